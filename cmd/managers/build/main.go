@@ -20,6 +20,7 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -30,13 +31,15 @@ import (
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
 	controllers "github.com/projectriff/system/pkg/controllers/build"
+	"github.com/projectriff/system/pkg/tracker"
 	// +kubebuilder:scaffold:imports
 )
 
 var (
-	scheme    = runtime.NewScheme()
-	setupLog  = ctrl.Log.WithName("setup")
-	namespace = os.Getenv("SYSTEM_NAMESPACE")
+	scheme     = runtime.NewScheme()
+	setupLog   = ctrl.Log.WithName("setup")
+	namespace  = os.Getenv("SYSTEM_NAMESPACE")
+	syncPeriod = 10 * time.Hour
 )
 
 func init() {
@@ -49,9 +52,13 @@ func init() {
 func main() {
 	var metricsAddr string
 	var probesAddr string
+	var gitWebhookAddr string
+	var containerPollInterval time.Duration
 	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probesAddr, "probes-addr", ":8081", "The address health probes bind to.")
+	flag.StringVar(&gitWebhookAddr, "git-webhook-addr", ":8082", "The address the git push webhook endpoint binds to. Set to empty to disable.")
+	flag.DurationVar(&containerPollInterval, "container-poll-interval", 5*time.Minute, "How often Container images are checked for a new digest.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.Parse()
@@ -64,16 +71,21 @@ func main() {
 		HealthProbeBindAddress: probesAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "controller-leader-election-helper-build",
+		SyncPeriod:             &syncPeriod,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	registryClient := &controllers.RegistryClient{HTTPClient: &http.Client{Timeout: 10 * time.Second}}
+
 	if err = (&controllers.ApplicationReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Application"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Application"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Application").WithName("tracker")),
+		Namespace: namespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Application")
 		os.Exit(1)
@@ -83,9 +95,11 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.ContainerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Container"),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("Container"),
+		Scheme:         mgr.GetScheme(),
+		RegistryClient: registryClient,
+		PollInterval:   containerPollInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Container")
 		os.Exit(1)
@@ -95,9 +109,11 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.FunctionReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Function"),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Function"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Function").WithName("tracker")),
+		Namespace: namespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Function")
 		os.Exit(1)
@@ -107,12 +123,27 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.CredentialReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Credentials"),
+		Client:         mgr.GetClient(),
+		Log:            ctrl.Log.WithName("controllers").WithName("Credential"),
+		Scheme:         mgr.GetScheme(),
+		Tracker:        tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Credential").WithName("tracker")),
+		RegistryClient: registryClient,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Credential")
 		os.Exit(1)
 	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&buildv1alpha1.Credential{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Credential")
+		os.Exit(1)
+	}
+	if err = (&controllers.ServiceAccountReconciler{
+		Client: mgr.GetClient(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ServiceAccounts"),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ServiceAccount")
+		os.Exit(1)
+	}
 	if err = (&controllers.ClusterBuilderReconciler{
 		Client:    mgr.GetClient(),
 		Log:       ctrl.Log.WithName("controllers").WithName("ClusterBuilders"),
@@ -121,6 +152,19 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterBuilder")
 		os.Exit(1)
 	}
+	if gitWebhookAddr != "" {
+		if err = mgr.Add(&controllers.GitWebhookServer{
+			Addr: gitWebhookAddr,
+			Handler: &controllers.GitWebhookHandler{
+				Client:    mgr.GetClient(),
+				Log:       ctrl.Log.WithName("webhooks").WithName("Git"),
+				Namespace: namespace,
+			},
+		}); err != nil {
+			setupLog.Error(err, "unable to create git webhook server")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("default", func(_ *http.Request) error { return nil }); err != nil {
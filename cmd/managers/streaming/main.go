@@ -27,11 +27,13 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers/discovery"
 	controllers "github.com/projectriff/system/pkg/controllers/streaming"
 	"github.com/projectriff/system/pkg/tracker"
 	// +kubebuilder:scaffold:imports
@@ -57,10 +59,14 @@ func main() {
 	var metricsAddr string
 	var probesAddr string
 	var enableLeaderElection bool
+	var brokerHealthInterval time.Duration
+	var deliveryCheckInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probesAddr, "probes-addr", ":8081", "The address health probes bind to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&brokerHealthInterval, "broker-health-interval", 30*time.Second, "The interval between broker connectivity checks.")
+	flag.DurationVar(&deliveryCheckInterval, "delivery-check-interval", 30*time.Second, "The interval between stream subscription delivery checks.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.Logger(true))
@@ -79,11 +85,13 @@ func main() {
 	}
 
 	if err = (&controllers.KafkaProviderReconciler{
-		Client:    mgr.GetClient(),
-		Log:       ctrl.Log.WithName("controllers").WithName("KafkaProvider"),
-		Scheme:    mgr.GetScheme(),
-		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("KafkaProvider").WithName("tracker")),
-		Namespace: namespace,
+		Client:              mgr.GetClient(),
+		Log:                 ctrl.Log.WithName("controllers").WithName("KafkaProvider"),
+		Scheme:              mgr.GetScheme(),
+		Tracker:             tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("KafkaProvider").WithName("tracker")),
+		Namespace:           namespace,
+		HealthClient:        controllers.NewProviderHealthClient(&http.Client{Timeout: 10 * time.Second}, ctrl.Log.WithName("controllers").WithName("KafkaProvider")),
+		HealthCheckInterval: brokerHealthInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KafkaProvider")
 		os.Exit(1)
@@ -111,7 +119,9 @@ func main() {
 		Client:                  mgr.GetClient(),
 		Log:                     streamControllerLogger,
 		Scheme:                  mgr.GetScheme(),
+		Tracker:                 tracker.New(syncPeriod, streamControllerLogger.WithName("tracker")),
 		StreamProvisionerClient: controllers.NewStreamProvisionerClient(http.DefaultClient, streamControllerLogger),
+		Namespace:               namespace,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Stream")
 		os.Exit(1)
@@ -120,12 +130,22 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Stream")
 		os.Exit(1)
 	}
+	// detect optional CRDs, processors degrade without KEDA
+	kedaEnabled, err := discovery.IsResourceAvailable(mgr.GetConfig(), kedav1alpha1.GroupVersion.WithKind("ScaledObject"))
+	if err != nil {
+		setupLog.Error(err, "unable to detect KEDA")
+		os.Exit(1)
+	}
+	if !kedaEnabled {
+		setupLog.Info("KEDA is not installed, processors will not scale with KEDA")
+	}
 	if err = (&controllers.ProcessorReconciler{
-		Client:    mgr.GetClient(),
-		Log:       ctrl.Log.WithName("controllers").WithName("Processor"),
-		Scheme:    mgr.GetScheme(),
-		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Processor").WithName("tracker")),
-		Namespace: namespace,
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("Processor"),
+		Scheme:      mgr.GetScheme(),
+		Tracker:     tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("Processor").WithName("tracker")),
+		Namespace:   namespace,
+		KEDAEnabled: kedaEnabled,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Processor")
 		os.Exit(1)
@@ -134,6 +154,56 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Processor")
 		os.Exit(1)
 	}
+	mgr.GetWebhookServer().Register(controllers.ProcessorStreamAccessWebhookPath, &webhook.Admission{
+		Handler: &controllers.ProcessorStreamAccessValidator{Client: mgr.GetClient()},
+	})
+	mgr.GetWebhookServer().Register(controllers.ProcessorInvocationWebhookPath, &webhook.Admission{
+		Handler: &controllers.ProcessorInvocationValidator{Client: mgr.GetClient()},
+	})
+	if err = (&controllers.StreamIngressReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("StreamIngress"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("StreamIngress").WithName("tracker")),
+		Namespace: namespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StreamIngress")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.StreamIngress{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "StreamIngress")
+		os.Exit(1)
+	}
+	if err = (&controllers.StreamSubscriptionReconciler{
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("StreamSubscription"),
+		Scheme:                mgr.GetScheme(),
+		Tracker:               tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("StreamSubscription").WithName("tracker")),
+		Namespace:             namespace,
+		DeliveryStatsClient:   controllers.NewDeliveryStatsClient(&http.Client{Timeout: 5 * time.Second}, ctrl.Log.WithName("controllers").WithName("StreamSubscription")),
+		DeliveryCheckInterval: deliveryCheckInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StreamSubscription")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.StreamSubscription{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "StreamSubscription")
+		os.Exit(1)
+	}
+	if err = (&controllers.StreamTapReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("StreamTap"),
+		Scheme:    mgr.GetScheme(),
+		Tracker:   tracker.New(syncPeriod, ctrl.Log.WithName("controllers").WithName("StreamTap").WithName("tracker")),
+		Namespace: namespace,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "StreamTap")
+		os.Exit(1)
+	}
+	if err = ctrl.NewWebhookManagedBy(mgr).For(&streamingv1alpha1.StreamTap{}).Complete(); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "StreamTap")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("default", func(_ *http.Request) error { return nil }); err != nil {
@@ -31,4 +31,7 @@ func (s *ApplicationSpec) Default() {
 	if s.Image == "" {
 		s.Image = "_"
 	}
+	if s.Builder != nil {
+		s.Builder.Default()
+	}
 }
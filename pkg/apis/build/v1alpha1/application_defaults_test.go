@@ -35,6 +35,25 @@ func TestApplicationDefault(t *testing.T) {
 				Image: "_",
 			},
 		},
+	}, {
+		name: "builder kind",
+		in: &Application{
+			Spec: ApplicationSpec{
+				Image: "_",
+				Builder: &BuilderReference{
+					Name: "my-builder",
+				},
+			},
+		},
+		want: &Application{
+			Spec: ApplicationSpec{
+				Image: "_",
+				Builder: &BuilderReference{
+					Kind: ClusterBuilderKind,
+					Name: "my-builder",
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -27,11 +27,16 @@ const (
 	ApplicationConditionReady                              = apis.ConditionReady
 	ApplicationConditionKpackImageReady apis.ConditionType = "KpackImageReady"
 	ApplicationConditionImageResolved   apis.ConditionType = "ImageResolved"
+	ApplicationConditionBuilderResolved apis.ConditionType = "BuilderResolved"
+	// ApplicationConditionBuilderUpToDate is informational, an outdated builder
+	// does not make the application unready
+	ApplicationConditionBuilderUpToDate apis.ConditionType = "BuilderUpToDate"
 )
 
 var applicationCondSet = apis.NewLivingConditionSet(
 	ApplicationConditionKpackImageReady,
 	ApplicationConditionImageResolved,
+	ApplicationConditionBuilderResolved,
 )
 
 func (as *ApplicationStatus) GetObservedGeneration() int64 {
@@ -57,6 +62,9 @@ func (as *ApplicationStatus) InitializeConditions() {
 func (as *ApplicationStatus) MarkBuildNotUsed() {
 	as.KpackImageRef = nil
 	as.BuildCacheRef = nil
+	as.Builds = nil
+	as.LatestBuilderImage = ""
+	applicationCondSet.Manage(as).ClearCondition(ApplicationConditionBuilderUpToDate)
 	applicationCondSet.Manage(as).MarkTrue(ApplicationConditionKpackImageReady)
 }
 
@@ -68,10 +76,35 @@ func (as *ApplicationStatus) MarkImageInvalid(message string) {
 	applicationCondSet.Manage(as).MarkFalse(ApplicationConditionImageResolved, "ImageInvalid", message)
 }
 
+func (as *ApplicationStatus) MarkBuilderNotFound(message string) {
+	applicationCondSet.Manage(as).MarkFalse(ApplicationConditionBuilderResolved, "BuilderNotFound", message)
+}
+
+func (as *ApplicationStatus) MarkBuilderResolved() {
+	applicationCondSet.Manage(as).MarkTrue(ApplicationConditionBuilderResolved)
+}
+
+// PropagateBuilderImage compares the builder image used by the most recent
+// build with the image the builder currently provides.
+func (as *ApplicationStatus) PropagateBuilderImage(builderImage string) {
+	if builderImage == "" || as.LatestBuilderImage == "" {
+		// nothing built yet, or the builder is not ready
+		applicationCondSet.Manage(as).ClearCondition(ApplicationConditionBuilderUpToDate)
+		return
+	}
+	if builderImage != as.LatestBuilderImage {
+		applicationCondSet.Manage(as).MarkFalse(ApplicationConditionBuilderUpToDate, "BuilderOutdated", "built with builder image %s, the builder now provides %s", as.LatestBuilderImage, builderImage)
+		return
+	}
+	applicationCondSet.Manage(as).MarkTrue(ApplicationConditionBuilderUpToDate)
+}
+
 func (as *ApplicationStatus) MarkImageResolved() {
 	applicationCondSet.Manage(as).MarkTrue(ApplicationConditionImageResolved)
 }
 
+// PropagateKpackImageStatus must be called after PropagateKpackBuilds so a
+// failed build is reported with its details.
 func (as *ApplicationStatus) PropagateKpackImageStatus(is *kpackbuildv1alpha1.ImageStatus) {
 	sc := is.GetCondition(apis.ConditionReady)
 	if sc == nil {
@@ -83,6 +116,10 @@ func (as *ApplicationStatus) PropagateKpackImageStatus(is *kpackbuildv1alpha1.Im
 	case sc.Status == corev1.ConditionTrue:
 		applicationCondSet.Manage(as).MarkTrue(ApplicationConditionKpackImageReady)
 	case sc.Status == corev1.ConditionFalse:
+		if build := as.latestFailedBuild(is); build != nil {
+			applicationCondSet.Manage(as).MarkFalse(ApplicationConditionKpackImageReady, "BuildFailed", "%s", build.failureSummary())
+			return
+		}
 		applicationCondSet.Manage(as).MarkFalse(ApplicationConditionKpackImageReady, sc.Reason, sc.Message)
 	}
 }
@@ -52,6 +52,22 @@ type ApplicationSpec struct {
 	// Source location. Required for on cluster builds.
 	Source *Source `json:"source,omitempty"`
 
+	// Builder used for on cluster builds. Defaults to the riff-application
+	// ClusterBuilder.
+	// +optional
+	Builder *BuilderReference `json:"builder,omitempty"`
+
+	// ServiceAccountName the build runs as. Credentials bound to the service
+	// account are used to fetch source and push images. Defaults to riff-build.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// RebuildPolicy controls whether a new build is triggered when the
+	// builder provides a newer image, rolling out base image and buildpack
+	// updates. Builder updates do not trigger builds unless set.
+	// +optional
+	RebuildPolicy RebuildPolicy `json:"rebuildPolicy,omitempty"`
+
 	// +optional
 	// +nullable
 	FailedBuildHistoryLimit *int64 `json:"failedBuildHistoryLimit,omitempty"`
@@ -102,6 +102,56 @@ func TestValidateApplicationSpec(t *testing.T) {
 			Source: &Source{},
 		},
 		expected: validation.ErrMissingField("source"),
+	}, {
+		name: "with builder",
+		target: &ApplicationSpec{
+			Image: "test-image",
+			Builder: &BuilderReference{
+				Kind: NamespacedBuilderKind,
+				Name: "my-builder",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "validates builder",
+		target: &ApplicationSpec{
+			Image: "test-image",
+			Builder: &BuilderReference{
+				Kind: "Bogus",
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(BuilderKind("Bogus"), "builder.kind"),
+			validation.ErrMissingField("builder.name"),
+		),
+	}, {
+		name: "with service account",
+		target: &ApplicationSpec{
+			Image:              "test-image",
+			ServiceAccountName: "team-a",
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid service account",
+		target: &ApplicationSpec{
+			Image:              "test-image",
+			ServiceAccountName: "Team A",
+		},
+		expected: validation.ErrInvalidValue("Team A", "serviceAccountName"),
+	}, {
+		name: "rebuild on builder update",
+		target: &ApplicationSpec{
+			Image:         "test-image",
+			RebuildPolicy: BuilderUpdateRebuildPolicy,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid rebuild policy",
+		target: &ApplicationSpec{
+			Image:         "test-image",
+			RebuildPolicy: "Always",
+		},
+		expected: validation.ErrInvalidValue(RebuildPolicy("Always"), "rebuildPolicy"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -31,4 +31,16 @@ func (s *ContainerSpec) Default() {
 	if s.Image == "" {
 		s.Image = "_"
 	}
+	if s.TagPolicy != nil {
+		s.TagPolicy.Default()
+	}
+}
+
+func (p *TagPolicy) Default() {
+	if p.Semver != nil && p.Semver.Range == "" {
+		p.Semver.Range = "*"
+	}
+	if p.Regex != nil && p.Regex.Order == "" {
+		p.Regex.Order = AlphabeticalTagOrder
+	}
 }
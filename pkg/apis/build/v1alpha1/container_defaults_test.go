@@ -35,6 +35,47 @@ func TestContainerDefault(t *testing.T) {
 				Image: "_",
 			},
 		},
+	}, {
+		name: "semver tag policy",
+		in: &Container{
+			Spec: ContainerSpec{
+				Image:     "test-image",
+				TagPolicy: &TagPolicy{Semver: &SemverTagPolicy{}},
+			},
+		},
+		want: &Container{
+			Spec: ContainerSpec{
+				Image: "test-image",
+				TagPolicy: &TagPolicy{
+					Semver: &SemverTagPolicy{
+						Range: "*",
+					},
+				},
+			},
+		},
+	}, {
+		name: "regex tag policy",
+		in: &Container{
+			Spec: ContainerSpec{
+				Image: "test-image",
+				TagPolicy: &TagPolicy{
+					Regex: &RegexTagPolicy{
+						Pattern: "^main-[0-9]+$",
+					},
+				},
+			},
+		},
+		want: &Container{
+			Spec: ContainerSpec{
+				Image: "test-image",
+				TagPolicy: &TagPolicy{
+					Regex: &RegexTagPolicy{
+						Pattern: "^main-[0-9]+$",
+						Order:   AlphabeticalTagOrder,
+					},
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -23,6 +23,9 @@ import (
 const (
 	ContainerConditionReady                            = apis.ConditionReady
 	ContainerConditionImageResolved apis.ConditionType = "ImageResolved"
+	// ContainerConditionDigestResolved is informational, a container whose
+	// digest can not be resolved is ready with the target image
+	ContainerConditionDigestResolved apis.ConditionType = "DigestResolved"
 )
 
 var containerCondSet = apis.NewLivingConditionSet(
@@ -57,6 +60,22 @@ func (cs *ContainerStatus) MarkImageInvalid(message string) {
 	containerCondSet.Manage(cs).MarkFalse(ContainerConditionImageResolved, "ImageInvalid", message)
 }
 
+func (cs *ContainerStatus) MarkTagNotFound(messageFormat string, messageA ...interface{}) {
+	containerCondSet.Manage(cs).MarkFalse(ContainerConditionImageResolved, "TagNotFound", messageFormat, messageA...)
+}
+
+func (cs *ContainerStatus) MarkTagSelectionFailed(messageFormat string, messageA ...interface{}) {
+	containerCondSet.Manage(cs).MarkFalse(ContainerConditionImageResolved, "TagSelectionFailed", messageFormat, messageA...)
+}
+
 func (cs *ContainerStatus) MarkImageResolved() {
 	containerCondSet.Manage(cs).MarkTrue(ContainerConditionImageResolved)
 }
+
+func (cs *ContainerStatus) MarkDigestResolutionFailed(messageFormat string, messageA ...interface{}) {
+	containerCondSet.Manage(cs).MarkFalse(ContainerConditionDigestResolved, "DigestResolutionFailed", messageFormat, messageA...)
+}
+
+func (cs *ContainerStatus) MarkDigestResolved() {
+	containerCondSet.Manage(cs).MarkTrue(ContainerConditionDigestResolved)
+}
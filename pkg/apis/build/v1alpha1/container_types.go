@@ -43,6 +43,64 @@ type ContainerSpec struct {
 	// to have the default image prefix applied, or be `_` to combine the default
 	// image prefix with the resource's name as a default value.
 	Image string `json:"image"`
+
+	// TagPolicy selects which of the repository's tags is used. The image must
+	// not include a tag when set. Defaults to the image's tag.
+	// +optional
+	TagPolicy *TagPolicy `json:"tagPolicy,omitempty"`
+}
+
+// TagPolicy selects a tag from an image repository. Exactly one policy must
+// be set.
+type TagPolicy struct {
+	// Semver selects the highest tag that is a semantic version in a range.
+	// +optional
+	Semver *SemverTagPolicy `json:"semver,omitempty"`
+
+	// Regex selects the highest tag matching a pattern.
+	// +optional
+	Regex *RegexTagPolicy `json:"regex,omitempty"`
+
+	// Latest selects the most recently pushed tag.
+	// +optional
+	Latest *LatestTagPolicy `json:"latest,omitempty"`
+}
+
+type SemverTagPolicy struct {
+	// Range of versions to select from, for example `>=1.0.0 <2.0.0`.
+	// Defaults to `*`, any release version.
+	// +optional
+	Range string `json:"range,omitempty"`
+}
+
+type RegexTagPolicy struct {
+	// Pattern tags must match.
+	Pattern string `json:"pattern"`
+
+	// Extract is the value tags are ordered by, expanded from the pattern's
+	// submatches like `$1` or `${name}`. Defaults to the whole tag.
+	// +optional
+	Extract string `json:"extract,omitempty"`
+
+	// Order of the extracted values, the highest value is selected. Defaults
+	// to Alphabetical.
+	// +optional
+	Order TagOrder `json:"order,omitempty"`
+}
+
+type TagOrder string
+
+const (
+	AlphabeticalTagOrder TagOrder = "Alphabetical"
+	NumericalTagOrder    TagOrder = "Numerical"
+)
+
+type LatestTagPolicy struct {
+	// Pattern tags must match. Defaults to any tag. Each matching tag is
+	// inspected on every poll, at most LatestTagCandidatesLimit tags may
+	// match.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
 }
 
 // ContainerStatus defines the observed state of Container
@@ -52,8 +110,22 @@ type ContainerStatus struct {
 
 	apis.Status `json:",inline"`
 	BuildStatus `json:",inline"`
+
+	// TagCandidates are the repository's tags selected by the tag policy, best
+	// first. Limited to the top TagCandidatesLimit tags.
+	TagCandidates []string `json:"tagCandidates,omitempty"`
+
+	// LatestTag is the tag chosen by the tag policy.
+	LatestTag string `json:"latestTag,omitempty"`
 }
 
+// TagCandidatesLimit is the number of tag candidates recorded in status.
+const TagCandidatesLimit = 10
+
+// LatestTagCandidatesLimit is the number of tags a latest tag policy may
+// match.
+const LatestTagCandidatesLimit = 100
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:categories="riff"
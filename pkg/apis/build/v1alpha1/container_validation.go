@@ -17,10 +17,14 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"regexp"
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/equality"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/projectriff/system/pkg/semver"
 	"github.com/projectriff/system/pkg/validation"
 )
 
@@ -66,5 +70,49 @@ func (s *ContainerSpec) Validate() validation.FieldErrors {
 		errs = errs.Also(validation.ErrMissingField("image"))
 	}
 
+	if s.TagPolicy != nil {
+		if name := s.Image[strings.LastIndex(s.Image, "/")+1:]; strings.ContainsAny(name, ":@") {
+			// the tag policy chooses the tag
+			errs = errs.Also(validation.ErrInvalidValue(s.Image, "image"))
+		}
+		errs = errs.Also(s.TagPolicy.Validate().ViaField("tagPolicy"))
+	}
+
+	return errs
+}
+
+func (p *TagPolicy) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	set := []string{}
+	if p.Semver != nil {
+		set = append(set, "semver")
+		if _, err := semver.ParseRange(p.Semver.Range); err != nil {
+			errs = errs.Also(validation.ErrInvalidValue(p.Semver.Range, "semver.range"))
+		}
+	}
+	if p.Regex != nil {
+		set = append(set, "regex")
+		if p.Regex.Pattern == "" {
+			errs = errs.Also(validation.ErrMissingField("regex.pattern"))
+		} else if _, err := regexp.Compile(p.Regex.Pattern); err != nil {
+			errs = errs.Also(validation.ErrInvalidValue(p.Regex.Pattern, "regex.pattern"))
+		}
+		if p.Regex.Order != AlphabeticalTagOrder && p.Regex.Order != NumericalTagOrder {
+			errs = errs.Also(validation.ErrInvalidValue(p.Regex.Order, "regex.order"))
+		}
+	}
+	if p.Latest != nil {
+		set = append(set, "latest")
+		if _, err := regexp.Compile(p.Latest.Pattern); err != nil {
+			errs = errs.Also(validation.ErrInvalidValue(p.Latest.Pattern, "latest.pattern"))
+		}
+	}
+	if len(set) == 0 {
+		errs = errs.Also(validation.ErrMissingOneOf("semver", "regex", "latest"))
+	} else if len(set) > 1 {
+		errs = errs.Also(validation.ErrMultipleOneOf(set...))
+	}
+
 	return errs
 }
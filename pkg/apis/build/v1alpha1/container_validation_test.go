@@ -66,6 +66,98 @@ func TestValidateContainerSpec(t *testing.T) {
 			Image: "test-image",
 		},
 		expected: validation.FieldErrors{},
+	}, {
+		name: "semver tag policy",
+		target: &ContainerSpec{
+			Image: "registry.example.com:5000/test-image",
+			TagPolicy: &TagPolicy{
+				Semver: &SemverTagPolicy{Range: ">=1.0.0 <2.0.0"},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "regex tag policy",
+		target: &ContainerSpec{
+			Image: "test-image",
+			TagPolicy: &TagPolicy{
+				Regex: &RegexTagPolicy{
+					Pattern: "^main-(?P<build>[0-9]+)$",
+					Extract: "$build",
+					Order:   NumericalTagOrder,
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "latest tag policy",
+		target: &ContainerSpec{
+			Image: "test-image",
+			TagPolicy: &TagPolicy{
+				Latest: &LatestTagPolicy{},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "tag policy with tagged image",
+		target: &ContainerSpec{
+			Image: "test-image:v1",
+			TagPolicy: &TagPolicy{
+				Latest: &LatestTagPolicy{},
+			},
+		},
+		expected: validation.ErrInvalidValue("test-image:v1", "image"),
+	}, {
+		name: "empty tag policy",
+		target: &ContainerSpec{
+			Image:     "test-image",
+			TagPolicy: &TagPolicy{},
+		},
+		expected: validation.ErrMissingOneOf("semver", "regex", "latest").ViaField("tagPolicy"),
+	}, {
+		name: "multiple tag policies",
+		target: &ContainerSpec{
+			Image: "test-image",
+			TagPolicy: &TagPolicy{
+				Semver: &SemverTagPolicy{Range: "*"},
+				Latest: &LatestTagPolicy{},
+			},
+		},
+		expected: validation.ErrMultipleOneOf("semver", "latest").ViaField("tagPolicy"),
+	}, {
+		name: "invalid semver range",
+		target: &ContainerSpec{
+			Image: "test-image",
+			TagPolicy: &TagPolicy{
+				Semver: &SemverTagPolicy{Range: "=>1.0"},
+			},
+		},
+		expected: validation.ErrInvalidValue("=>1.0", "tagPolicy.semver.range"),
+	}, {
+		name: "invalid regex",
+		target: &ContainerSpec{
+			Image: "test-image",
+			TagPolicy: &TagPolicy{
+				Regex: &RegexTagPolicy{
+					Pattern: "main-(",
+					Order:   "Random",
+				},
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue("main-(", "tagPolicy.regex.pattern"),
+			validation.ErrInvalidValue(TagOrder("Random"), "tagPolicy.regex.order"),
+		),
+	}, {
+		name: "missing regex pattern",
+		target: &ContainerSpec{
+			Image: "test-image",
+			TagPolicy: &TagPolicy{
+				Regex: &RegexTagPolicy{
+					Order: AlphabeticalTagOrder,
+				},
+			},
+		},
+		expected: validation.ErrMissingField("tagPolicy.regex.pattern"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
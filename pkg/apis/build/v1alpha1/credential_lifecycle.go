@@ -0,0 +1,79 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+const (
+	CredentialConditionReady                            = apis.ConditionReady
+	CredentialConditionSecretReady   apis.ConditionType = "SecretReady"
+	CredentialConditionLoginVerified apis.ConditionType = "LoginVerified"
+)
+
+var credentialCondSet = apis.NewLivingConditionSet(
+	CredentialConditionSecretReady,
+	CredentialConditionLoginVerified,
+)
+
+func (cs *CredentialStatus) GetObservedGeneration() int64 {
+	return cs.ObservedGeneration
+}
+
+func (cs *CredentialStatus) IsReady() bool {
+	return credentialCondSet.Manage(cs).IsHappy()
+}
+
+func (*CredentialStatus) GetReadyConditionType() apis.ConditionType {
+	return CredentialConditionReady
+}
+
+func (cs *CredentialStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return credentialCondSet.Manage(cs).GetCondition(t)
+}
+
+func (cs *CredentialStatus) InitializeConditions() {
+	credentialCondSet.Manage(cs).InitializeConditions()
+}
+
+func (cs *CredentialStatus) MarkSecretReady() {
+	credentialCondSet.Manage(cs).MarkTrue(CredentialConditionSecretReady)
+}
+
+func (cs *CredentialStatus) MarkSecretNotFound(message string) {
+	credentialCondSet.Manage(cs).MarkFalse(CredentialConditionSecretReady, "SecretNotFound", message)
+}
+
+func (cs *CredentialStatus) MarkSecretKeyMissing(secret, key string) {
+	credentialCondSet.Manage(cs).MarkFalse(CredentialConditionSecretReady, "SecretKeyMissing", fmt.Sprintf("secret %q is missing key %q", secret, key))
+}
+
+func (cs *CredentialStatus) MarkLoginVerified() {
+	credentialCondSet.Manage(cs).MarkTrue(CredentialConditionLoginVerified)
+}
+
+func (cs *CredentialStatus) MarkLoginFailed(messageFormat string, messageA ...interface{}) {
+	credentialCondSet.Manage(cs).MarkFalse(CredentialConditionLoginVerified, "LoginFailed", messageFormat, messageA...)
+}
+
+func (cs *CredentialStatus) MarkLoginNotRequested() {
+	// verification is opt-in, the credential is usable without it
+	credentialCondSet.Manage(cs).MarkTrue(CredentialConditionLoginVerified)
+}
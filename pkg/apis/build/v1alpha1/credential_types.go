@@ -0,0 +1,152 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apis "github.com/projectriff/system/pkg/apis"
+	"github.com/projectriff/system/pkg/refs"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	_ apis.Resource = (*Credential)(nil)
+)
+
+// DockerHubServer is the registry server for Docker Hub credentials
+const DockerHubServer = "https://index.docker.io/v1/"
+
+// CredentialSpec defines the desired state of Credential
+type CredentialSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// DockerRegistry credential to push and pull images. Exactly one of
+	// dockerRegistry, gitBasicAuth or gitSSH is required.
+	// +optional
+	DockerRegistry *DockerRegistryCredential `json:"dockerRegistry,omitempty"`
+
+	// GitBasicAuth credential to fetch git sources over http.
+	// +optional
+	GitBasicAuth *GitBasicAuthCredential `json:"gitBasicAuth,omitempty"`
+
+	// GitSSH credential to fetch git sources over ssh.
+	// +optional
+	GitSSH *GitSSHCredential `json:"gitSSH,omitempty"`
+
+	// ServiceAccounts in this namespace the credential is bound to. Defaults
+	// to the riff-build service account.
+	// +optional
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+type DockerRegistryCredential struct {
+	// Server is the url of the registry. Defaults to Docker Hub.
+	// +optional
+	Server string `json:"server,omitempty"`
+
+	// Username to log into the registry as.
+	Username string `json:"username"`
+
+	// PasswordRef selects the password, or access token, from a Secret in
+	// this namespace.
+	PasswordRef corev1.SecretKeySelector `json:"passwordRef"`
+
+	// VerifyLogin checks the credential by logging into the registry.
+	// +optional
+	VerifyLogin bool `json:"verifyLogin,omitempty"`
+}
+
+type GitBasicAuthCredential struct {
+	// URL prefix of the repositories the credential applies to, e.g.
+	// https://github.com
+	URL string `json:"url"`
+
+	// Username to authenticate as.
+	Username string `json:"username"`
+
+	// PasswordRef selects the password, or access token, from a Secret in
+	// this namespace.
+	PasswordRef corev1.SecretKeySelector `json:"passwordRef"`
+}
+
+type GitSSHCredential struct {
+	// URL prefix of the repositories the credential applies to, e.g.
+	// git@github.com
+	URL string `json:"url"`
+
+	// PrivateKeyRef selects the ssh private key from a Secret in this
+	// namespace.
+	PrivateKeyRef corev1.SecretKeySelector `json:"privateKeyRef"`
+
+	// KnownHosts entries for the git server.
+	// +optional
+	KnownHosts string `json:"knownHosts,omitempty"`
+}
+
+// CredentialStatus defines the observed state of Credential
+type CredentialStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status `json:",inline"`
+
+	// SecretRef is the generated Secret kpack reads the credential from.
+	SecretRef *refs.TypedLocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// Credential is the Schema for the credentials API
+type Credential struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CredentialSpec   `json:"spec,omitempty"`
+	Status CredentialStatus `json:"status,omitempty"`
+}
+
+func (*Credential) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("Credential")
+}
+
+func (c *Credential) GetStatus() apis.ResourceStatus {
+	return &c.Status
+}
+
+// +kubebuilder:object:root=true
+
+// CredentialList contains a list of Credential
+type CredentialList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Credential `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Credential{}, &CredentialList{})
+}
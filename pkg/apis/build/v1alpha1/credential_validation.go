@@ -0,0 +1,154 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-build-projectriff-io-v1alpha1-credential,mutating=false,failurePolicy=fail,groups=build.projectriff.io,resources=credentials,verbs=create;update,versions=v1alpha1,name=credentials.build.projectriff.io
+
+var (
+	_ webhook.Validator         = &Credential{}
+	_ validation.FieldValidator = &Credential{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Credential) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Credential) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Credential) ValidateDelete() error {
+	return nil
+}
+
+func (r *Credential) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *CredentialSpec) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(s, &CredentialSpec{}) {
+		return validation.ErrMissingField(validation.CurrentField)
+	}
+
+	errs := validation.FieldErrors{}
+
+	set := []string{}
+	if s.DockerRegistry != nil {
+		set = append(set, "dockerRegistry")
+		errs = errs.Also(s.DockerRegistry.Validate().ViaField("dockerRegistry"))
+	}
+	if s.GitBasicAuth != nil {
+		set = append(set, "gitBasicAuth")
+		errs = errs.Also(s.GitBasicAuth.Validate().ViaField("gitBasicAuth"))
+	}
+	if s.GitSSH != nil {
+		set = append(set, "gitSSH")
+		errs = errs.Also(s.GitSSH.Validate().ViaField("gitSSH"))
+	}
+	if len(set) == 0 {
+		errs = errs.Also(validation.ErrMissingOneOf("dockerRegistry", "gitBasicAuth", "gitSSH"))
+	} else if len(set) > 1 {
+		errs = errs.Also(validation.ErrMultipleOneOf(set...))
+	}
+
+	for i, serviceAccount := range s.ServiceAccounts {
+		if out := validationutil.IsDNS1123Subdomain(serviceAccount); len(out) != 0 {
+			errs = errs.Also(validation.ErrInvalidArrayValue(serviceAccount, "serviceAccounts", i))
+		}
+	}
+
+	return errs
+}
+
+func (c *DockerRegistryCredential) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if c.Server == "" {
+		errs = errs.Also(validation.ErrMissingField("server"))
+	} else if u, err := url.Parse(c.Server); err != nil || u.Host == "" {
+		errs = errs.Also(validation.ErrInvalidValue(c.Server, "server"))
+	}
+	if c.Username == "" {
+		errs = errs.Also(validation.ErrMissingField("username"))
+	}
+	errs = errs.Also(validateSecretKeySelector(c.PasswordRef).ViaField("passwordRef"))
+
+	return errs
+}
+
+func (c *GitBasicAuthCredential) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if c.URL == "" {
+		errs = errs.Also(validation.ErrMissingField("url"))
+	} else if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = errs.Also(validation.ErrInvalidValue(c.URL, "url"))
+	}
+	if c.Username == "" {
+		errs = errs.Also(validation.ErrMissingField("username"))
+	}
+	errs = errs.Also(validateSecretKeySelector(c.PasswordRef).ViaField("passwordRef"))
+
+	return errs
+}
+
+func (c *GitSSHCredential) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if c.URL == "" {
+		errs = errs.Also(validation.ErrMissingField("url"))
+	} else if u, err := url.Parse(c.URL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		// http urls are authenticated with gitBasicAuth
+		errs = errs.Also(validation.ErrInvalidValue(c.URL, "url"))
+	}
+	errs = errs.Also(validateSecretKeySelector(c.PrivateKeyRef).ViaField("privateKeyRef"))
+
+	return errs
+}
+
+func validateSecretKeySelector(selector corev1.SecretKeySelector) validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if selector.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	}
+	if selector.Key == "" {
+		errs = errs.Also(validation.ErrMissingField("key"))
+	}
+
+	return errs
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateCredential(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *Credential
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &Credential{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &Credential{
+			Spec: CredentialSpec{
+				DockerRegistry: &DockerRegistryCredential{
+					Server:   DockerHubServer,
+					Username: "projectriff",
+					PasswordRef: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "docker-hub"},
+						Key:                  "password",
+					},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateCredential(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateCredentialSpec(t *testing.T) {
+	passwordRef := corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+		Key:                  "password",
+	}
+	privateKeyRef := corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+		Key:                  "ssh-privatekey",
+	}
+
+	for _, c := range []struct {
+		name     string
+		target   *CredentialSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &CredentialSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "docker registry",
+		target: &CredentialSpec{
+			DockerRegistry: &DockerRegistryCredential{
+				Server:      "https://registry.example.com",
+				Username:    "projectriff",
+				PasswordRef: passwordRef,
+				VerifyLogin: true,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "docker registry, invalid",
+		target: &CredentialSpec{
+			DockerRegistry: &DockerRegistryCredential{
+				Server: "registry",
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue("registry", "dockerRegistry.server"),
+			validation.ErrMissingField("dockerRegistry.username"),
+			validation.ErrMissingField("dockerRegistry.passwordRef.name"),
+			validation.ErrMissingField("dockerRegistry.passwordRef.key"),
+		),
+	}, {
+		name: "git basic auth",
+		target: &CredentialSpec{
+			GitBasicAuth: &GitBasicAuthCredential{
+				URL:         "https://github.com",
+				Username:    "projectriff",
+				PasswordRef: passwordRef,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "git basic auth, ssh url",
+		target: &CredentialSpec{
+			GitBasicAuth: &GitBasicAuthCredential{
+				URL:         "git@github.com",
+				Username:    "projectriff",
+				PasswordRef: passwordRef,
+			},
+		},
+		expected: validation.ErrInvalidValue("git@github.com", "gitBasicAuth.url"),
+	}, {
+		name: "git ssh",
+		target: &CredentialSpec{
+			GitSSH: &GitSSHCredential{
+				URL:           "git@github.com",
+				PrivateKeyRef: privateKeyRef,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "git ssh, http url",
+		target: &CredentialSpec{
+			GitSSH: &GitSSHCredential{
+				URL:           "https://github.com",
+				PrivateKeyRef: privateKeyRef,
+			},
+		},
+		expected: validation.ErrInvalidValue("https://github.com", "gitSSH.url"),
+	}, {
+		name: "multiple credentials",
+		target: &CredentialSpec{
+			GitBasicAuth: &GitBasicAuthCredential{
+				URL:         "https://github.com",
+				Username:    "projectriff",
+				PasswordRef: passwordRef,
+			},
+			GitSSH: &GitSSHCredential{
+				URL:           "git@github.com",
+				PrivateKeyRef: privateKeyRef,
+			},
+		},
+		expected: validation.ErrMultipleOneOf("gitBasicAuth", "gitSSH"),
+	}, {
+		name: "missing credential",
+		target: &CredentialSpec{
+			ServiceAccounts: []string{"team-a"},
+		},
+		expected: validation.ErrMissingOneOf("dockerRegistry", "gitBasicAuth", "gitSSH"),
+	}, {
+		name: "invalid service account",
+		target: &CredentialSpec{
+			GitSSH: &GitSSHCredential{
+				URL:           "git@github.com",
+				PrivateKeyRef: privateKeyRef,
+			},
+			ServiceAccounts: []string{"team-a", "Team B"},
+		},
+		expected: validation.ErrInvalidArrayValue("Team B", "serviceAccounts", 1),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateCredentialSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -35,6 +35,25 @@ func TestFunctionDefault(t *testing.T) {
 				Image: "_",
 			},
 		},
+	}, {
+		name: "builder kind",
+		in: &Function{
+			Spec: FunctionSpec{
+				Image: "_",
+				Builder: &BuilderReference{
+					Name: "my-builder",
+				},
+			},
+		},
+		want: &Function{
+			Spec: FunctionSpec{
+				Image: "_",
+				Builder: &BuilderReference{
+					Kind: ClusterBuilderKind,
+					Name: "my-builder",
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
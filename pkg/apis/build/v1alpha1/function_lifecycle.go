@@ -27,11 +27,16 @@ const (
 	FunctionConditionReady                              = apis.ConditionReady
 	FunctionConditionKpackImageReady apis.ConditionType = "KpackImageReady"
 	FunctionConditionImageResolved   apis.ConditionType = "ImageResolved"
+	FunctionConditionBuilderResolved apis.ConditionType = "BuilderResolved"
+	// FunctionConditionBuilderUpToDate is informational, an outdated builder
+	// does not make the function unready
+	FunctionConditionBuilderUpToDate apis.ConditionType = "BuilderUpToDate"
 )
 
 var functionCondSet = apis.NewLivingConditionSet(
 	FunctionConditionKpackImageReady,
 	FunctionConditionImageResolved,
+	FunctionConditionBuilderResolved,
 )
 
 func (fs *FunctionStatus) GetObservedGeneration() int64 {
@@ -57,6 +62,9 @@ func (fs *FunctionStatus) InitializeConditions() {
 func (fs *FunctionStatus) MarkBuildNotUsed() {
 	fs.KpackImageRef = nil
 	fs.BuildCacheRef = nil
+	fs.Builds = nil
+	fs.LatestBuilderImage = ""
+	functionCondSet.Manage(fs).ClearCondition(FunctionConditionBuilderUpToDate)
 	functionCondSet.Manage(fs).MarkTrue(FunctionConditionKpackImageReady)
 }
 
@@ -68,10 +76,35 @@ func (fs *FunctionStatus) MarkImageInvalid(message string) {
 	functionCondSet.Manage(fs).MarkFalse(FunctionConditionImageResolved, "ImageInvalid", message)
 }
 
+func (fs *FunctionStatus) MarkBuilderNotFound(message string) {
+	functionCondSet.Manage(fs).MarkFalse(FunctionConditionBuilderResolved, "BuilderNotFound", message)
+}
+
+func (fs *FunctionStatus) MarkBuilderResolved() {
+	functionCondSet.Manage(fs).MarkTrue(FunctionConditionBuilderResolved)
+}
+
+// PropagateBuilderImage compares the builder image used by the most recent
+// build with the image the builder currently provides.
+func (fs *FunctionStatus) PropagateBuilderImage(builderImage string) {
+	if builderImage == "" || fs.LatestBuilderImage == "" {
+		// nothing built yet, or the builder is not ready
+		functionCondSet.Manage(fs).ClearCondition(FunctionConditionBuilderUpToDate)
+		return
+	}
+	if builderImage != fs.LatestBuilderImage {
+		functionCondSet.Manage(fs).MarkFalse(FunctionConditionBuilderUpToDate, "BuilderOutdated", "built with builder image %s, the builder now provides %s", fs.LatestBuilderImage, builderImage)
+		return
+	}
+	functionCondSet.Manage(fs).MarkTrue(FunctionConditionBuilderUpToDate)
+}
+
 func (fs *FunctionStatus) MarkImageResolved() {
 	functionCondSet.Manage(fs).MarkTrue(FunctionConditionImageResolved)
 }
 
+// PropagateKpackImageStatus must be called after PropagateKpackBuilds so a
+// failed build is reported with its details.
 func (fs *FunctionStatus) PropagateKpackImageStatus(is *kpackbuildv1alpha1.ImageStatus) {
 	sc := is.GetCondition(apis.ConditionReady)
 	if sc == nil {
@@ -83,6 +116,10 @@ func (fs *FunctionStatus) PropagateKpackImageStatus(is *kpackbuildv1alpha1.Image
 	case sc.Status == corev1.ConditionTrue:
 		functionCondSet.Manage(fs).MarkTrue(FunctionConditionKpackImageReady)
 	case sc.Status == corev1.ConditionFalse:
+		if build := fs.latestFailedBuild(is); build != nil {
+			functionCondSet.Manage(fs).MarkFalse(FunctionConditionKpackImageReady, "BuildFailed", "%s", build.failureSummary())
+			return
+		}
 		functionCondSet.Manage(fs).MarkFalse(FunctionConditionKpackImageReady, sc.Reason, sc.Message)
 	}
 }
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	validationutil "k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/projectriff/system/pkg/validation"
@@ -70,5 +71,17 @@ func (s *FunctionSpec) Validate() validation.FieldErrors {
 		errs = errs.Also(s.Source.Validate().ViaField("source"))
 	}
 
+	if s.Builder != nil {
+		errs = errs.Also(s.Builder.Validate().ViaField("builder"))
+	}
+
+	if s.ServiceAccountName != "" {
+		if out := validationutil.IsDNS1123Subdomain(s.ServiceAccountName); len(out) != 0 {
+			errs = errs.Also(validation.ErrInvalidValue(s.ServiceAccountName, "serviceAccountName"))
+		}
+	}
+
+	errs = errs.Also(s.RebuildPolicy.Validate().ViaField("rebuildPolicy"))
+
 	return errs
 }
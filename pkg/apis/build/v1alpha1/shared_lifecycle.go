@@ -0,0 +1,149 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
+)
+
+// BuildHistoryLimit is the number of builds recorded in the status.
+const BuildHistoryLimit = 10
+
+// PropagateKpackBuilds records the most recent builds for the kpack Image,
+// newest first.
+func (bs *BuildStatus) PropagateKpackBuilds(builds []kpackbuildv1alpha1.Build) {
+	records := make([]BuildRecord, len(builds))
+	for i := range builds {
+		records[i] = newBuildRecord(&builds[i])
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Number != records[j].Number {
+			return records[i].Number > records[j].Number
+		}
+		return records[j].StartTime.Before(records[i].StartTime)
+	})
+	if len(records) > BuildHistoryLimit {
+		records = records[:BuildHistoryLimit]
+	}
+	if len(records) == 0 {
+		records = nil
+	}
+	bs.Builds = records
+	bs.LatestBuilderImage = ""
+	if len(records) != 0 {
+		bs.LatestBuilderImage = records[0].BuilderImage
+	}
+}
+
+func newBuildRecord(build *kpackbuildv1alpha1.Build) BuildRecord {
+	record := BuildRecord{
+		Name:    build.Name,
+		Outcome: BuildOutcomeRunning,
+	}
+	if number, err := strconv.ParseInt(build.Labels[kpackbuildv1alpha1.BuildNumberLabel], 10, 64); err == nil {
+		record.Number = number
+	}
+	if build.Spec.Source.Git != nil {
+		record.Revision = build.Spec.Source.Git.Revision
+	}
+	if !build.CreationTimestamp.IsZero() {
+		startTime := build.CreationTimestamp
+		record.StartTime = &startTime
+	}
+
+	record.PodName = build.Status.PodName
+	record.BuilderImage = build.Spec.Builder.Image
+
+	ready := build.Status.GetCondition(apis.ConditionReady)
+	if ready == nil || ready.Status == corev1.ConditionUnknown {
+		return record
+	}
+	completionTime := ready.LastTransitionTime.Inner
+	record.CompletionTime = &completionTime
+	if ready.Status == corev1.ConditionTrue {
+		record.Outcome = BuildOutcomeSucceeded
+		record.Image = build.Status.LatestImage
+		return record
+	}
+	record.Outcome = BuildOutcomeFailed
+	record.FailureMessage = ready.Message
+	if step, terminated := failedBuildStep(&build.Status); terminated != nil {
+		record.FailedStep = step
+		record.ExitCode = terminated.ExitCode
+		if terminated.Message != "" {
+			record.FailureMessage = terminated.Message
+		}
+	}
+	return record
+}
+
+// failedBuildStep returns the name and termination state of the first step
+// that failed the build. kpack records the state of every step, but only the
+// names of the terminated steps, in the same order. Names are matched to the
+// terminated states rather than to the position in all states.
+func failedBuildStep(bs *kpackbuildv1alpha1.BuildStatus) (string, *corev1.ContainerStateTerminated) {
+	terminated := 0
+	for _, state := range bs.StepStates {
+		if state.Terminated == nil {
+			continue
+		}
+		name := ""
+		if terminated < len(bs.StepsCompleted) {
+			name = bs.StepsCompleted[terminated]
+		}
+		terminated++
+		if state.Terminated.ExitCode != 0 {
+			return name, state.Terminated
+		}
+	}
+	return "", nil
+}
+
+// latestFailedBuild returns the record for the latest build of the kpack Image
+// when that build failed.
+func (bs *BuildStatus) latestFailedBuild(is *kpackbuildv1alpha1.ImageStatus) *BuildRecord {
+	for i := range bs.Builds {
+		if bs.Builds[i].Name == is.LatestBuildRef {
+			if bs.Builds[i].Outcome == BuildOutcomeFailed {
+				return &bs.Builds[i]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *BuildRecord) failureSummary() string {
+	summary := fmt.Sprintf("build %s failed", r.Name)
+	if r.FailedStep != "" {
+		summary = fmt.Sprintf("%s in step %s with exit code %d", summary, r.FailedStep, r.ExitCode)
+	}
+	if r.FailureMessage != "" {
+		summary = fmt.Sprintf("%s: %s", summary, r.FailureMessage)
+	}
+	if r.PodName != "" {
+		summary = fmt.Sprintf("%s (logs in pod %s)", summary, r.PodName)
+	}
+	return summary
+}
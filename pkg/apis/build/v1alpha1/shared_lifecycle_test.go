@@ -0,0 +1,192 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
+)
+
+func TestBuildStatusPropagateKpackBuilds(t *testing.T) {
+	created := metav1.NewTime(time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC))
+	completed := metav1.NewTime(created.Add(time.Minute))
+
+	newBuild := func(name string, number int, createdAt metav1.Time) kpackbuildv1alpha1.Build {
+		return kpackbuildv1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: createdAt,
+				Labels: map[string]string{
+					kpackbuildv1alpha1.BuildNumberLabel: fmt.Sprintf("%d", number),
+				},
+			},
+			Spec: kpackbuildv1alpha1.BuildSpec{
+				Builder: kpackbuildv1alpha1.BuilderImage{Image: fmt.Sprintf("builder@sha256:%d", number)},
+			},
+		}
+	}
+	failed := func(build kpackbuildv1alpha1.Build, stepStates []corev1.ContainerState, stepsCompleted []string) kpackbuildv1alpha1.Build {
+		build.Status.PodName = build.Name + "-build-pod"
+		build.Status.Conditions = apis.Conditions{{
+			Type:               apis.ConditionReady,
+			Status:             corev1.ConditionFalse,
+			Message:            "pod failed",
+			LastTransitionTime: apis.VolatileTime{Inner: completed},
+		}}
+		build.Status.StepStates = stepStates
+		build.Status.StepsCompleted = stepsCompleted
+		return build
+	}
+	succeeded := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+
+	t.Run("step mapping", func(t *testing.T) {
+		for _, c := range []struct {
+			name           string
+			stepStates     []corev1.ContainerState
+			stepsCompleted []string
+			expected       BuildRecord
+		}{{
+			name: "failed step",
+			stepStates: []corev1.ContainerState{
+				succeeded,
+				succeeded,
+				{Terminated: &corev1.ContainerStateTerminated{ExitCode: 51, Message: "no buildpacks participating"}},
+				waiting,
+			},
+			stepsCompleted: []string{"prepare", "analyze", "detect"},
+			expected: BuildRecord{
+				FailedStep:     "detect",
+				ExitCode:       51,
+				FailureMessage: "no buildpacks participating",
+			},
+		}, {
+			name: "steps not terminated are skipped",
+			stepStates: []corev1.ContainerState{
+				running,
+				succeeded,
+				{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}},
+			},
+			stepsCompleted: []string{"prepare", "build"},
+			expected: BuildRecord{
+				FailedStep:     "build",
+				ExitCode:       1,
+				FailureMessage: "pod failed",
+			},
+		}, {
+			name: "unnamed step",
+			stepStates: []corev1.ContainerState{
+				{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2}},
+			},
+			expected: BuildRecord{
+				ExitCode:       2,
+				FailureMessage: "pod failed",
+			},
+		}, {
+			name:           "no failed step",
+			stepStates:     []corev1.ContainerState{succeeded, waiting},
+			stepsCompleted: []string{"prepare"},
+			expected: BuildRecord{
+				FailureMessage: "pod failed",
+			},
+		}} {
+			t.Run(c.name, func(t *testing.T) {
+				bs := &BuildStatus{}
+				bs.PropagateKpackBuilds([]kpackbuildv1alpha1.Build{
+					failed(newBuild("my-build", 1, created), c.stepStates, c.stepsCompleted),
+				})
+
+				expected := c.expected
+				expected.Name = "my-build"
+				expected.Number = 1
+				expected.StartTime = &created
+				expected.CompletionTime = &completed
+				expected.Outcome = BuildOutcomeFailed
+				expected.PodName = "my-build-build-pod"
+				expected.BuilderImage = "builder@sha256:1"
+				if diff := cmp.Diff([]BuildRecord{expected}, bs.Builds); diff != "" {
+					t.Errorf("PropagateKpackBuilds(%s) (-expected, +actual) = %v", c.name, diff)
+				}
+			})
+		}
+	})
+
+	t.Run("ordering", func(t *testing.T) {
+		bs := &BuildStatus{}
+		bs.PropagateKpackBuilds([]kpackbuildv1alpha1.Build{
+			newBuild("build-2", 2, created),
+			newBuild("build-3-older", 3, created),
+			newBuild("build-1", 1, created),
+			newBuild("build-3-newer", 3, metav1.NewTime(created.Add(time.Second))),
+		})
+
+		expected := []string{"build-3-newer", "build-3-older", "build-2", "build-1"}
+		actual := []string{}
+		for _, record := range bs.Builds {
+			actual = append(actual, record.Name)
+		}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Errorf("PropagateKpackBuilds() (-expected, +actual) = %v", diff)
+		}
+		if expected, actual := "builder@sha256:3", bs.LatestBuilderImage; expected != actual {
+			t.Errorf("PropagateKpackBuilds() expected latest builder image %q, got %q", expected, actual)
+		}
+	})
+
+	t.Run("truncation", func(t *testing.T) {
+		builds := []kpackbuildv1alpha1.Build{}
+		for i := 1; i <= BuildHistoryLimit+5; i++ {
+			builds = append(builds, newBuild(fmt.Sprintf("build-%d", i), i, created))
+		}
+		bs := &BuildStatus{}
+		bs.PropagateKpackBuilds(builds)
+
+		if expected, actual := BuildHistoryLimit, len(bs.Builds); expected != actual {
+			t.Fatalf("PropagateKpackBuilds() expected %d records, got %d", expected, actual)
+		}
+		if expected, actual := int64(BuildHistoryLimit+5), bs.Builds[0].Number; expected != actual {
+			t.Errorf("PropagateKpackBuilds() expected newest build %d first, got %d", expected, actual)
+		}
+		if expected, actual := int64(6), bs.Builds[BuildHistoryLimit-1].Number; expected != actual {
+			t.Errorf("PropagateKpackBuilds() expected oldest recorded build %d, got %d", expected, actual)
+		}
+	})
+
+	t.Run("no builds", func(t *testing.T) {
+		bs := &BuildStatus{
+			Builds:             []BuildRecord{{Name: "stale"}},
+			LatestBuilderImage: "builder@sha256:0",
+		}
+		bs.PropagateKpackBuilds(nil)
+
+		if bs.Builds != nil {
+			t.Errorf("PropagateKpackBuilds() expected no records, got %v", bs.Builds)
+		}
+		if bs.LatestBuilderImage != "" {
+			t.Errorf("PropagateKpackBuilds() expected no latest builder image, got %q", bs.LatestBuilderImage)
+		}
+	})
+}
@@ -29,6 +29,56 @@ var (
 	// credentials are not a CRD, but a Secret with this label
 	CredentialLabelKey       = GroupVersion.Group + "/credential"
 	CredentialsAnnotationKey = GroupVersion.Group + "/credentials"
+	// ServiceAccountsAnnotationKey on a credential lists the build service
+	// accounts it binds to, defaults to the riff-build service account
+	ServiceAccountsAnnotationKey = GroupVersion.Group + "/service-accounts"
+	// ServiceAccountLabelKey marks build service accounts managed by riff
+	ServiceAccountLabelKey = GroupVersion.Group + "/service-account"
+	// ImagePullSecretsAnnotationKey on a runtime service account lists the
+	// image pull secrets riff added
+	ImagePullSecretsAnnotationKey = GroupVersion.Group + "/image-pull-secrets"
+	// ImagePullSecretLabelKey on an image pull secret is the credential it was
+	// generated from
+	ImagePullSecretLabelKey = GroupVersion.Group + "/image-pull-secret"
+)
+
+const (
+	// BuildConfigMapName is the ConfigMap in each namespace configuring riff
+	// builds
+	BuildConfigMapName = "riff-build"
+	// RuntimeServiceAccountConfigKey in the build ConfigMap names the service
+	// account workloads run as by default. Registry credentials are added to
+	// the service account as image pull secrets.
+	RuntimeServiceAccountConfigKey = "runtime-service-account"
+)
+
+type BuilderKind string
+
+const (
+	ClusterBuilderKind    BuilderKind = "ClusterBuilder"
+	NamespacedBuilderKind BuilderKind = "Builder"
+)
+
+// BuilderReference selects the kpack builder used to build images.
+type BuilderReference struct {
+	// Kind of builder, either a cluster scoped kpack ClusterBuilder or a kpack
+	// Builder in the same namespace. Defaults to ClusterBuilder.
+	// +optional
+	Kind BuilderKind `json:"kind,omitempty"`
+
+	// Name of the builder.
+	Name string `json:"name"`
+}
+
+type RebuildPolicy string
+
+const (
+	// NeverRebuildPolicy builds only when the source or build configuration
+	// changes.
+	NeverRebuildPolicy RebuildPolicy = "Never"
+	// BuilderUpdateRebuildPolicy also builds when the builder provides a newer
+	// image.
+	BuilderUpdateRebuildPolicy RebuildPolicy = "OnBuilderUpdate"
 )
 
 type BuildStatus struct {
@@ -45,6 +95,88 @@ type BuildStatus struct {
 	// TargetImage is the resolved image repository where built images are
 	// pushed.
 	TargetImage string `json:"targetImage,omitempty"`
+
+	// LatestTrigger is the most recent git push that triggered a build.
+	LatestTrigger *BuildTrigger `json:"latestTrigger,omitempty"`
+
+	// Builds is the history of the most recent builds, newest first.
+	Builds []BuildRecord `json:"builds,omitempty"`
+
+	// LatestBuilderImage is the builder image used by the most recent build.
+	LatestBuilderImage string `json:"latestBuilderImage,omitempty"`
+}
+
+type BuildOutcome string
+
+const (
+	BuildOutcomeRunning   BuildOutcome = "Running"
+	BuildOutcomeSucceeded BuildOutcome = "Succeeded"
+	BuildOutcomeFailed    BuildOutcome = "Failed"
+)
+
+// BuildRecord summarizes a single kpack build.
+type BuildRecord struct {
+	// Name of the kpack Build.
+	Name string `json:"name"`
+
+	// Number is the sequence of the build for the kpack Image.
+	Number int64 `json:"number,omitempty"`
+
+	// Revision is the resolved git commit that was built.
+	Revision string `json:"revision,omitempty"`
+
+	// Image is the digest of the image produced by a successful build.
+	Image string `json:"image,omitempty"`
+
+	// StartTime is when the build was created.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the build succeeded or failed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Outcome of the build.
+	Outcome BuildOutcome `json:"outcome"`
+
+	// FailedStep is the name of the step that caused the build to fail.
+	FailedStep string `json:"failedStep,omitempty"`
+
+	// ExitCode of the failed step.
+	ExitCode int32 `json:"exitCode,omitempty"`
+
+	// FailureMessage is the termination message of the failed step, or the
+	// reason the build failed when no step failed.
+	FailureMessage string `json:"failureMessage,omitempty"`
+
+	// PodName is the pod running the build, its logs contain the build
+	// output.
+	PodName string `json:"podName,omitempty"`
+
+	// BuilderImage is the builder image the build ran with.
+	BuilderImage string `json:"builderImage,omitempty"`
+}
+
+// BuildTrigger records a git push received for the source of a build.
+type BuildTrigger struct {
+	// Repository is the git url of the source the commit was pushed to.
+	Repository string `json:"repository"`
+
+	// Branch the commit was pushed to.
+	Branch string `json:"branch"`
+
+	// Commit is the sha of the pushed commit.
+	Commit string `json:"commit"`
+
+	// Time the push was received.
+	Time metav1.Time `json:"time"`
+}
+
+// AppliesTo returns true if the trigger was received for the repository and
+// branch of the source. Triggers for stale sources are ignored.
+func (t *BuildTrigger) AppliesTo(source *Source) bool {
+	if t == nil || source == nil || source.Git == nil {
+		return false
+	}
+	return t.Repository == source.Git.URL && t.Branch == source.Git.Revision
 }
 
 // +k8s:deepcopy-gen=false
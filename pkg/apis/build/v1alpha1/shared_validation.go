@@ -0,0 +1,47 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func (b *BuilderReference) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	switch b.Kind {
+	case ClusterBuilderKind, NamespacedBuilderKind:
+	case "":
+		errs = errs.Also(validation.ErrMissingField("kind"))
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(b.Kind, "kind"))
+	}
+
+	if b.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	}
+
+	return errs
+}
+
+func (p RebuildPolicy) Validate() validation.FieldErrors {
+	switch p {
+	case "", NeverRebuildPolicy, BuilderUpdateRebuildPolicy:
+		return validation.FieldErrors{}
+	}
+	return validation.ErrInvalidValue(p, validation.CurrentField)
+}
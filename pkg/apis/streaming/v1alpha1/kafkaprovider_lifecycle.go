@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 
@@ -29,6 +31,7 @@ const (
 	KafkaProviderConditionGatewayServiceReady        apis.ConditionType = "GatewayServiceReady"
 	KafkaProviderConditionProvisionerDeploymentReady apis.ConditionType = "ProvisionerDeploymentReady"
 	KafkaProviderConditionProvisionerServiceReady    apis.ConditionType = "ProvisionerServiceReady"
+	KafkaProviderConditionBrokerReachable            apis.ConditionType = "BrokerReachable"
 )
 
 var providerCondSet = apis.NewLivingConditionSet(
@@ -36,6 +39,7 @@ var providerCondSet = apis.NewLivingConditionSet(
 	KafkaProviderConditionGatewayServiceReady,
 	KafkaProviderConditionProvisionerDeploymentReady,
 	KafkaProviderConditionProvisionerServiceReady,
+	KafkaProviderConditionBrokerReachable,
 )
 
 func (ps *KafkaProviderStatus) GetObservedGeneration() int64 {
@@ -123,3 +127,27 @@ func (ps *KafkaProviderStatus) PropagateProvisionerServiceStatus(ss *corev1.Serv
 	// services don't have meaningful status
 	providerCondSet.Manage(ps).MarkTrue(KafkaProviderConditionProvisionerServiceReady)
 }
+
+func (ps *KafkaProviderStatus) MarkBrokerReachable() {
+	providerCondSet.Manage(ps).MarkTrue(KafkaProviderConditionBrokerReachable)
+}
+
+func (ps *KafkaProviderStatus) MarkBrokerUnreachable(messageFormat string, messageA ...interface{}) {
+	providerCondSet.Manage(ps).MarkFalse(KafkaProviderConditionBrokerReachable, "BrokerUnreachable", messageFormat, messageA...)
+}
+
+// MarkBrokerReachabilityNotChecked reports broker connectivity as informational
+// when the provisioner is unable to probe the brokers. Only probe failures make
+// the provider not ready.
+func (ps *KafkaProviderStatus) MarkBrokerReachabilityNotChecked(reason, messageFormat string, messageA ...interface{}) {
+	manager := providerCondSet.Manage(ps)
+	manager.MarkTrue(KafkaProviderConditionBrokerReachable)
+	cond := *manager.GetCondition(KafkaProviderConditionBrokerReachable)
+	cond.Reason = reason
+	cond.Message = fmt.Sprintf(messageFormat, messageA...)
+	manager.SetCondition(cond)
+}
+
+func (ps *KafkaProviderStatus) MarkBrokerReachableUnknown(reason, messageFormat string, messageA ...interface{}) {
+	providerCondSet.Manage(ps).MarkUnknown(KafkaProviderConditionBrokerReachable, reason, messageFormat, messageA...)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+func TestKafkaProviderStatusBrokerReachable(t *testing.T) {
+	available := &appsv1.DeploymentStatus{
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+		},
+	}
+	newStatus := func() *KafkaProviderStatus {
+		ps := &KafkaProviderStatus{}
+		ps.InitializeConditions()
+		ps.PropagateGatewayDeploymentStatus(available)
+		ps.PropagateGatewayServiceStatus(&corev1.ServiceStatus{})
+		ps.PropagateProvisionerDeploymentStatus(available)
+		ps.PropagateProvisionerServiceStatus(&corev1.ServiceStatus{})
+		return ps
+	}
+
+	for _, c := range []struct {
+		name           string
+		mark           func(ps *KafkaProviderStatus)
+		expectedStatus corev1.ConditionStatus
+		expectedReason string
+		expectedReady  bool
+	}{{
+		name:           "reachable",
+		mark:           func(ps *KafkaProviderStatus) { ps.MarkBrokerReachable() },
+		expectedStatus: corev1.ConditionTrue,
+		expectedReady:  true,
+	}, {
+		name: "not checked",
+		mark: func(ps *KafkaProviderStatus) {
+			ps.MarkBrokerReachabilityNotChecked("HealthCheckNotSupported", "%s", "the provisioner does not report broker connectivity")
+		},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: "HealthCheckNotSupported",
+		expectedReady:  true,
+	}, {
+		name:           "unreachable",
+		mark:           func(ps *KafkaProviderStatus) { ps.MarkBrokerUnreachable("%s", "dial tcp: connection refused") },
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: "BrokerUnreachable",
+	}, {
+		name:           "unknown",
+		mark:           func(ps *KafkaProviderStatus) { ps.MarkBrokerReachableUnknown("ProvisionerNotReady", "waiting") },
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: "ProvisionerNotReady",
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			ps := newStatus()
+			c.mark(ps)
+			cond := ps.GetCondition(KafkaProviderConditionBrokerReachable)
+			if cond.Status != c.expectedStatus || cond.Reason != c.expectedReason {
+				t.Errorf("%s expected status %s with reason %q, got %s with reason %q", KafkaProviderConditionBrokerReachable, c.expectedStatus, c.expectedReason, cond.Status, cond.Reason)
+			}
+			if actual := ps.IsReady(); actual != c.expectedReady {
+				t.Errorf("IsReady() expected %v, got %v", c.expectedReady, actual)
+			}
+			if actual := ps.GetCondition(apis.ConditionReady).IsTrue(); actual != c.expectedReady {
+				t.Errorf("Ready condition expected true %v, got %v", c.expectedReady, actual)
+			}
+		})
+	}
+}
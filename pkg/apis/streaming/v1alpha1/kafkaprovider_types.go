@@ -31,6 +31,7 @@ var (
 	KafkaProviderLabelKey            = GroupVersion.Group + "/kafka-provider"             // Identifies all resources originating from a provider
 	KafkaProviderGatewayLabelKey     = GroupVersion.Group + "/kafka-provider-gateway"     // Used as a selector
 	KafkaProviderProvisionerLabelKey = GroupVersion.Group + "/kafka-provider-provisioner" // Used as a selector
+	KafkaProviderBrokerLabelKey      = GroupVersion.Group + "/kafka-provider-broker"      // Used as a selector
 	KafkaProvisioner                 = "kafka-provisioner"
 )
 
@@ -48,7 +49,11 @@ type KafkaProviderSpec struct {
 	// connects to initially to bootstrap itself.
 	//
 	// A host and port pair uses `:` as the separator.
-	BootstrapServers string `json:"bootstrapServers"`
+	//
+	// When omitted, a single node Kafka broker is run for the provider. The
+	// broker is meant for development, its data is lost when restarted.
+	// +optional
+	BootstrapServers string `json:"bootstrapServers,omitempty"`
 }
 
 // KafkaProviderStatus defines the observed state of KafkaProvider
@@ -61,6 +66,12 @@ type KafkaProviderStatus struct {
 	GatewayServiceRef        *refs.TypedLocalObjectReference `json:"gatewayServiceRef,omitempty"`
 	ProvisionerDeploymentRef *refs.TypedLocalObjectReference `json:"provisionerDeploymentRef,omitempty"`
 	ProvisionerServiceRef    *refs.TypedLocalObjectReference `json:"provisionerServiceRef,omitempty"`
+	BrokerStatefulSetRef     *refs.TypedLocalObjectReference `json:"brokerStatefulSetRef,omitempty"`
+	BrokerServiceRef         *refs.TypedLocalObjectReference `json:"brokerServiceRef,omitempty"`
+
+	// BootstrapServers the gateway and provisioner connect to, either from
+	// the spec or the embedded broker
+	BootstrapServers string `json:"bootstrapServers,omitempty"`
 }
 
 // +kubebuilder:object:root=true
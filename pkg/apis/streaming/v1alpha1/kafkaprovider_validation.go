@@ -17,7 +17,6 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/api/equality"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -56,15 +55,9 @@ func (r *KafkaProvider) Validate() validation.FieldErrors {
 }
 
 func (s *KafkaProviderSpec) Validate() validation.FieldErrors {
-	if equality.Semantic.DeepEqual(s, &KafkaProviderSpec{}) {
-		return validation.ErrMissingField(validation.CurrentField)
-	}
-
 	errs := validation.FieldErrors{}
 
-	if s.BootstrapServers == "" {
-		errs = errs.Also(validation.ErrMissingField("bootstrapServers"))
-	}
+	// an empty bootstrapServers runs an embedded broker
 
 	return errs
 }
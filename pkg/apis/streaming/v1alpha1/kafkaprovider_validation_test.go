@@ -32,7 +32,7 @@ func TestValidateKafkaProvider(t *testing.T) {
 	}{{
 		name:     "empty",
 		target:   &KafkaProvider{},
-		expected: validation.ErrMissingField("spec"),
+		expected: validation.FieldErrors{},
 	}, {
 		name: "valid",
 		target: &KafkaProvider{
@@ -57,9 +57,9 @@ func TestValidateKafkaProviderSpec(t *testing.T) {
 		target   *KafkaProviderSpec
 		expected validation.FieldErrors
 	}{{
-		name:     "empty",
+		name:     "embedded broker",
 		target:   &KafkaProviderSpec{},
-		expected: validation.ErrMissingField(validation.CurrentField),
+		expected: validation.FieldErrors{},
 	}, {
 		name: "valid",
 		target: &KafkaProviderSpec{
@@ -66,4 +66,37 @@ func (s *ProcessorSpec) Default() {
 	if s.Template.Spec.Volumes == nil {
 		s.Template.Spec.Volumes = []corev1.Volume{}
 	}
+
+	if s.ScalerPolicy == "" {
+		s.ScalerPolicy = ScalerPolicyGateway
+	}
+
+	if s.Mode == "" {
+		s.Mode = ProcessorModeDeployment
+	}
+
+	if s.State != nil {
+		s.State.Default()
+	}
+
+	if s.Invocation == nil {
+		s.Invocation = &ProcessorInvocation{}
+	}
+	s.Invocation.Default()
+}
+
+func (s *ProcessorInvocation) Default() {
+	if s.Port == 0 {
+		s.Port = 8081
+	}
+	if s.Protocol == "" {
+		s.Protocol = FunctionProtocolGRPC
+	}
+}
+
+func (s *ProcessorState) Default() {
+	if s.Replicas == nil {
+		one := int32(1)
+		s.Replicas = &one
+	}
 }
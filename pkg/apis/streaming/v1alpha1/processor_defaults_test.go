@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,6 +35,12 @@ func TestProcessorDefault(t *testing.T) {
 		in:   &Processor{},
 		want: &Processor{
 			Spec: ProcessorSpec{
+				ScalerPolicy: ScalerPolicyGateway,
+				Mode:         ProcessorModeDeployment,
+				Invocation: &ProcessorInvocation{
+					Port:     8081,
+					Protocol: FunctionProtocolGRPC,
+				},
 				Inputs:  []StreamBinding{},
 				Outputs: []StreamBinding{},
 				Template: &corev1.PodTemplateSpec{
@@ -72,6 +79,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 		name: "empty",
 		in:   &ProcessorSpec{},
 		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
 			Inputs:  []StreamBinding{},
 			Outputs: []StreamBinding{},
 			Template: &corev1.PodTemplateSpec{
@@ -97,6 +110,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 				{Stream: "my-output"},
 			}},
 		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
 			Inputs: []StreamBinding{
 				{Stream: "my-input", Alias: "my-input"},
 			},
@@ -126,6 +145,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 				{Stream: "my-output", Alias: "out"},
 			}},
 		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
 			Inputs: []StreamBinding{
 				{Stream: "my-input", Alias: "in"},
 			},
@@ -157,6 +182,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 			},
 		},
 		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
 			Inputs:  []StreamBinding{},
 			Outputs: []StreamBinding{},
 			Template: &corev1.PodTemplateSpec{
@@ -189,6 +220,12 @@ func TestProcessorSpecDefault(t *testing.T) {
 			},
 		},
 		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
 			Inputs:  []StreamBinding{},
 			Outputs: []StreamBinding{},
 			Template: &corev1.PodTemplateSpec{
@@ -209,6 +246,93 @@ func TestProcessorSpecDefault(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "preserves scaler policy",
+		in: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyNative,
+		},
+		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyNative,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+					Labels:      map[string]string{},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+					Volumes: []corev1.Volume{},
+				},
+			},
+		},
+	}, {
+		name: "add state replicas",
+		in: &ProcessorSpec{
+			State: &ProcessorState{
+				Size: resource.MustParse("1Gi"),
+			},
+		},
+		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeDeployment,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
+			State: &ProcessorState{
+				Size:     resource.MustParse("1Gi"),
+				Replicas: int32Ptr(1),
+			},
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+					Labels:      map[string]string{},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+					Volumes: []corev1.Volume{},
+				},
+			},
+		},
+	}, {
+		name: "preserves mode",
+		in: &ProcessorSpec{
+			Mode: ProcessorModeJob,
+		},
+		want: &ProcessorSpec{
+			ScalerPolicy: ScalerPolicyGateway,
+			Mode:         ProcessorModeJob,
+			Invocation: &ProcessorInvocation{
+				Port:     8081,
+				Protocol: FunctionProtocolGRPC,
+			},
+			Inputs:  []StreamBinding{},
+			Outputs: []StreamBinding{},
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+					Labels:      map[string]string{},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+					Volumes: []corev1.Volume{},
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
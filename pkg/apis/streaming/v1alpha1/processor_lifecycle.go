@@ -31,9 +31,12 @@ const (
 	ProcessorConditionStreamsReady      apis.ConditionType = "StreamsReady"
 	ProcessorConditionDeploymentReady   apis.ConditionType = "DeploymentReady"
 	ProcessorConditionScaledObjectReady apis.ConditionType = "ScaledObjectReady"
+	ProcessorConditionNativeScaler      apis.ConditionType = "NativeScaler"
+	ProcessorConditionScalingBackend    apis.ConditionType = "ScalingBackend"
 )
 
 var processorCondSet = apis.NewLivingConditionSet(
+	ProcessorConditionFunctionReady,
 	ProcessorConditionStreamsReady,
 	ProcessorConditionDeploymentReady,
 	ProcessorConditionScaledObjectReady,
@@ -59,6 +62,14 @@ func (ps *ProcessorStatus) InitializeConditions() {
 	processorCondSet.Manage(ps).InitializeConditions()
 }
 
+func (ps *ProcessorStatus) MarkFunctionReady() {
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionFunctionReady)
+}
+
+func (ps *ProcessorStatus) MarkFunctionNotReady(reason, messageFormat string, messageA ...interface{}) {
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionFunctionReady, reason, messageFormat, messageA...)
+}
+
 func (ps *ProcessorStatus) MarkStreamsReady() {
 	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionStreamsReady)
 }
@@ -67,6 +78,10 @@ func (ps *ProcessorStatus) MarkStreamsNotReady(message string) {
 	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionStreamsReady, "StreamNotReady", message)
 }
 
+func (ps *ProcessorStatus) MarkStreamAccessDenied(message string) {
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionStreamsReady, "StreamAccessDenied", message)
+}
+
 func (ps *ProcessorStatus) PropagateDeploymentStatus(ds *appsv1.DeploymentStatus) {
 	var available, progressing *appsv1.DeploymentCondition
 	for i := range ds.Conditions {
@@ -95,7 +110,54 @@ func (ps *ProcessorStatus) PropagateDeploymentStatus(ds *appsv1.DeploymentStatus
 	}
 }
 
+func (ps *ProcessorStatus) PropagateStatefulSetStatus(ss *appsv1.StatefulSetStatus) {
+	// stateful sets do not report conditions, compare replicas instead
+	if ss.ReadyReplicas < ss.Replicas {
+		processorCondSet.Manage(ps).MarkUnknown(ProcessorConditionDeploymentReady, "StatefulSetNotReady", "%d of %d replicas are ready", ss.ReadyReplicas, ss.Replicas)
+		return
+	}
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionDeploymentReady)
+}
+
+func (ps *ProcessorStatus) MarkDeploymentNotRequired() {
+	// no workload is needed, either jobs are run in place of a deployment or
+	// the processor is not allowed to run at all
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionDeploymentReady)
+}
+
 func (ps *ProcessorStatus) PropagateScaledObjectStatus(sos *kedav1alpha1.ScaledObjectStatus) {
 	// TODO: ScaledObject does not report much atm
 	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionScaledObjectReady)
 }
+
+func (ps *ProcessorStatus) MarkScaledObjectNotRequired() {
+	// the scaling backend does not rely on KEDA
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionScaledObjectReady)
+}
+
+func (ps *ProcessorStatus) MarkScaledObjectNotAvailable(messageFormat string, messageA ...interface{}) {
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionScaledObjectReady, "KEDANotInstalled", messageFormat, messageA...)
+}
+
+func (ps *ProcessorStatus) MarkScalingBackend(backend ScalingBackend, messageFormat string, messageA ...interface{}) {
+	ps.ScalingBackend = backend
+	if backend == ScalingBackendKEDA {
+		processorCondSet.Manage(ps).MarkTrue(ProcessorConditionScalingBackend)
+		return
+	}
+	// KEDA triggers are not used without KEDA
+	ps.Scaler = ""
+	processorCondSet.Manage(ps).ClearCondition(ProcessorConditionNativeScaler)
+	// report fallbacks as an informational false condition
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionScalingBackend, string(backend), messageFormat, messageA...)
+}
+
+func (ps *ProcessorStatus) MarkNativeScaler(scaler string) {
+	ps.Scaler = scaler
+	processorCondSet.Manage(ps).MarkTrue(ProcessorConditionNativeScaler)
+}
+
+func (ps *ProcessorStatus) MarkNativeScalerNotUsed(scaler, reason, messageFormat string, messageA ...interface{}) {
+	ps.Scaler = scaler
+	processorCondSet.Manage(ps).MarkFalse(ProcessorConditionNativeScaler, reason, messageFormat, messageA...)
+}
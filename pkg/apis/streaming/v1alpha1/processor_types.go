@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -56,8 +57,128 @@ type ProcessorSpec struct {
 	// Template pod
 	// +optional
 	Template *corev1.PodTemplateSpec `json:"template,omitempty"`
+
+	// ScalerPolicy defines how the processor inputs are monitored to scale
+	// the processor
+	// +optional
+	ScalerPolicy ScalerPolicy `json:"scalerPolicy,omitempty"`
+
+	// Mode defines the kind of workload running the processor
+	// +optional
+	// +kubebuilder:validation:Enum=deployment;job
+	Mode ProcessorMode `json:"mode,omitempty"`
+
+	// Job configures the jobs running the processor in job mode
+	// +optional
+	Job *ProcessorJob `json:"job,omitempty"`
+
+	// State provisions local storage for each replica of the processor,
+	// mounted into the function container at /var/riff/state. Stateful
+	// processors run a fixed number of replicas with a stable assignment of
+	// input partitions to replicas.
+	// +optional
+	State *ProcessorState `json:"state,omitempty"`
+
+	// Invocation describes how the processor invokes the function
+	// +optional
+	Invocation *ProcessorInvocation `json:"invocation,omitempty"`
+}
+
+type ProcessorInvocation struct {
+	// Port the function container listens on
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Protocol spoken by the function on the port
+	// +optional
+	Protocol FunctionProtocol `json:"protocol,omitempty"`
 }
 
+// FunctionProtocol describes how the processor communicates with the function.
+// Only one of the following protocols may be specified. If none of the
+// following protocols is specified, the default one is FunctionProtocolGRPC.
+type FunctionProtocol string
+
+const (
+	// FunctionProtocolGRPC streams messages to and from the function over
+	// gRPC
+	FunctionProtocolGRPC FunctionProtocol = "grpc"
+	// FunctionProtocolHTTP sends each message to the function as an HTTP
+	// request, the response holds the output message
+	FunctionProtocolHTTP FunctionProtocol = "http"
+	// FunctionProtocolRSocket streams messages to and from the function over
+	// RSocket
+	FunctionProtocolRSocket FunctionProtocol = "rsocket"
+)
+
+// ProcessorMode describes the kind of workload running the processor. Only
+// one of the following modes may be specified. If none of the following
+// modes is specified, the default one is ProcessorModeDeployment.
+type ProcessorMode string
+
+const (
+	// ProcessorModeDeployment runs the processor as a long-lived deployment
+	ProcessorModeDeployment ProcessorMode = "deployment"
+	// ProcessorModeJob runs the processor as jobs that drain the inputs and
+	// exit
+	ProcessorModeJob ProcessorMode = "job"
+)
+
+type ProcessorJob struct {
+	// Completions is the desired number of successfully finished pods per
+	// job
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+
+	// Parallelism is the maximum desired number of pods running at any
+	// given time per job
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+}
+
+type ProcessorState struct {
+	// Size of the volume provisioned for each replica
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName of the volume provisioned for each replica, the
+	// default storage class is used when not set
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Replicas is the number of replicas running the processor
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// ScalerPolicy describes how the processor inputs are monitored to scale the
+// processor. Only one of the following scaler policies may be specified. If
+// none of the following policies is specified, the default one is
+// ScalerPolicyGateway.
+type ScalerPolicy string
+
+const (
+	// ScalerPolicyGateway monitors inputs through the provider's gateway
+	ScalerPolicyGateway ScalerPolicy = "Gateway"
+	// ScalerPolicyNative monitors inputs directly on the backing broker,
+	// when all inputs are on a provider supporting it. Otherwise, inputs
+	// are monitored through the provider's gateway.
+	ScalerPolicyNative ScalerPolicy = "Native"
+)
+
+// ScalingBackend describes what scales the processor workload.
+type ScalingBackend string
+
+const (
+	// ScalingBackendKEDA scales the processor with a KEDA ScaledObject
+	ScalingBackendKEDA ScalingBackend = "KEDA"
+	// ScalingBackendHorizontalPodAutoscaler scales the processor deployment
+	// on CPU utilization, used when KEDA is not installed
+	ScalingBackendHorizontalPodAutoscaler ScalingBackend = "HorizontalPodAutoscaler"
+	// ScalingBackendFixedReplicas runs a single replica of the processor
+	// deployment, used when KEDA is not installed
+	ScalingBackendFixedReplicas ScalingBackend = "FixedReplicas"
+)
+
 type Build struct {
 	// ContainerRef references a container in this namespace.
 	ContainerRef string `json:"containerRef,omitempty"`
@@ -73,6 +194,14 @@ type StreamBinding struct {
 	// Alias exposes the stream under another name within the processor
 	// +optional
 	Alias string `json:"alias,omitempty"`
+
+	// Filter selects the messages of an input the function is invoked with.
+	// Other messages are acknowledged without invoking the function. The
+	// expression compares the message contentType, or a message header, to a
+	// quoted string, e.g.
+	// `contentType == 'application/json' && headers['priority'] != 'low'`
+	// +optional
+	Filter string `json:"filter,omitempty"`
 }
 
 // ProcessorStatus defines the observed state of Processor
@@ -86,8 +215,30 @@ type ProcessorStatus struct {
 	DeprecatedOutputAddresses    []string                        `json:"outputAddresses,omitempty"`
 	DeprecatedOutputContentTypes []string                        `json:"outputContentTypes,omitempty"`
 	DeploymentRef                *refs.TypedLocalObjectReference `json:"deploymentRef,omitempty"`
+	StatefulSetRef               *refs.TypedLocalObjectReference `json:"statefulSetRef,omitempty"`
 	ScaledObjectRef              *refs.TypedLocalObjectReference `json:"scaledObjectRef,omitempty"`
+	HorizontalPodAutoscalerRef   *refs.TypedLocalObjectReference `json:"horizontalPodAutoscalerRef,omitempty"`
 	LatestImage                  string                          `json:"latestImage,omitempty"`
+
+	// Scaler is the type of KEDA trigger used to scale the processor
+	Scaler string `json:"scaler,omitempty"`
+
+	// Jobs summarizes the jobs run in job mode
+	Jobs *ProcessorJobStatus `json:"jobs,omitempty"`
+
+	// ScalingBackend is what scales the processor workload
+	ScalingBackend ScalingBackend `json:"scalingBackend,omitempty"`
+}
+
+type ProcessorJobStatus struct {
+	// Active is the number of running jobs
+	Active int32 `json:"active"`
+
+	// Completed is the number of jobs that completed successfully
+	Completed int32 `json:"completed"`
+
+	// Failed is the number of jobs that failed
+	Failed int32 `json:"failed"`
 }
 
 // +kubebuilder:object:root=true
@@ -25,6 +25,7 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/projectriff/system/pkg/filter"
 	"github.com/projectriff/system/pkg/validation"
 )
 
@@ -43,7 +44,11 @@ func (r *Processor) ValidateCreate() error {
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *Processor) ValidateUpdate(old runtime.Object) error {
 	// TODO check for immutable fields
-	return r.Validate().ToAggregate()
+	errs := r.Validate()
+	if oldProcessor, ok := old.(*Processor); ok {
+		errs = errs.Also(r.validateStateUpdate(oldProcessor))
+	}
+	return errs.ToAggregate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -98,6 +103,11 @@ func (s *ProcessorSpec) Validate() validation.FieldErrors {
 		if input.Alias == "" {
 			errs = errs.Also(validation.ErrMissingField("alias").ViaFieldIndex("inputs", i))
 		}
+		if input.Filter != "" {
+			if _, err := filter.Parse(input.Filter); err != nil {
+				errs = errs.Also(validation.ErrInvalidValueWithDetail(input.Filter, "filter", err.Error()).ViaFieldIndex("inputs", i))
+			}
+		}
 	}
 
 	// outputs are optional
@@ -108,10 +118,36 @@ func (s *ProcessorSpec) Validate() validation.FieldErrors {
 		if output.Alias == "" {
 			errs = errs.Also(validation.ErrMissingField("alias").ViaFieldIndex("outputs", i))
 		}
+		if output.Filter != "" {
+			errs = errs.Also(validation.ErrDisallowedFields("filter", "only inputs may be filtered").ViaFieldIndex("outputs", i))
+		}
 	}
 
 	errs = errs.Also(s.validateStreamAliasUniqueness())
 
+	if s.ScalerPolicy != "" && s.ScalerPolicy != ScalerPolicyGateway && s.ScalerPolicy != ScalerPolicyNative {
+		errs = errs.Also(validation.ErrInvalidValue(s.ScalerPolicy, "scalerPolicy"))
+	}
+
+	if s.Mode != "" && s.Mode != ProcessorModeDeployment && s.Mode != ProcessorModeJob {
+		errs = errs.Also(validation.ErrInvalidValue(s.Mode, "mode"))
+	}
+	if s.Job != nil {
+		if s.Mode != ProcessorModeJob {
+			errs = errs.Also(validation.ErrDisallowedFields("job", "job may only be set in job mode"))
+		}
+		errs = errs.Also(s.Job.Validate().ViaField("job"))
+	}
+	if s.State != nil {
+		if s.Mode == ProcessorModeJob {
+			errs = errs.Also(validation.ErrDisallowedFields("state", "state may not be set in job mode"))
+		}
+		errs = errs.Also(s.State.Validate().ViaField("state"))
+	}
+	if s.Invocation != nil {
+		errs = errs.Also(s.Invocation.Validate().ViaField("invocation"))
+	}
+
 	return errs
 }
 
@@ -177,6 +213,94 @@ func (b *Build) Validate() validation.FieldErrors {
 	return errs
 }
 
+func (j *ProcessorJob) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if j.Completions != nil && *j.Completions < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*j.Completions, "completions"))
+	}
+	if j.Parallelism != nil && *j.Parallelism < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*j.Parallelism, "parallelism"))
+	}
+
+	return errs
+}
+
+func (s *ProcessorState) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Size.IsZero() {
+		errs = errs.Also(validation.ErrMissingField("size"))
+	} else if s.Size.Sign() < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(s.Size.String(), "size"))
+	}
+	if s.StorageClassName != nil && *s.StorageClassName == "" {
+		errs = errs.Also(validation.ErrInvalidValue(*s.StorageClassName, "storageClassName"))
+	}
+	if s.Replicas != nil && *s.Replicas < 1 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.Replicas, "replicas"))
+	}
+
+	return errs
+}
+
+// validateStateUpdate rejects changes to the volumes of a stateful processor.
+// The volume claims of a StatefulSet may not be updated, the volumes would
+// silently keep their original size and class.
+func (r *Processor) validateStateUpdate(old *Processor) validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if r.Spec.State == nil || old.Spec.State == nil {
+		return errs
+	}
+	if r.Spec.State.Size.Cmp(old.Spec.State.Size) != 0 {
+		errs = errs.Also(validation.ErrDisallowedFields("spec.state.size", "the volume size may not change"))
+	}
+	if !equality.Semantic.DeepEqual(r.Spec.State.StorageClassName, old.Spec.State.StorageClassName) {
+		errs = errs.Also(validation.ErrDisallowedFields("spec.state.storageClassName", "the storage class may not change"))
+	}
+
+	return errs
+}
+
+func (s *ProcessorInvocation) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Port < 0 || s.Port > 65535 {
+		errs = errs.Also(validation.ErrInvalidValue(s.Port, "port"))
+	}
+	if s.Protocol != "" && s.Protocol != FunctionProtocolGRPC && s.Protocol != FunctionProtocolHTTP && s.Protocol != FunctionProtocolRSocket {
+		errs = errs.Also(validation.ErrInvalidValue(s.Protocol, "protocol"))
+	}
+
+	return errs
+}
+
+// invokerProtocols lists the protocols supported by each invoker. Invokers
+// that are not listed, including detected invokers, are not restricted.
+var invokerProtocols = map[string][]FunctionProtocol{
+	"command": {FunctionProtocolGRPC, FunctionProtocolHTTP},
+	"java":    {FunctionProtocolGRPC, FunctionProtocolHTTP, FunctionProtocolRSocket},
+	"node":    {FunctionProtocolGRPC, FunctionProtocolHTTP},
+}
+
+// ValidateInvoker checks the protocol is supported by the invoker building the
+// function. The invoker is only known once the referenced function is
+// resolved, the check runs both when the processor is admitted and when it is
+// reconciled.
+func (s *ProcessorInvocation) ValidateInvoker(invoker string) validation.FieldErrors {
+	protocols, ok := invokerProtocols[invoker]
+	if !ok {
+		return validation.FieldErrors{}
+	}
+	for _, protocol := range protocols {
+		if s.Protocol == protocol {
+			return validation.FieldErrors{}
+		}
+	}
+	return validation.ErrInvalidValue(s.Protocol, "protocol")
+}
+
 func filterInvalidContainers(containers []corev1.Container) []corev1.Container {
 	// TODO remove unsupported fields
 	return containers
@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -264,6 +265,63 @@ func TestValidateProcessorSpec(t *testing.T) {
 			},
 		},
 		expected: validation.ErrDuplicateValue("my-alias", "inputs[0].alias", "outputs[0].alias"),
+	}, {
+		name: "input filter",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in", Filter: "headers['priority'] == 'high'"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid input filter",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "in", Filter: "priority = high"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+		},
+		expected: validation.ErrInvalidValueWithDetail("priority = high", "inputs[0].filter", "unexpected character '=' at position 9"),
+	}, {
+		name: "output filter",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream1", Alias: "in"},
+			},
+			Outputs: []StreamBinding{
+				{Stream: "my-stream2", Alias: "out", Filter: "contentType == 'text/plain'"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+		},
+		expected: validation.ErrDisallowedFields("outputs[0].filter", "only inputs may be filtered"),
 	}, {
 		name: "invalid container name",
 		target: &ProcessorSpec{
@@ -285,6 +343,200 @@ func TestValidateProcessorSpec(t *testing.T) {
 			},
 		},
 		expected: validation.ErrInvalidValue("processor", "template.spec.containers[0].name"),
+	}, {
+		name: "invalid scaler policy",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			ScalerPolicy: "bogus",
+		},
+		expected: validation.ErrInvalidValue(ScalerPolicy("bogus"), "scalerPolicy"),
+	}, {
+		name: "valid job mode",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			Mode: ProcessorModeJob,
+			Job: &ProcessorJob{
+				Completions: int32Ptr(1),
+				Parallelism: int32Ptr(2),
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "job mode as documented",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			Mode: "job",
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid mode",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			Mode: "bogus",
+		},
+		expected: validation.ErrInvalidValue(ProcessorMode("bogus"), "mode"),
+	}, {
+		name: "job requires job mode",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			Mode: ProcessorModeDeployment,
+			Job:  &ProcessorJob{},
+		},
+		expected: validation.ErrDisallowedFields("job", "job may only be set in job mode"),
+	}, {
+		name: "invalid job",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			Mode: ProcessorModeJob,
+			Job: &ProcessorJob{
+				Completions: int32Ptr(0),
+				Parallelism: int32Ptr(0),
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrInvalidValue(int32(0), "job.completions"),
+			validation.ErrInvalidValue(int32(0), "job.parallelism"),
+		),
+	}, {
+		name: "valid state",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			State: &ProcessorState{
+				Size:     resource.MustParse("1Gi"),
+				Replicas: int32Ptr(3),
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid state",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			State: &ProcessorState{
+				Replicas: int32Ptr(0),
+			},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("state.size"),
+			validation.ErrInvalidValue(int32(0), "state.replicas"),
+		),
+	}, {
+		name: "state in job mode",
+		target: &ProcessorSpec{
+			Build: &Build{
+				FunctionRef: "my-func",
+			},
+			Inputs: []StreamBinding{
+				{Stream: "my-stream", Alias: "my-input"},
+			},
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "function"},
+					},
+				},
+			},
+			Mode: ProcessorModeJob,
+			State: &ProcessorState{
+				Size: resource.MustParse("1Gi"),
+			},
+		},
+		expected: validation.ErrDisallowedFields("state", "state may not be set in job mode"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -295,6 +547,71 @@ func TestValidateProcessorSpec(t *testing.T) {
 	}
 }
 
+func TestValidateProcessorStateUpdate(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ProcessorState
+		old      *ProcessorState
+		expected validation.FieldErrors
+	}{{
+		name:     "stateless",
+		expected: validation.FieldErrors{},
+	}, {
+		name: "becomes stateful",
+		target: &ProcessorState{
+			Size: resource.MustParse("1Gi"),
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "replicas changed",
+		target: &ProcessorState{
+			Size:     resource.MustParse("1Gi"),
+			Replicas: int32Ptr(3),
+		},
+		old: &ProcessorState{
+			Size: resource.MustParse("1Gi"),
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "same size, different notation",
+		target: &ProcessorState{
+			Size: resource.MustParse("1024Mi"),
+		},
+		old: &ProcessorState{
+			Size: resource.MustParse("1Gi"),
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "size changed",
+		target: &ProcessorState{
+			Size: resource.MustParse("2Gi"),
+		},
+		old: &ProcessorState{
+			Size: resource.MustParse("1Gi"),
+		},
+		expected: validation.ErrDisallowedFields("spec.state.size", "the volume size may not change"),
+	}, {
+		name: "storage class changed",
+		target: &ProcessorState{
+			Size:             resource.MustParse("1Gi"),
+			StorageClassName: stringPtr("fast"),
+		},
+		old: &ProcessorState{
+			Size: resource.MustParse("1Gi"),
+		},
+		expected: validation.ErrDisallowedFields("spec.state.storageClassName", "the storage class may not change"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			target := &Processor{Spec: ProcessorSpec{State: c.target}}
+			old := &Processor{Spec: ProcessorSpec{State: c.old}}
+			actual := target.validateStateUpdate(old)
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStateUpdate(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
 func TestValidateBuild(t *testing.T) {
 	for _, c := range []struct {
 		name     string
@@ -332,3 +649,85 @@ func TestValidateBuild(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateProcessorInvocation(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ProcessorInvocation
+		expected validation.FieldErrors
+	}{{
+		name: "valid",
+		target: &ProcessorInvocation{
+			Port:     8080,
+			Protocol: FunctionProtocolHTTP,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid port",
+		target: &ProcessorInvocation{
+			Port: 65536,
+		},
+		expected: validation.ErrInvalidValue(int32(65536), "port"),
+	}, {
+		name: "invalid protocol",
+		target: &ProcessorInvocation{
+			Protocol: "bogus",
+		},
+		expected: validation.ErrInvalidValue(FunctionProtocol("bogus"), "protocol"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateProcessorInvocation(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateProcessorInvocationInvoker(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *ProcessorInvocation
+		invoker  string
+		expected validation.FieldErrors
+	}{{
+		name: "detected invoker",
+		target: &ProcessorInvocation{
+			Protocol: FunctionProtocolRSocket,
+		},
+		invoker:  "",
+		expected: validation.FieldErrors{},
+	}, {
+		name: "supported protocol",
+		target: &ProcessorInvocation{
+			Protocol: FunctionProtocolGRPC,
+		},
+		invoker:  "node",
+		expected: validation.FieldErrors{},
+	}, {
+		name: "unsupported protocol",
+		target: &ProcessorInvocation{
+			Protocol: FunctionProtocolGRPC,
+		},
+		invoker:  "command",
+		expected: validation.FieldErrors{},
+	}, {
+		name: "unsupported protocol",
+		target: &ProcessorInvocation{
+			Protocol: FunctionProtocolRSocket,
+		},
+		invoker:  "command",
+		expected: validation.ErrInvalidValue(FunctionProtocolRSocket, "protocol"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.ValidateInvoker(c.invoker)
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateProcessorInvocationInvoker(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -33,4 +33,22 @@ func (s *StreamSpec) Default() {
 	if s.ContentType == "" {
 		s.ContentType = "application/octet-stream"
 	}
+	if s.Schema != nil {
+		s.Schema.Default()
+	}
+	if s.Migration != nil {
+		s.Migration.Default()
+	}
+}
+
+func (s *StreamMigration) Default() {
+	if s.DrainTimeout == "" {
+		s.DrainTimeout = "5m"
+	}
+}
+
+func (s *StreamSchema) Default() {
+	if s.Compatibility == "" {
+		s.Compatibility = SchemaCompatibilityBackward
+	}
 }
@@ -67,6 +67,54 @@ func TestStreamSpecDefault(t *testing.T) {
 		want: &StreamSpec{
 			ContentType: "application/x-doom",
 		},
+	}, {
+		name: "schema compatibility is defaulted",
+		in: &StreamSpec{
+			Schema: &StreamSchema{},
+		},
+		want: &StreamSpec{
+			ContentType: "application/octet-stream",
+			Schema: &StreamSchema{
+				Compatibility: SchemaCompatibilityBackward,
+			},
+		},
+	}, {
+		name: "schema compatibility is not overwritten",
+		in: &StreamSpec{
+			Schema: &StreamSchema{
+				Compatibility: SchemaCompatibilityNone,
+			},
+		},
+		want: &StreamSpec{
+			ContentType: "application/octet-stream",
+			Schema: &StreamSchema{
+				Compatibility: SchemaCompatibilityNone,
+			},
+		},
+	}, {
+		name: "migration drain timeout is defaulted",
+		in: &StreamSpec{
+			Migration: &StreamMigration{},
+		},
+		want: &StreamSpec{
+			ContentType: "application/octet-stream",
+			Migration: &StreamMigration{
+				DrainTimeout: "5m",
+			},
+		},
+	}, {
+		name: "migration drain timeout is not overwritten",
+		in: &StreamSpec{
+			Migration: &StreamMigration{
+				DrainTimeout: "0s",
+			},
+		},
+		want: &StreamSpec{
+			ContentType: "application/octet-stream",
+			Migration: &StreamMigration{
+				DrainTimeout: "0s",
+			},
+		},
 	}}
 
 	for _, test := range tests {
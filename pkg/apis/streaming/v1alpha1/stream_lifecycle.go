@@ -24,11 +24,16 @@ const (
 	StreamConditionReady                                = apis.ConditionReady
 	StreamConditionResourceAvailable apis.ConditionType = "ResourceAvailable"
 	StreamConditionBindingReady      apis.ConditionType = "BindingReady"
+	StreamConditionSchemaReady       apis.ConditionType = "SchemaReady"
+	StreamConditionBrokerReachable   apis.ConditionType = "BrokerReachable"
+	StreamConditionMigrated          apis.ConditionType = "Migrated"
 )
 
 var streamCondSet = apis.NewLivingConditionSet(
 	StreamConditionResourceAvailable,
 	StreamConditionBindingReady,
+	StreamConditionSchemaReady,
+	StreamConditionBrokerReachable,
 )
 
 func (ss *StreamStatus) GetObservedGeneration() int64 {
@@ -66,3 +71,35 @@ func (ss *StreamStatus) MarkBindingReady() {
 func (ss *StreamStatus) MarkBindingNotReady(message string) {
 	streamCondSet.Manage(ss).MarkFalse(StreamConditionBindingReady, "BindingFailed", message)
 }
+
+func (ss *StreamStatus) MarkSchemaReady() {
+	streamCondSet.Manage(ss).MarkTrue(StreamConditionSchemaReady)
+}
+
+func (ss *StreamStatus) MarkSchemaNotReady(reason, message string, messageA ...interface{}) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionSchemaReady, reason, message, messageA...)
+}
+
+func (ss *StreamStatus) MarkMigrated() {
+	streamCondSet.Manage(ss).MarkTrue(StreamConditionMigrated)
+}
+
+func (ss *StreamStatus) MarkMigrating(phase StreamMigrationPhase, messageFormat string, messageA ...interface{}) {
+	streamCondSet.Manage(ss).MarkUnknown(StreamConditionMigrated, string(phase), messageFormat, messageA...)
+}
+
+func (ss *StreamStatus) MarkMigrationFailed(reason, messageFormat string, messageA ...interface{}) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionMigrated, reason, messageFormat, messageA...)
+}
+
+func (ss *StreamStatus) MarkBrokerReachable() {
+	streamCondSet.Manage(ss).MarkTrue(StreamConditionBrokerReachable)
+}
+
+func (ss *StreamStatus) MarkBrokerUnreachable(messageFormat string, messageA ...interface{}) {
+	streamCondSet.Manage(ss).MarkFalse(StreamConditionBrokerReachable, "BrokerUnreachable", messageFormat, messageA...)
+}
+
+func (ss *StreamStatus) MarkBrokerReachableUnknown(reason, messageFormat string, messageA ...interface{}) {
+	streamCondSet.Manage(ss).MarkUnknown(StreamConditionBrokerReachable, reason, messageFormat, messageA...)
+}
@@ -21,9 +21,11 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	apis "github.com/projectriff/system/pkg/apis"
+	"github.com/projectriff/system/pkg/refs"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -44,8 +46,110 @@ type StreamSpec struct {
 
 	Provider    string `json:"provider"`
 	ContentType string `json:"contentType"`
+
+	// Schema describes the messages on the stream. Messages are validated
+	// against the schema when ingested over HTTP.
+	// +optional
+	Schema *StreamSchema `json:"schema,omitempty"`
+
+	// Access restricts which processors may publish to and consume from the
+	// stream. Any processor in the namespace is allowed when not set.
+	// +optional
+	Access *StreamAccess `json:"access,omitempty"`
+
+	// Migration controls how the stream moves to a new provider when the
+	// provider changes
+	// +optional
+	Migration *StreamMigration `json:"migration,omitempty"`
+}
+
+type StreamMigration struct {
+	// Bridge copies the data remaining on the previous provider to the new
+	// provider once the drain timeout elapsed, before the previous provider is
+	// deprovisioned
+	// +optional
+	Bridge bool `json:"bridge,omitempty"`
+
+	// DrainTimeout is how long producers and consumers are given to move to
+	// the new provider once the binding switched, expressed as a duration
+	// (e.g. 5m). The previous provider is bridged, when enabled, and
+	// deprovisioned afterwards.
+	// +optional
+	DrainTimeout string `json:"drainTimeout,omitempty"`
+}
+
+type StreamAccess struct {
+	// Publishers allowed to bind the stream as an output. Any processor is
+	// allowed when empty.
+	// +optional
+	Publishers []StreamAccessor `json:"publishers,omitempty"`
+
+	// Consumers allowed to bind the stream as an input. Any processor is
+	// allowed when empty.
+	// +optional
+	Consumers []StreamAccessor `json:"consumers,omitempty"`
+}
+
+// StreamAccessor matches processors either by name or by labels. Only one of
+// the fields may be specified.
+type StreamAccessor struct {
+	// Processor name, from this namespace
+	// +optional
+	Processor string `json:"processor,omitempty"`
+
+	// Selector for processor labels
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
+type StreamSchema struct {
+	// Type of the schema definition
+	Type SchemaType `json:"type"`
+
+	// Inline schema definition
+	// +optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMapRef selects a key of a ConfigMap, from this namespace, holding
+	// the schema definition
+	// +optional
+	ConfigMapRef *corev1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
+	// Compatibility required from a new schema definition with the previous
+	// one
+	// +optional
+	Compatibility SchemaCompatibility `json:"compatibility,omitempty"`
+}
+
+// SchemaType describes the language of a schema definition. Only one of the
+// following schema types may be specified.
+type SchemaType string
+
+const (
+	SchemaTypeJSONSchema SchemaType = "JSONSchema"
+	SchemaTypeAvro       SchemaType = "Avro"
+)
+
+// SchemaCompatibility describes how a schema definition may evolve. Only one
+// of the following compatibility modes may be specified. If none of the
+// following modes is specified, the default one is
+// SchemaCompatibilityBackward.
+type SchemaCompatibility string
+
+const (
+	// SchemaCompatibilityNone accepts any new schema definition
+	SchemaCompatibilityNone SchemaCompatibility = "None"
+	// SchemaCompatibilityBackward requires the new schema definition to read
+	// messages written with the previous one
+	SchemaCompatibilityBackward SchemaCompatibility = "Backward"
+	// SchemaCompatibilityForward requires the previous schema definition to
+	// read messages written with the new one
+	SchemaCompatibilityForward SchemaCompatibility = "Forward"
+	// SchemaCompatibilityFull requires both backward and forward
+	// compatibility
+	SchemaCompatibilityFull SchemaCompatibility = "Full"
+)
+
 // StreamStatus defines the observed state of Stream
 type StreamStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -56,6 +160,61 @@ type StreamStatus struct {
 	Address StreamAddress `json:"address,omitempty"`
 
 	Binding BindingReference `json:"binding,omitempty"`
+
+	// Provider the stream address is provisioned on
+	Provider string `json:"provider,omitempty"`
+
+	// Migration to a new provider, while in progress
+	Migration *StreamMigrationStatus `json:"migration,omitempty"`
+}
+
+type StreamMigrationStatus struct {
+	// Phase of the migration
+	Phase StreamMigrationPhase `json:"phase"`
+
+	// FromProvider the stream is migrated from
+	FromProvider string `json:"fromProvider"`
+
+	// FromAddress on the previous provider
+	FromAddress StreamAddress `json:"fromAddress,omitempty"`
+
+	// ToProvider the stream is migrated to
+	ToProvider string `json:"toProvider"`
+
+	// ToAddress on the new provider
+	ToAddress StreamAddress `json:"toAddress,omitempty"`
+
+	// BridgeRef references the job copying data between providers
+	BridgeRef *refs.TypedLocalObjectReference `json:"bridgeRef,omitempty"`
+
+	// DrainingSince is when the binding switched to the new provider and
+	// consumers started draining the previous provider
+	DrainingSince *metav1.Time `json:"drainingSince,omitempty"`
+}
+
+// StreamMigrationPhase describes the progress of a migration between
+// providers.
+type StreamMigrationPhase string
+
+const (
+	// StreamMigrationPhaseProvisioning provisions the stream on the new
+	// provider
+	StreamMigrationPhaseProvisioning StreamMigrationPhase = "Provisioning"
+	// StreamMigrationPhaseDraining gives producers and consumers time to move
+	// to the new provider, the binding already references the new provider
+	StreamMigrationPhaseDraining StreamMigrationPhase = "Draining"
+	// StreamMigrationPhaseBridging copies the data remaining on the previous
+	// provider to the new provider, once drained
+	StreamMigrationPhaseBridging StreamMigrationPhase = "Bridging"
+	// StreamMigrationPhaseDeprovisioning removes the stream from the previous
+	// provider, the binding already references the new provider
+	StreamMigrationPhaseDeprovisioning StreamMigrationPhase = "Deprovisioning"
+)
+
+// Switched is true once the binding references the new provider, the
+// migration may no longer be abandoned.
+func (p StreamMigrationPhase) Switched() bool {
+	return p == StreamMigrationPhaseDraining || p == StreamMigrationPhaseBridging || p == StreamMigrationPhaseDeprovisioning
 }
 
 type StreamAddress struct {
@@ -75,6 +234,52 @@ func (a StreamAddress) String() string {
 	return fmt.Sprintf("%s/%s", a.Gateway, a.Topic)
 }
 
+// AllowsPublisher returns true when the processor may bind the stream as an
+// output.
+func (s *Stream) AllowsPublisher(processor *Processor) bool {
+	if s.Spec.Access == nil {
+		return true
+	}
+	return allowsAccess(s.Spec.Access.Publishers, processor)
+}
+
+// AllowsConsumer returns true when the processor may bind the stream as an
+// input.
+func (s *Stream) AllowsConsumer(processor *Processor) bool {
+	if s.Spec.Access == nil {
+		return true
+	}
+	return allowsAccess(s.Spec.Access.Consumers, processor)
+}
+
+func allowsAccess(accessors []StreamAccessor, processor *Processor) bool {
+	if len(accessors) == 0 {
+		return true
+	}
+	for _, accessor := range accessors {
+		if accessor.Matches(processor) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns true when the accessor selects the processor.
+func (a StreamAccessor) Matches(processor *Processor) bool {
+	if a.Processor != "" {
+		return a.Processor == processor.Name
+	}
+	if a.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(a.Selector)
+	if err != nil {
+		// the validation webhook rejects malformed selectors
+		return false
+	}
+	return selector.Matches(labels.Set(processor.Labels))
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:categories="riff"
@@ -17,7 +17,13 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -39,7 +45,11 @@ func (r *Stream) ValidateCreate() error {
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *Stream) ValidateUpdate(old runtime.Object) error {
 	// TODO check for immutable fields
-	return r.Validate().ToAggregate()
+	errs := r.Validate()
+	if oldStream, ok := old.(*Stream); ok {
+		errs = errs.Also(r.validateSchemaUpdate(oldStream))
+	}
+	return errs.ToAggregate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -66,5 +76,275 @@ func (s *StreamSpec) Validate() validation.FieldErrors {
 		errs = errs.Also(validation.ErrMissingField("provider"))
 	}
 
+	if s.Schema != nil {
+		errs = errs.Also(s.Schema.Validate().ViaField("schema"))
+	}
+
+	if s.Access != nil {
+		errs = errs.Also(s.Access.Validate().ViaField("access"))
+	}
+
+	if s.Migration != nil {
+		errs = errs.Also(s.Migration.Validate().ViaField("migration"))
+	}
+
 	return errs
 }
+
+func (s *StreamMigration) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.DrainTimeout != "" {
+		if timeout, err := time.ParseDuration(s.DrainTimeout); err != nil || timeout < 0 {
+			errs = errs.Also(validation.ErrInvalidValue(s.DrainTimeout, "drainTimeout"))
+		}
+	}
+
+	return errs
+}
+
+func (s *StreamAccess) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	for i, publisher := range s.Publishers {
+		errs = errs.Also(publisher.Validate().ViaFieldIndex("publishers", i))
+	}
+	for i, consumer := range s.Consumers {
+		errs = errs.Also(consumer.Validate().ViaFieldIndex("consumers", i))
+	}
+
+	return errs
+}
+
+func (a *StreamAccessor) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if a.Processor == "" && a.Selector == nil {
+		errs = errs.Also(validation.ErrMissingOneOf("processor", "selector"))
+	} else if a.Processor != "" && a.Selector != nil {
+		errs = errs.Also(validation.ErrMultipleOneOf("processor", "selector"))
+	} else if a.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(a.Selector); err != nil {
+			errs = errs.Also(validation.ErrInvalidValue(a.Selector, "selector"))
+		}
+	}
+
+	return errs
+}
+
+func (s *StreamSchema) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Type == "" {
+		errs = errs.Also(validation.ErrMissingField("type"))
+	} else if s.Type != SchemaTypeJSONSchema && s.Type != SchemaTypeAvro {
+		errs = errs.Also(validation.ErrInvalidValue(s.Type, "type"))
+	}
+
+	if s.Inline == "" && s.ConfigMapRef == nil {
+		errs = errs.Also(validation.ErrMissingOneOf("configMapRef", "inline"))
+	} else if s.Inline != "" && s.ConfigMapRef != nil {
+		errs = errs.Also(validation.ErrMultipleOneOf("configMapRef", "inline"))
+	} else if s.ConfigMapRef != nil {
+		if s.ConfigMapRef.Name == "" {
+			errs = errs.Also(validation.ErrMissingField("configMapRef.name"))
+		}
+		if s.ConfigMapRef.Key == "" {
+			errs = errs.Also(validation.ErrMissingField("configMapRef.key"))
+		}
+	} else if s.Type == SchemaTypeJSONSchema || s.Type == SchemaTypeAvro {
+		// schemas held by a ConfigMap are validated once resolved
+		if err := ValidateSchemaDefinition(s.Type, s.Inline); err != nil {
+			errs = errs.Also(validation.ErrInvalidValue(s.Inline, "inline"))
+		}
+	}
+
+	switch s.Compatibility {
+	case "", SchemaCompatibilityNone, SchemaCompatibilityBackward, SchemaCompatibilityForward, SchemaCompatibilityFull:
+	default:
+		errs = errs.Also(validation.ErrInvalidValue(s.Compatibility, "compatibility"))
+	}
+
+	return errs
+}
+
+func (r *Stream) validateSchemaUpdate(old *Stream) validation.FieldErrors {
+	// schemas held by a ConfigMap are checked for compatibility once resolved
+	if r.Spec.Schema == nil || old.Spec.Schema == nil || r.Spec.Schema.Inline == "" || old.Spec.Schema.Inline == "" {
+		return validation.FieldErrors{}
+	}
+	// the compatibility promised to existing consumers applies to the change,
+	// not the one requested alongside it
+	if err := CheckSchemaCompatibility(old.Spec.Schema.Compatibility, old.Spec.Schema.Type, old.Spec.Schema.Inline, r.Spec.Schema.Type, r.Spec.Schema.Inline); err != nil {
+		return validation.ErrDisallowedFields("spec.schema", err.Error())
+	}
+	return validation.FieldErrors{}
+}
+
+// ValidateSchemaDefinition checks the definition is a well formed schema of
+// the type.
+func ValidateSchemaDefinition(schemaType SchemaType, definition string) error {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(definition), &schema); err != nil {
+		return err
+	}
+	switch schemaType {
+	case SchemaTypeJSONSchema:
+		if _, ok := schema.(map[string]interface{}); !ok {
+			return fmt.Errorf("a JSON schema must be an object")
+		}
+	case SchemaTypeAvro:
+		// primitive types may be named by a string
+		if _, ok := schema.(string); ok {
+			return nil
+		}
+		object, ok := schema.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("an Avro schema must be a string or an object")
+		}
+		if _, ok := object["type"]; !ok {
+			return fmt.Errorf("an Avro schema must have a type")
+		}
+		if object["type"] == "record" {
+			if _, ok := object["name"].(string); !ok {
+				return fmt.Errorf("an Avro record must have a name")
+			}
+			if _, ok := object["fields"].([]interface{}); !ok {
+				return fmt.Errorf("an Avro record must have fields")
+			}
+		}
+	default:
+		return fmt.Errorf("unknown schema type %q", schemaType)
+	}
+	return nil
+}
+
+// CheckSchemaCompatibility checks the next schema definition may replace the
+// previous one according to the compatibility mode. Backward compatibility
+// requires the next schema to read messages written with the previous one,
+// forward compatibility requires the opposite. The schema type may only
+// change when no compatibility is required.
+func CheckSchemaCompatibility(compatibility SchemaCompatibility, previousType SchemaType, previous string, nextType SchemaType, next string) error {
+	if compatibility == SchemaCompatibilityNone {
+		return nil
+	}
+	if previousType != nextType {
+		return fmt.Errorf("schema type may not change from %q to %q unless the compatibility is %q", previousType, nextType, SchemaCompatibilityNone)
+	}
+	if previous == next {
+		return nil
+	}
+
+	var canRead func(reader, writer interface{}) error
+	switch nextType {
+	case SchemaTypeJSONSchema:
+		canRead = jsonSchemaCanRead
+	case SchemaTypeAvro:
+		canRead = avroCanRead
+	default:
+		return fmt.Errorf("unknown schema type %q", nextType)
+	}
+
+	var previousSchema, nextSchema interface{}
+	if err := json.Unmarshal([]byte(previous), &previousSchema); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(next), &nextSchema); err != nil {
+		return err
+	}
+
+	if compatibility == SchemaCompatibilityBackward || compatibility == SchemaCompatibilityFull {
+		if err := canRead(nextSchema, previousSchema); err != nil {
+			return fmt.Errorf("schema is not backward compatible: %v", err)
+		}
+	}
+	if compatibility == SchemaCompatibilityForward || compatibility == SchemaCompatibilityFull {
+		if err := canRead(previousSchema, nextSchema); err != nil {
+			return fmt.Errorf("schema is not forward compatible: %v", err)
+		}
+	}
+	return nil
+}
+
+func jsonSchemaCanRead(reader, writer interface{}) error {
+	readerObject, _ := reader.(map[string]interface{})
+	writerObject, _ := writer.(map[string]interface{})
+	if !reflect.DeepEqual(readerObject["type"], writerObject["type"]) {
+		return fmt.Errorf("type changed")
+	}
+
+	readerProperties, _ := readerObject["properties"].(map[string]interface{})
+	writerProperties, _ := writerObject["properties"].(map[string]interface{})
+	writerRequired := map[interface{}]bool{}
+	if required, ok := writerObject["required"].([]interface{}); ok {
+		for _, name := range required {
+			writerRequired[name] = true
+		}
+	}
+	if required, ok := readerObject["required"].([]interface{}); ok {
+		for _, name := range required {
+			if !writerRequired[name] {
+				return fmt.Errorf("property %v is required but may be missing", name)
+			}
+		}
+	}
+	for name, readerProperty := range readerProperties {
+		writerProperty, ok := writerProperties[name]
+		if !ok {
+			continue
+		}
+		readerType, _ := readerProperty.(map[string]interface{})
+		writerType, _ := writerProperty.(map[string]interface{})
+		if !reflect.DeepEqual(readerType["type"], writerType["type"]) {
+			return fmt.Errorf("property %s changed type", name)
+		}
+	}
+	if readerObject["additionalProperties"] == false {
+		for name := range writerProperties {
+			if _, ok := readerProperties[name]; !ok {
+				return fmt.Errorf("property %s is not allowed", name)
+			}
+		}
+	}
+	return nil
+}
+
+func avroCanRead(reader, writer interface{}) error {
+	readerRecord, _ := reader.(map[string]interface{})
+	writerRecord, _ := writer.(map[string]interface{})
+	if readerRecord["type"] != "record" || writerRecord["type"] != "record" {
+		// only records may evolve
+		if !reflect.DeepEqual(reader, writer) {
+			return fmt.Errorf("type changed")
+		}
+		return nil
+	}
+
+	writerFields := map[interface{}]map[string]interface{}{}
+	if fields, ok := writerRecord["fields"].([]interface{}); ok {
+		for _, field := range fields {
+			if field, ok := field.(map[string]interface{}); ok {
+				writerFields[field["name"]] = field
+			}
+		}
+	}
+	if fields, ok := readerRecord["fields"].([]interface{}); ok {
+		for _, field := range fields {
+			readerField, ok := field.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			writerField, ok := writerFields[readerField["name"]]
+			if !ok {
+				if _, ok := readerField["default"]; !ok {
+					return fmt.Errorf("field %v has no default but may be missing", readerField["name"])
+				}
+				continue
+			}
+			if !reflect.DeepEqual(readerField["type"], writerField["type"]) {
+				return fmt.Errorf("field %v changed type", readerField["name"])
+			}
+		}
+	}
+	return nil
+}
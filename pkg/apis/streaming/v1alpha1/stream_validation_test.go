@@ -20,6 +20,8 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/projectriff/system/pkg/validation"
 )
@@ -81,6 +83,62 @@ func TestValidateStreamSpec(t *testing.T) {
 			ContentType: "image/*",
 		},
 		expected: validation.ErrMissingField("provider"),
+	}, {
+		name: "valid migration",
+		target: &StreamSpec{
+			Provider: "kafka",
+			Migration: &StreamMigration{
+				DrainTimeout: "10m",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid migration drain timeout",
+		target: &StreamSpec{
+			Provider: "kafka",
+			Migration: &StreamMigration{
+				DrainTimeout: "later",
+			},
+		},
+		expected: validation.ErrInvalidValue("later", "migration.drainTimeout"),
+	}, {
+		name: "valid schema",
+		target: &StreamSpec{
+			Provider: "kafka",
+			Schema: &StreamSchema{
+				Type:   SchemaTypeJSONSchema,
+				Inline: `{"type": "object"}`,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid schema",
+		target: &StreamSpec{
+			Provider: "kafka",
+			Schema:   &StreamSchema{},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("schema.type"),
+			validation.ErrMissingOneOf("configMapRef", "inline").ViaField("schema"),
+		),
+	}, {
+		name: "valid access",
+		target: &StreamSpec{
+			Provider: "kafka",
+			Access: &StreamAccess{
+				Publishers: []StreamAccessor{{Processor: "auditor"}},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid access",
+		target: &StreamSpec{
+			Provider: "kafka",
+			Access: &StreamAccess{
+				Publishers: []StreamAccessor{{}},
+			},
+		},
+		expected: validation.ErrMissingOneOf("processor", "selector").ViaFieldIndex("publishers", 0).ViaField("access"),
 	}} {
 		t.Run(c.name, func(t *testing.T) {
 			actual := c.target.Validate()
@@ -90,3 +148,441 @@ func TestValidateStreamSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStreamSchema(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamSchema
+		expected validation.FieldErrors
+	}{{
+		name: "valid inline JSON schema",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid inline Avro schema",
+		target: &StreamSchema{
+			Type:          SchemaTypeAvro,
+			Inline:        `{"type": "record", "name": "user", "fields": [{"name": "name", "type": "string"}]}`,
+			Compatibility: SchemaCompatibilityFull,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid configMapRef",
+		target: &StreamSchema{
+			Type: SchemaTypeAvro,
+			ConfigMapRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "schemas"},
+				Key:                  "user.avsc",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "invalid type",
+		target: &StreamSchema{
+			Type:   SchemaType("Protobuf"),
+			Inline: `{}`,
+		},
+		expected: validation.ErrInvalidValue(SchemaType("Protobuf"), "type"),
+	}, {
+		name: "inline and configMapRef",
+		target: &StreamSchema{
+			Type:   SchemaTypeJSONSchema,
+			Inline: `{}`,
+			ConfigMapRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "schemas"},
+				Key:                  "user.json",
+			},
+		},
+		expected: validation.ErrMultipleOneOf("configMapRef", "inline"),
+	}, {
+		name: "empty configMapRef",
+		target: &StreamSchema{
+			Type:         SchemaTypeJSONSchema,
+			ConfigMapRef: &corev1.ConfigMapKeySelector{},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("configMapRef.name"),
+			validation.ErrMissingField("configMapRef.key"),
+		),
+	}, {
+		name: "malformed JSON schema",
+		target: &StreamSchema{
+			Type:   SchemaTypeJSONSchema,
+			Inline: `["type"]`,
+		},
+		expected: validation.ErrInvalidValue(`["type"]`, "inline"),
+	}, {
+		name: "Avro record without fields",
+		target: &StreamSchema{
+			Type:   SchemaTypeAvro,
+			Inline: `{"type": "record", "name": "user"}`,
+		},
+		expected: validation.ErrInvalidValue(`{"type": "record", "name": "user"}`, "inline"),
+	}, {
+		name: "invalid compatibility",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        `{}`,
+			Compatibility: SchemaCompatibility("Transitive"),
+		},
+		expected: validation.ErrInvalidValue(SchemaCompatibility("Transitive"), "compatibility"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamSchema(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	userJSON := `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`
+	userAvro := `{"type": "record", "name": "user", "fields": [{"name": "name", "type": "string"}]}`
+
+	for _, c := range []struct {
+		name          string
+		schemaType    SchemaType
+		nextType      SchemaType
+		compatibility SchemaCompatibility
+		previous      string
+		next          string
+		compatible    bool
+	}{{
+		name:          "unchanged",
+		schemaType:    SchemaTypeJSONSchema,
+		compatibility: SchemaCompatibilityFull,
+		previous:      userJSON,
+		next:          userJSON,
+		compatible:    true,
+	}, {
+		name:          "JSON schema optional property added",
+		schemaType:    SchemaTypeJSONSchema,
+		compatibility: SchemaCompatibilityFull,
+		previous:      userJSON,
+		next:          `{"type": "object", "properties": {"name": {"type": "string"}, "email": {"type": "string"}}, "required": ["name"]}`,
+		compatible:    true,
+	}, {
+		name:          "JSON schema required property added",
+		schemaType:    SchemaTypeJSONSchema,
+		compatibility: SchemaCompatibilityBackward,
+		previous:      userJSON,
+		next:          `{"type": "object", "properties": {"name": {"type": "string"}, "email": {"type": "string"}}, "required": ["name", "email"]}`,
+		compatible:    false,
+	}, {
+		name:          "JSON schema required property added forward",
+		schemaType:    SchemaTypeJSONSchema,
+		compatibility: SchemaCompatibilityForward,
+		previous:      userJSON,
+		next:          `{"type": "object", "properties": {"name": {"type": "string"}, "email": {"type": "string"}}, "required": ["name", "email"]}`,
+		compatible:    true,
+	}, {
+		name:          "JSON schema property type changed",
+		schemaType:    SchemaTypeJSONSchema,
+		compatibility: SchemaCompatibilityBackward,
+		previous:      userJSON,
+		next:          `{"type": "object", "properties": {"name": {"type": "number"}}, "required": ["name"]}`,
+		compatible:    false,
+	}, {
+		name:          "JSON schema property type changed without checks",
+		schemaType:    SchemaTypeJSONSchema,
+		compatibility: SchemaCompatibilityNone,
+		previous:      userJSON,
+		next:          `{"type": "object", "properties": {"name": {"type": "number"}}, "required": ["name"]}`,
+		compatible:    true,
+	}, {
+		name:          "Avro field with default added",
+		schemaType:    SchemaTypeAvro,
+		compatibility: SchemaCompatibilityFull,
+		previous:      userAvro,
+		next:          `{"type": "record", "name": "user", "fields": [{"name": "name", "type": "string"}, {"name": "email", "type": "string", "default": ""}]}`,
+		compatible:    true,
+	}, {
+		name:          "Avro field without default added",
+		schemaType:    SchemaTypeAvro,
+		compatibility: SchemaCompatibilityBackward,
+		previous:      userAvro,
+		next:          `{"type": "record", "name": "user", "fields": [{"name": "name", "type": "string"}, {"name": "email", "type": "string"}]}`,
+		compatible:    false,
+	}, {
+		name:          "Avro field without default removed",
+		schemaType:    SchemaTypeAvro,
+		compatibility: SchemaCompatibilityForward,
+		previous:      userAvro,
+		next:          `{"type": "record", "name": "user", "fields": []}`,
+		compatible:    false,
+	}, {
+		name:          "Avro primitive changed",
+		schemaType:    SchemaTypeAvro,
+		compatibility: SchemaCompatibilityBackward,
+		previous:      `"string"`,
+		next:          `"long"`,
+		compatible:    false,
+	}, {
+		name:          "type changed",
+		schemaType:    SchemaTypeJSONSchema,
+		nextType:      SchemaTypeAvro,
+		compatibility: SchemaCompatibilityBackward,
+		previous:      userJSON,
+		next:          userAvro,
+		compatible:    false,
+	}, {
+		name:          "type changed without checks",
+		schemaType:    SchemaTypeJSONSchema,
+		nextType:      SchemaTypeAvro,
+		compatibility: SchemaCompatibilityNone,
+		previous:      userJSON,
+		next:          userAvro,
+		compatible:    true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			nextType := c.nextType
+			if nextType == "" {
+				nextType = c.schemaType
+			}
+			err := CheckSchemaCompatibility(c.compatibility, c.schemaType, c.previous, nextType, c.next)
+			if compatible := err == nil; compatible != c.compatible {
+				t.Errorf("CheckSchemaCompatibility(%s) expected compatible %v, got error %v", c.name, c.compatible, err)
+			}
+		})
+	}
+}
+
+func TestValidateStreamSchemaUpdate(t *testing.T) {
+	userJSON := `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`
+	userNumberJSON := `{"type": "object", "properties": {"name": {"type": "number"}}, "required": ["name"]}`
+
+	for _, c := range []struct {
+		name     string
+		target   *StreamSchema
+		old      *StreamSchema
+		expected bool
+	}{{
+		name: "compatible change",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        `{"type": "object", "properties": {"name": {"type": "string"}, "email": {"type": "string"}}, "required": ["name"]}`,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		expected: true,
+	}, {
+		name: "incompatible change",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userNumberJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		expected: false,
+	}, {
+		name: "incompatible change while dropping compatibility",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userNumberJSON,
+			Compatibility: SchemaCompatibilityNone,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		expected: false,
+	}, {
+		name: "compatibility dropped without a schema change",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityNone,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		expected: true,
+	}, {
+		name: "incompatible change without checks",
+		target: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userNumberJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityNone,
+		},
+		expected: true,
+	}, {
+		name: "type changed",
+		target: &StreamSchema{
+			Type:          SchemaTypeAvro,
+			Inline:        `"string"`,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		expected: false,
+	}, {
+		name: "type changed without checks",
+		target: &StreamSchema{
+			Type:          SchemaTypeAvro,
+			Inline:        `"string"`,
+			Compatibility: SchemaCompatibilityBackward,
+		},
+		old: &StreamSchema{
+			Type:          SchemaTypeJSONSchema,
+			Inline:        userJSON,
+			Compatibility: SchemaCompatibilityNone,
+		},
+		expected: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			target := &Stream{Spec: StreamSpec{Schema: c.target}}
+			old := &Stream{Spec: StreamSpec{Schema: c.old}}
+			errs := target.validateSchemaUpdate(old)
+			if allowed := len(errs) == 0; allowed != c.expected {
+				t.Errorf("validateSchemaUpdate(%s) expected allowed %v, got errors %v", c.name, c.expected, errs)
+			}
+		})
+	}
+}
+
+func TestValidateStreamAccess(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamAccess
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamAccess{},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid",
+		target: &StreamAccess{
+			Publishers: []StreamAccessor{{Processor: "auditor"}},
+			Consumers: []StreamAccessor{{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "audit"},
+				},
+			}},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "processor and selector",
+		target: &StreamAccess{
+			Consumers: []StreamAccessor{{
+				Processor: "auditor",
+				Selector:  &metav1.LabelSelector{},
+			}},
+		},
+		expected: validation.ErrMultipleOneOf("processor", "selector").ViaFieldIndex("consumers", 0),
+	}, {
+		name: "invalid selector",
+		target: &StreamAccess{
+			Publishers: []StreamAccessor{{
+				Selector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "team",
+						Operator: metav1.LabelSelectorOperator("Near"),
+					}},
+				},
+			}},
+		},
+		expected: validation.ErrInvalidValue(&metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{
+				Key:      "team",
+				Operator: metav1.LabelSelectorOperator("Near"),
+			}},
+		}, "selector").ViaFieldIndex("publishers", 0),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamAccess(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestStreamAllowsProcessor(t *testing.T) {
+	auditor := &Processor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "auditor",
+			Labels: map[string]string{"team": "audit"},
+		},
+	}
+	for _, c := range []struct {
+		name      string
+		access    *StreamAccess
+		publisher bool
+		consumer  bool
+	}{{
+		name:      "unrestricted",
+		publisher: true,
+		consumer:  true,
+	}, {
+		name: "publisher by name",
+		access: &StreamAccess{
+			Publishers: []StreamAccessor{{Processor: "auditor"}},
+		},
+		publisher: true,
+		consumer:  true,
+	}, {
+		name: "other publisher by name",
+		access: &StreamAccess{
+			Publishers: []StreamAccessor{{Processor: "reporter"}},
+		},
+		publisher: false,
+		consumer:  true,
+	}, {
+		name: "consumer by selector",
+		access: &StreamAccess{
+			Publishers: []StreamAccessor{{Processor: "reporter"}},
+			Consumers: []StreamAccessor{{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "audit"},
+				},
+			}},
+		},
+		publisher: false,
+		consumer:  true,
+	}, {
+		name: "other consumer by selector",
+		access: &StreamAccess{
+			Consumers: []StreamAccessor{{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "billing"},
+				},
+			}},
+		},
+		publisher: true,
+		consumer:  false,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			stream := &Stream{Spec: StreamSpec{Access: c.access}}
+			if actual := stream.AllowsPublisher(auditor); actual != c.publisher {
+				t.Errorf("AllowsPublisher(%s) expected %v, got %v", c.name, c.publisher, actual)
+			}
+			if actual := stream.AllowsConsumer(auditor); actual != c.consumer {
+				t.Errorf("AllowsConsumer(%s) expected %v, got %v", c.name, c.consumer, actual)
+			}
+		})
+	}
+}
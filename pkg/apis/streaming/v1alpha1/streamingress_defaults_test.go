@@ -0,0 +1,81 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStreamIngressDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *StreamIngress
+		want *StreamIngress
+	}{{
+		name: "empty",
+		in:   &StreamIngress{},
+		want: &StreamIngress{
+			Spec: StreamIngressSpec{
+				IngressPolicy: IngressPolicyClusterLocal,
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestStreamIngressSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *StreamIngressSpec
+		want *StreamIngressSpec
+	}{{
+		name: "ingress policy is defaulted",
+		in:   &StreamIngressSpec{},
+		want: &StreamIngressSpec{
+			IngressPolicy: IngressPolicyClusterLocal,
+		},
+	}, {
+		name: "ingress policy is not overwritten",
+		in: &StreamIngressSpec{
+			IngressPolicy: IngressPolicyExternal,
+		},
+		want: &StreamIngressSpec{
+			IngressPolicy: IngressPolicyExternal,
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
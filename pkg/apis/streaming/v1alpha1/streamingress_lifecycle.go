@@ -0,0 +1,114 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+const (
+	StreamIngressConditionReady                              = apis.ConditionReady
+	StreamIngressConditionStreamReady     apis.ConditionType = "StreamReady"
+	StreamIngressConditionDeploymentReady apis.ConditionType = "DeploymentReady"
+	StreamIngressConditionServiceReady    apis.ConditionType = "ServiceReady"
+	StreamIngressConditionIngressReady    apis.ConditionType = "IngressReady"
+)
+
+var streamIngressCondSet = apis.NewLivingConditionSet(
+	StreamIngressConditionStreamReady,
+	StreamIngressConditionDeploymentReady,
+	StreamIngressConditionServiceReady,
+)
+
+func (sis *StreamIngressStatus) GetObservedGeneration() int64 {
+	return sis.ObservedGeneration
+}
+
+func (sis *StreamIngressStatus) IsReady() bool {
+	return streamIngressCondSet.Manage(sis).IsHappy()
+}
+
+func (*StreamIngressStatus) GetReadyConditionType() apis.ConditionType {
+	return StreamIngressConditionReady
+}
+
+func (sis *StreamIngressStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return streamIngressCondSet.Manage(sis).GetCondition(t)
+}
+
+func (sis *StreamIngressStatus) InitializeConditions() {
+	streamIngressCondSet.Manage(sis).InitializeConditions()
+}
+
+func (sis *StreamIngressStatus) MarkStreamReady() {
+	streamIngressCondSet.Manage(sis).MarkTrue(StreamIngressConditionStreamReady)
+}
+
+func (sis *StreamIngressStatus) MarkStreamNotReady(message string) {
+	streamIngressCondSet.Manage(sis).MarkFalse(StreamIngressConditionStreamReady, "StreamNotReady", message)
+}
+
+func (sis *StreamIngressStatus) PropagateDeploymentStatus(ds *appsv1.DeploymentStatus) {
+	var available, progressing *appsv1.DeploymentCondition
+	for i := range ds.Conditions {
+		switch ds.Conditions[i].Type {
+		case appsv1.DeploymentAvailable:
+			available = &ds.Conditions[i]
+		case appsv1.DeploymentProgressing:
+			progressing = &ds.Conditions[i]
+		}
+	}
+	if available == nil || progressing == nil {
+		return
+	}
+	if progressing.Status == corev1.ConditionTrue && available.Status == corev1.ConditionFalse {
+		// DeploymentAvailable is False while progressing, avoid reporting StreamIngressConditionReady as False
+		streamIngressCondSet.Manage(sis).MarkUnknown(StreamIngressConditionDeploymentReady, progressing.Reason, progressing.Message)
+		return
+	}
+	switch {
+	case available.Status == corev1.ConditionUnknown:
+		streamIngressCondSet.Manage(sis).MarkUnknown(StreamIngressConditionDeploymentReady, available.Reason, available.Message)
+	case available.Status == corev1.ConditionTrue:
+		streamIngressCondSet.Manage(sis).MarkTrue(StreamIngressConditionDeploymentReady)
+	case available.Status == corev1.ConditionFalse:
+		streamIngressCondSet.Manage(sis).MarkFalse(StreamIngressConditionDeploymentReady, available.Reason, available.Message)
+	}
+}
+
+func (sis *StreamIngressStatus) PropagateServiceStatus(ss *corev1.ServiceStatus) {
+	// services don't have meaningful status
+	streamIngressCondSet.Manage(sis).MarkTrue(StreamIngressConditionServiceReady)
+}
+
+func (sis *StreamIngressStatus) MarkIngressNotRequired() {
+	streamIngressCondSet.Manage(sis).MarkFalse(StreamIngressConditionIngressReady, "IngressNotRequired", "Ingress resource is not required.")
+}
+
+// PropagateIngressStatus update StreamIngressConditionIngressReady condition
+// in StreamIngressStatus according to IngressStatus.
+func (sis *StreamIngressStatus) PropagateIngressStatus(is *networkingv1beta1.IngressStatus) {
+	if len(is.LoadBalancer.Ingress) == 0 {
+		streamIngressCondSet.Manage(sis).MarkUnknown(StreamIngressConditionIngressReady, "IngressNotConfigured", "Ingress has not yet been reconciled.")
+	} else {
+		streamIngressCondSet.Manage(sis).MarkTrue(StreamIngressConditionIngressReady)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+	"github.com/projectriff/system/pkg/refs"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	StreamIngressLabelKey = GroupVersion.Group + "/stream-ingress"
+)
+
+var (
+	_ apis.Resource = (*StreamIngress)(nil)
+)
+
+// StreamIngressSpec defines the desired state of StreamIngress
+type StreamIngressSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Stream name, from this namespace, that received events are published to
+	Stream string `json:"stream"`
+
+	// IngressPolicy defines whether the endpoint should be reachable from
+	// outside the cluster
+	IngressPolicy IngressPolicy `json:"ingressPolicy,omitempty"`
+}
+
+// IngressPolicy describes whether the endpoint should be exposed via
+// ingress. Only one of the following ingress policies may be specified.
+// If none of the following policies is specified, the default one is
+// IngressPolicyClusterLocal.
+type IngressPolicy string
+
+const (
+	IngressPolicyClusterLocal IngressPolicy = "ClusterLocal"
+	IngressPolicyExternal     IngressPolicy = "External"
+)
+
+// StreamIngressStatus defines the observed state of StreamIngress
+type StreamIngressStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status `json:",inline"`
+
+	DeploymentRef *refs.TypedLocalObjectReference `json:"deploymentRef,omitempty"`
+	ServiceRef    *refs.TypedLocalObjectReference `json:"serviceRef,omitempty"`
+	IngressRef    *refs.TypedLocalObjectReference `json:"ingressRef,omitempty"`
+
+	// Address to publish events internally
+	Address *apis.Addressable `json:"address,omitempty"`
+
+	// URL to publish events publicly
+	URL string `json:"url,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Stream",type=string,JSONPath=`.spec.stream`
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.url`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// StreamIngress is the Schema for the streamingresses API
+type StreamIngress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StreamIngressSpec   `json:"spec,omitempty"`
+	Status StreamIngressStatus `json:"status,omitempty"`
+}
+
+func (*StreamIngress) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("StreamIngress")
+}
+
+func (si *StreamIngress) GetStatus() apis.ResourceStatus {
+	return &si.Status
+}
+
+// +kubebuilder:object:root=true
+
+// StreamIngressList contains a list of StreamIngress
+type StreamIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StreamIngress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StreamIngress{}, &StreamIngressList{})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateStreamIngress(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamIngress
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamIngress{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &StreamIngress{
+			Spec: StreamIngressSpec{
+				Stream:        "my-stream",
+				IngressPolicy: IngressPolicyClusterLocal,
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamIngress(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateStreamIngressSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamIngressSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamIngressSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid",
+		target: &StreamIngressSpec{
+			Stream: "my-stream",
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid, external",
+		target: &StreamIngressSpec{
+			Stream:        "my-stream",
+			IngressPolicy: IngressPolicyExternal,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires stream",
+		target: &StreamIngressSpec{
+			IngressPolicy: IngressPolicyClusterLocal,
+		},
+		expected: validation.ErrMissingField("stream"),
+	}, {
+		name: "invalid ingress policy",
+		target: &StreamIngressSpec{
+			Stream:        "my-stream",
+			IngressPolicy: "bogus",
+		},
+		expected: validation.ErrInvalidValue(IngressPolicy("bogus"), "ingressPolicy"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamIngressSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStreamSubscriptionDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *StreamSubscription
+		want *StreamSubscription
+	}{{
+		name: "empty",
+		in:   &StreamSubscription{},
+		want: &StreamSubscription{
+			Spec: StreamSubscriptionSpec{
+				Delivery: &DeliverySpec{
+					Retry:   int32Ptr(3),
+					Timeout: "30s",
+				},
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestStreamSubscriptionSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *StreamSubscriptionSpec
+		want *StreamSubscriptionSpec
+	}{{
+		name: "delivery is defaulted",
+		in:   &StreamSubscriptionSpec{},
+		want: &StreamSubscriptionSpec{
+			Delivery: &DeliverySpec{
+				Retry:   int32Ptr(3),
+				Timeout: "30s",
+			},
+		},
+	}, {
+		name: "delivery is not overwritten",
+		in: &StreamSubscriptionSpec{
+			Delivery: &DeliverySpec{
+				Retry:            int32Ptr(0),
+				Timeout:          "1m",
+				DeadLetterStream: "dlq",
+			},
+		},
+		want: &StreamSubscriptionSpec{
+			Delivery: &DeliverySpec{
+				Retry:            int32Ptr(0),
+				Timeout:          "1m",
+				DeadLetterStream: "dlq",
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
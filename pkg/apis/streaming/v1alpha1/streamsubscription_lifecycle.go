@@ -0,0 +1,145 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+const (
+	StreamSubscriptionConditionReady                              = apis.ConditionReady
+	StreamSubscriptionConditionStreamsReady    apis.ConditionType = "StreamsReady"
+	StreamSubscriptionConditionSubscriberReady apis.ConditionType = "SubscriberReady"
+	StreamSubscriptionConditionDeploymentReady apis.ConditionType = "DeploymentReady"
+	// StreamSubscriptionConditionDeliveryFailing is informational, failing
+	// deliveries do not make the subscription not ready
+	StreamSubscriptionConditionDeliveryFailing apis.ConditionType = "DeliveryFailing"
+)
+
+var streamSubscriptionCondSet = apis.NewLivingConditionSet(
+	StreamSubscriptionConditionStreamsReady,
+	StreamSubscriptionConditionSubscriberReady,
+	StreamSubscriptionConditionDeploymentReady,
+)
+
+func (sss *StreamSubscriptionStatus) GetObservedGeneration() int64 {
+	return sss.ObservedGeneration
+}
+
+func (sss *StreamSubscriptionStatus) IsReady() bool {
+	return streamSubscriptionCondSet.Manage(sss).IsHappy()
+}
+
+func (*StreamSubscriptionStatus) GetReadyConditionType() apis.ConditionType {
+	return StreamSubscriptionConditionReady
+}
+
+func (sss *StreamSubscriptionStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return streamSubscriptionCondSet.Manage(sss).GetCondition(t)
+}
+
+func (sss *StreamSubscriptionStatus) InitializeConditions() {
+	streamSubscriptionCondSet.Manage(sss).InitializeConditions()
+}
+
+func (sss *StreamSubscriptionStatus) MarkStreamsReady() {
+	streamSubscriptionCondSet.Manage(sss).MarkTrue(StreamSubscriptionConditionStreamsReady)
+}
+
+func (sss *StreamSubscriptionStatus) MarkStreamsNotReady(messageFormat string, messageA ...interface{}) {
+	streamSubscriptionCondSet.Manage(sss).MarkFalse(StreamSubscriptionConditionStreamsReady, "StreamNotReady", messageFormat, messageA...)
+}
+
+func (sss *StreamSubscriptionStatus) MarkSubscriberReady() {
+	streamSubscriptionCondSet.Manage(sss).MarkTrue(StreamSubscriptionConditionSubscriberReady)
+}
+
+func (sss *StreamSubscriptionStatus) MarkSubscriberNotFound(messageFormat string, messageA ...interface{}) {
+	streamSubscriptionCondSet.Manage(sss).MarkFalse(StreamSubscriptionConditionSubscriberReady, "SubscriberNotFound", messageFormat, messageA...)
+}
+
+func (sss *StreamSubscriptionStatus) MarkSubscriberNotAddressable(messageFormat string, messageA ...interface{}) {
+	streamSubscriptionCondSet.Manage(sss).MarkFalse(StreamSubscriptionConditionSubscriberReady, "SubscriberNotAddressable", messageFormat, messageA...)
+}
+
+// MarkDeliveryFailing reports recent delivery failures, the subscription
+// remains ready.
+func (sss *StreamSubscriptionStatus) MarkDeliveryFailing(messageFormat string, messageA ...interface{}) {
+	manager := streamSubscriptionCondSet.Manage(sss)
+	manager.MarkTrue(StreamSubscriptionConditionDeliveryFailing)
+	cond := *manager.GetCondition(StreamSubscriptionConditionDeliveryFailing)
+	cond.Reason = "DeliveryFailed"
+	cond.Message = fmt.Sprintf(messageFormat, messageA...)
+	manager.SetCondition(cond)
+}
+
+func (sss *StreamSubscriptionStatus) MarkDeliverySucceeding() {
+	streamSubscriptionCondSet.Manage(sss).MarkFalse(StreamSubscriptionConditionDeliveryFailing, "DeliverySucceeding", "")
+}
+
+func (sss *StreamSubscriptionStatus) MarkDeliveryUnknown(reason, messageFormat string, messageA ...interface{}) {
+	streamSubscriptionCondSet.Manage(sss).MarkUnknown(StreamSubscriptionConditionDeliveryFailing, reason, messageFormat, messageA...)
+}
+
+// PropagateDeliveryStatus reflects the messages handled by the dispatcher on
+// the subscription, delivery is failing while the most recent failure is
+// within the window.
+func (sss *StreamSubscriptionStatus) PropagateDeliveryStatus(ds *DeliveryStatus, now time.Time, window time.Duration) {
+	sss.Delivery = ds
+	if ds.LastFailureTime != nil && now.Sub(ds.LastFailureTime.Time) < window {
+		sss.MarkDeliveryFailing("%d of %d messages failed delivery, most recently: %s", ds.Failed, ds.Delivered+ds.Failed, ds.LastFailureMessage)
+		return
+	}
+	sss.MarkDeliverySucceeding()
+}
+
+// PropagateDeploymentStatus reflects the availability of the delivery
+// Deployment on the subscription. Failures delivering individual messages are
+// reported by PropagateDeliveryStatus.
+func (sss *StreamSubscriptionStatus) PropagateDeploymentStatus(ds *appsv1.DeploymentStatus) {
+	var available, progressing *appsv1.DeploymentCondition
+	for i := range ds.Conditions {
+		switch ds.Conditions[i].Type {
+		case appsv1.DeploymentAvailable:
+			available = &ds.Conditions[i]
+		case appsv1.DeploymentProgressing:
+			progressing = &ds.Conditions[i]
+		}
+	}
+	if available == nil || progressing == nil {
+		return
+	}
+	if progressing.Status == corev1.ConditionTrue && available.Status == corev1.ConditionFalse {
+		// DeploymentAvailable is False while progressing, avoid reporting StreamSubscriptionConditionReady as False
+		streamSubscriptionCondSet.Manage(sss).MarkUnknown(StreamSubscriptionConditionDeploymentReady, progressing.Reason, progressing.Message)
+		return
+	}
+	switch {
+	case available.Status == corev1.ConditionUnknown:
+		streamSubscriptionCondSet.Manage(sss).MarkUnknown(StreamSubscriptionConditionDeploymentReady, available.Reason, available.Message)
+	case available.Status == corev1.ConditionTrue:
+		streamSubscriptionCondSet.Manage(sss).MarkTrue(StreamSubscriptionConditionDeploymentReady)
+	case available.Status == corev1.ConditionFalse:
+		streamSubscriptionCondSet.Manage(sss).MarkFalse(StreamSubscriptionConditionDeploymentReady, available.Reason, available.Message)
+	}
+}
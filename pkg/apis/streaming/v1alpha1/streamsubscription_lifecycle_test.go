@@ -0,0 +1,110 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStreamSubscriptionStatusPropagateDeliveryStatus(t *testing.T) {
+	now := time.Now()
+	recent := metav1.NewTime(now.Add(-1 * time.Minute))
+	old := metav1.NewTime(now.Add(-1 * time.Hour))
+
+	for _, c := range []struct {
+		name            string
+		delivery        *DeliveryStatus
+		expectedStatus  corev1.ConditionStatus
+		expectedMessage string
+	}{{
+		name:           "no failures",
+		delivery:       &DeliveryStatus{Delivered: 10},
+		expectedStatus: corev1.ConditionFalse,
+	}, {
+		name: "recent failure",
+		delivery: &DeliveryStatus{
+			Delivered:          8,
+			Failed:             2,
+			DeadLettered:       2,
+			LastFailureTime:    &recent,
+			LastFailureMessage: "subscriber responded 500%",
+		},
+		expectedStatus:  corev1.ConditionTrue,
+		expectedMessage: "2 of 10 messages failed delivery, most recently: subscriber responded 500%",
+	}, {
+		name: "failure outside the window",
+		delivery: &DeliveryStatus{
+			Delivered:          8,
+			Failed:             2,
+			LastFailureTime:    &old,
+			LastFailureMessage: "subscriber responded 500",
+		},
+		expectedStatus: corev1.ConditionFalse,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			sss := &StreamSubscriptionStatus{}
+			sss.InitializeConditions()
+			sss.MarkStreamsReady()
+			sss.MarkSubscriberReady()
+			sss.PropagateDeploymentStatus(&appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+				},
+			})
+
+			sss.PropagateDeliveryStatus(c.delivery, now, 5*time.Minute)
+
+			if sss.Delivery != c.delivery {
+				t.Errorf("PropagateDeliveryStatus() expected delivery status to be recorded")
+			}
+			cond := sss.GetCondition(StreamSubscriptionConditionDeliveryFailing)
+			if cond.Status != c.expectedStatus {
+				t.Errorf("%s expected status %s, got %s", StreamSubscriptionConditionDeliveryFailing, c.expectedStatus, cond.Status)
+			}
+			if cond.Message != c.expectedMessage {
+				t.Errorf("%s expected message %q, got %q", StreamSubscriptionConditionDeliveryFailing, c.expectedMessage, cond.Message)
+			}
+			if !sss.IsReady() {
+				t.Errorf("IsReady() expected delivery failures to be informational")
+			}
+		})
+	}
+}
+
+func TestStreamSubscriptionStatusMarkDeliveryUnknown(t *testing.T) {
+	sss := &StreamSubscriptionStatus{}
+	sss.InitializeConditions()
+	sss.MarkStreamsNotReady("stream %s is not ready: %s", "my-stream", "100% not provisioned")
+
+	sss.MarkDeliveryUnknown("DispatcherNotRunning", "no dispatcher is running")
+
+	if cond := sss.GetCondition(StreamSubscriptionConditionStreamsReady); cond.Message != "stream my-stream is not ready: 100% not provisioned" {
+		t.Errorf("%s unexpected message %q", StreamSubscriptionConditionStreamsReady, cond.Message)
+	}
+	if cond := sss.GetCondition(StreamSubscriptionConditionDeliveryFailing); cond.Status != corev1.ConditionUnknown || cond.Reason != "DispatcherNotRunning" {
+		t.Errorf("%s expected Unknown with reason DispatcherNotRunning, got %s with reason %q", StreamSubscriptionConditionDeliveryFailing, cond.Status, cond.Reason)
+	}
+	if cond := sss.GetCondition(StreamSubscriptionConditionReady); cond.Reason != "StreamNotReady" {
+		t.Errorf("%s expected reason StreamNotReady, got %q", StreamSubscriptionConditionReady, cond.Reason)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+	"github.com/projectriff/system/pkg/refs"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	StreamSubscriptionLabelKey = GroupVersion.Group + "/stream-subscription"
+)
+
+var (
+	_ apis.Resource = (*StreamSubscription)(nil)
+)
+
+// StreamSubscriptionSpec defines the desired state of StreamSubscription
+type StreamSubscriptionSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Stream name, from this namespace, that messages are delivered from
+	Stream string `json:"stream"`
+
+	// Subscriber references a resource, from this namespace, that messages
+	// are delivered to. The resource must expose its address at
+	// `status.address.url`. Kinds other than riff deployers and stream
+	// ingresses must be readable by the controller, see the
+	// `subscriber-role` ClusterRole.
+	Subscriber *SubscriberReference `json:"subscriber"`
+
+	// Delivery configures how messages are delivered to the subscriber
+	// +optional
+	Delivery *DeliverySpec `json:"delivery,omitempty"`
+}
+
+type SubscriberReference struct {
+	// APIVersion of the referenced resource
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the referenced resource
+	Kind string `json:"kind"`
+
+	// Name of the referenced resource
+	Name string `json:"name"`
+}
+
+type DeliverySpec struct {
+	// Retry is the number of times the delivery of a message is retried
+	// before the message is given up on
+	// +optional
+	Retry *int32 `json:"retry,omitempty"`
+
+	// Timeout of a single delivery attempt, expressed as a duration (e.g. 30s)
+	// +optional
+	Timeout string `json:"timeout,omitempty"`
+
+	// DeadLetterStream name, from this namespace, that messages are
+	// published to once all delivery attempts have failed
+	// +optional
+	DeadLetterStream string `json:"deadLetterStream,omitempty"`
+}
+
+// StreamSubscriptionStatus defines the observed state of StreamSubscription
+type StreamSubscriptionStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status `json:",inline"`
+
+	DeploymentRef *refs.TypedLocalObjectReference `json:"deploymentRef,omitempty"`
+
+	// SubscriberURL resolved from the subscriber's address
+	SubscriberURL string `json:"subscriberURL,omitempty"`
+
+	// Delivery reports the messages handled by the dispatcher, while the
+	// dispatcher is running and reports them
+	Delivery *DeliveryStatus `json:"delivery,omitempty"`
+}
+
+// DeliveryStatus counts the messages handled by the running dispatcher pods,
+// counts restart with the pods.
+type DeliveryStatus struct {
+	// Delivered messages, accepted by the subscriber
+	Delivered int64 `json:"delivered"`
+
+	// Failed messages, all delivery attempts were rejected by the subscriber
+	Failed int64 `json:"failed"`
+
+	// DeadLettered messages, failed messages published to the dead letter
+	// stream
+	DeadLettered int64 `json:"deadLettered"`
+
+	// LastFailureTime is when the most recent delivery failed
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// LastFailureMessage describes the most recent delivery failure
+	LastFailureMessage string `json:"lastFailureMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Stream",type=string,JSONPath=`.spec.stream`
+// +kubebuilder:printcolumn:name="Subscriber",type=string,JSONPath=`.status.subscriberURL`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// StreamSubscription is the Schema for the streamsubscriptions API
+type StreamSubscription struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StreamSubscriptionSpec   `json:"spec,omitempty"`
+	Status StreamSubscriptionStatus `json:"status,omitempty"`
+}
+
+func (*StreamSubscription) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("StreamSubscription")
+}
+
+func (ss *StreamSubscription) GetStatus() apis.ResourceStatus {
+	return &ss.Status
+}
+
+// +kubebuilder:object:root=true
+
+// StreamSubscriptionList contains a list of StreamSubscription
+type StreamSubscriptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StreamSubscription `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StreamSubscription{}, &StreamSubscriptionList{})
+}
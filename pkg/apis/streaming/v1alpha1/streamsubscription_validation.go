@@ -0,0 +1,116 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-streamsubscription,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=streamsubscriptions,verbs=create;update,versions=v1alpha1,name=streamsubscriptions.streaming.projectriff.io
+
+var (
+	_ webhook.Validator         = &StreamSubscription{}
+	_ validation.FieldValidator = &StreamSubscription{}
+)
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *StreamSubscription) ValidateCreate() error {
+	return r.Validate().ToAggregate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *StreamSubscription) ValidateUpdate(old runtime.Object) error {
+	// TODO check for immutable fields
+	return r.Validate().ToAggregate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *StreamSubscription) ValidateDelete() error {
+	return nil
+}
+
+func (r *StreamSubscription) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	errs = errs.Also(r.Spec.Validate().ViaField("spec"))
+
+	return errs
+}
+
+func (s *StreamSubscriptionSpec) Validate() validation.FieldErrors {
+	if equality.Semantic.DeepEqual(s, &StreamSubscriptionSpec{}) {
+		return validation.ErrMissingField(validation.CurrentField)
+	}
+
+	errs := validation.FieldErrors{}
+
+	if s.Stream == "" {
+		errs = errs.Also(validation.ErrMissingField("stream"))
+	}
+
+	if s.Subscriber == nil {
+		errs = errs.Also(validation.ErrMissingField("subscriber"))
+	} else {
+		errs = errs.Also(s.Subscriber.Validate().ViaField("subscriber"))
+	}
+
+	if s.Delivery != nil {
+		errs = errs.Also(s.Delivery.Validate().ViaField("delivery"))
+		if s.Delivery.DeadLetterStream != "" && s.Delivery.DeadLetterStream == s.Stream {
+			errs = errs.Also(validation.ErrInvalidValue(s.Delivery.DeadLetterStream, "delivery.deadLetterStream"))
+		}
+	}
+
+	return errs
+}
+
+func (r *SubscriberReference) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if r.APIVersion == "" {
+		errs = errs.Also(validation.ErrMissingField("apiVersion"))
+	}
+	if r.Kind == "" {
+		errs = errs.Also(validation.ErrMissingField("kind"))
+	}
+	if r.Name == "" {
+		errs = errs.Also(validation.ErrMissingField("name"))
+	}
+
+	return errs
+}
+
+func (s *DeliverySpec) Validate() validation.FieldErrors {
+	errs := validation.FieldErrors{}
+
+	if s.Retry != nil && *s.Retry < 0 {
+		errs = errs.Also(validation.ErrInvalidValue(*s.Retry, "retry"))
+	}
+	if s.Timeout != "" {
+		if timeout, err := time.ParseDuration(s.Timeout); err != nil || timeout <= 0 {
+			errs = errs.Also(validation.ErrInvalidValue(s.Timeout, "timeout"))
+		}
+	}
+
+	return errs
+}
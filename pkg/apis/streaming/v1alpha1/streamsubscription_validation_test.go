@@ -0,0 +1,154 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateStreamSubscription(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamSubscription
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamSubscription{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &StreamSubscription{
+			Spec: StreamSubscriptionSpec{
+				Stream: "my-stream",
+				Subscriber: &SubscriberReference{
+					APIVersion: "core.projectriff.io/v1alpha1",
+					Kind:       "Deployer",
+					Name:       "my-deployer",
+				},
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamSubscription(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateStreamSubscriptionSpec(t *testing.T) {
+	subscriber := &SubscriberReference{
+		APIVersion: "core.projectriff.io/v1alpha1",
+		Kind:       "Deployer",
+		Name:       "my-deployer",
+	}
+
+	for _, c := range []struct {
+		name     string
+		target   *StreamSubscriptionSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamSubscriptionSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid",
+		target: &StreamSubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: subscriber,
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "valid, with delivery",
+		target: &StreamSubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: subscriber,
+			Delivery: &DeliverySpec{
+				Retry:            int32Ptr(5),
+				Timeout:          "10s",
+				DeadLetterStream: "my-dlq",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires stream",
+		target: &StreamSubscriptionSpec{
+			Subscriber: subscriber,
+		},
+		expected: validation.ErrMissingField("stream"),
+	}, {
+		name: "requires subscriber",
+		target: &StreamSubscriptionSpec{
+			Stream: "my-stream",
+		},
+		expected: validation.ErrMissingField("subscriber"),
+	}, {
+		name: "requires subscriber fields",
+		target: &StreamSubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: &SubscriberReference{},
+		},
+		expected: validation.FieldErrors{}.Also(
+			validation.ErrMissingField("subscriber.apiVersion"),
+			validation.ErrMissingField("subscriber.kind"),
+			validation.ErrMissingField("subscriber.name"),
+		),
+	}, {
+		name: "invalid retry",
+		target: &StreamSubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: subscriber,
+			Delivery: &DeliverySpec{
+				Retry: int32Ptr(-1),
+			},
+		},
+		expected: validation.ErrInvalidValue(int32(-1), "delivery.retry"),
+	}, {
+		name: "invalid timeout",
+		target: &StreamSubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: subscriber,
+			Delivery: &DeliverySpec{
+				Timeout: "soon",
+			},
+		},
+		expected: validation.ErrInvalidValue("soon", "delivery.timeout"),
+	}, {
+		name: "dead letter stream must differ from stream",
+		target: &StreamSubscriptionSpec{
+			Stream:     "my-stream",
+			Subscriber: subscriber,
+			Delivery: &DeliverySpec{
+				DeadLetterStream: "my-stream",
+			},
+		},
+		expected: validation.ErrInvalidValue("my-stream", "delivery.deadLetterStream"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamSubscriptionSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStreamTapDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *StreamTap
+		want *StreamTap
+	}{{
+		name: "empty",
+		in:   &StreamTap{},
+		want: &StreamTap{
+			Spec: StreamTapSpec{
+				Buffer: int32Ptr(100),
+				TTL:    "1h",
+			},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestStreamTapSpecDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *StreamTapSpec
+		want *StreamTapSpec
+	}{{
+		name: "buffer and ttl are defaulted",
+		in:   &StreamTapSpec{},
+		want: &StreamTapSpec{
+			Buffer: int32Ptr(100),
+			TTL:    "1h",
+		},
+	}, {
+		name: "buffer and ttl are not overwritten",
+		in: &StreamTapSpec{
+			Buffer: int32Ptr(10),
+			TTL:    "5m",
+		},
+		want: &StreamTapSpec{
+			Buffer: int32Ptr(10),
+			TTL:    "5m",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.in
+			got.Default()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Default (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
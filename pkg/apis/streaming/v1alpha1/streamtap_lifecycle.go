@@ -0,0 +1,89 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/projectriff/system/pkg/apis"
+)
+
+const (
+	StreamTapConditionReady                           = apis.ConditionReady
+	StreamTapConditionStreamReady  apis.ConditionType = "StreamReady"
+	StreamTapConditionPodReady     apis.ConditionType = "PodReady"
+	StreamTapConditionServiceReady apis.ConditionType = "ServiceReady"
+)
+
+var streamTapCondSet = apis.NewLivingConditionSet(
+	StreamTapConditionStreamReady,
+	StreamTapConditionPodReady,
+	StreamTapConditionServiceReady,
+)
+
+func (sts *StreamTapStatus) GetObservedGeneration() int64 {
+	return sts.ObservedGeneration
+}
+
+func (sts *StreamTapStatus) IsReady() bool {
+	return streamTapCondSet.Manage(sts).IsHappy()
+}
+
+func (*StreamTapStatus) GetReadyConditionType() apis.ConditionType {
+	return StreamTapConditionReady
+}
+
+func (sts *StreamTapStatus) GetCondition(t apis.ConditionType) *apis.Condition {
+	return streamTapCondSet.Manage(sts).GetCondition(t)
+}
+
+func (sts *StreamTapStatus) InitializeConditions() {
+	streamTapCondSet.Manage(sts).InitializeConditions()
+}
+
+func (sts *StreamTapStatus) MarkStreamReady() {
+	streamTapCondSet.Manage(sts).MarkTrue(StreamTapConditionStreamReady)
+}
+
+func (sts *StreamTapStatus) MarkStreamNotReady(message string) {
+	streamTapCondSet.Manage(sts).MarkFalse(StreamTapConditionStreamReady, "StreamNotReady", message)
+}
+
+func (sts *StreamTapStatus) PropagatePodStatus(ps *corev1.PodStatus) {
+	if ps.Phase == corev1.PodFailed || ps.Phase == corev1.PodSucceeded {
+		// the tap is not restarted once terminated
+		streamTapCondSet.Manage(sts).MarkFalse(StreamTapConditionPodReady, "PodTerminated", "Pod %s: %s", ps.Phase, ps.Message)
+		return
+	}
+	for i := range ps.Conditions {
+		if ps.Conditions[i].Type != corev1.PodReady {
+			continue
+		}
+		switch ps.Conditions[i].Status {
+		case corev1.ConditionTrue:
+			streamTapCondSet.Manage(sts).MarkTrue(StreamTapConditionPodReady)
+		default:
+			streamTapCondSet.Manage(sts).MarkUnknown(StreamTapConditionPodReady, ps.Conditions[i].Reason, ps.Conditions[i].Message)
+		}
+		return
+	}
+}
+
+func (sts *StreamTapStatus) PropagateServiceStatus(ss *corev1.ServiceStatus) {
+	// services don't have meaningful status
+	streamTapCondSet.Manage(sts).MarkTrue(StreamTapConditionServiceReady)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/projectriff/system/pkg/apis"
+	"github.com/projectriff/system/pkg/refs"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+var (
+	StreamTapLabelKey = GroupVersion.Group + "/stream-tap"
+)
+
+var (
+	_ apis.Resource = (*StreamTap)(nil)
+)
+
+// StreamTapSpec defines the desired state of StreamTap
+type StreamTapSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// Stream name, from this namespace, to tap. Messages are consumed from
+	// the latest offset with a consumer group dedicated to the tap.
+	Stream string `json:"stream"`
+
+	// Buffer is the number of most recent messages kept in memory and served
+	// by the tap
+	// +optional
+	Buffer *int32 `json:"buffer,omitempty"`
+
+	// TTL is how long the tap lives before being deleted, as a duration
+	// (e.g. 1h)
+	// +optional
+	TTL string `json:"ttl,omitempty"`
+}
+
+// StreamTapStatus defines the observed state of StreamTap
+type StreamTapStatus struct {
+	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	apis.Status `json:",inline"`
+
+	PodRef     *refs.TypedLocalObjectReference `json:"podRef,omitempty"`
+	ServiceRef *refs.TypedLocalObjectReference `json:"serviceRef,omitempty"`
+
+	// Address to read the buffered messages from
+	Address *apis.Addressable `json:"address,omitempty"`
+
+	// ExpirationTime is when the tap is deleted
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories="riff"
+// +kubebuilder:printcolumn:name="Stream",type=string,JSONPath=`.spec.stream`
+// +kubebuilder:printcolumn:name="Address",type=string,JSONPath=`.status.address.url`
+// +kubebuilder:printcolumn:name="Expires",type=date,JSONPath=`.status.expirationTime`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+// +genclient
+
+// StreamTap is the Schema for the streamtaps API
+type StreamTap struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StreamTapSpec   `json:"spec,omitempty"`
+	Status StreamTapStatus `json:"status,omitempty"`
+}
+
+func (*StreamTap) GetGroupVersionKind() schema.GroupVersionKind {
+	return SchemeGroupVersion.WithKind("StreamTap")
+}
+
+func (st *StreamTap) GetStatus() apis.ResourceStatus {
+	return &st.Status
+}
+
+// +kubebuilder:object:root=true
+
+// StreamTapList contains a list of StreamTap
+type StreamTapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StreamTap `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StreamTap{}, &StreamTapList{})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/projectriff/system/pkg/validation"
+)
+
+func TestValidateStreamTap(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamTap
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamTap{},
+		expected: validation.ErrMissingField("spec"),
+	}, {
+		name: "valid",
+		target: &StreamTap{
+			Spec: StreamTapSpec{
+				Stream: "my-stream",
+			},
+		},
+		expected: validation.FieldErrors{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamTap(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
+
+func TestValidateStreamTapSpec(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		target   *StreamTapSpec
+		expected validation.FieldErrors
+	}{{
+		name:     "empty",
+		target:   &StreamTapSpec{},
+		expected: validation.ErrMissingField(validation.CurrentField),
+	}, {
+		name: "valid",
+		target: &StreamTapSpec{
+			Stream: "my-stream",
+			Buffer: int32Ptr(10),
+			TTL:    "5m",
+		},
+		expected: validation.FieldErrors{},
+	}, {
+		name: "requires stream",
+		target: &StreamTapSpec{
+			TTL: "5m",
+		},
+		expected: validation.ErrMissingField("stream"),
+	}, {
+		name: "invalid buffer",
+		target: &StreamTapSpec{
+			Stream: "my-stream",
+			Buffer: int32Ptr(0),
+		},
+		expected: validation.ErrInvalidValue(int32(0), "buffer"),
+	}, {
+		name: "invalid ttl",
+		target: &StreamTapSpec{
+			Stream: "my-stream",
+			TTL:    "forever",
+		},
+		expected: validation.ErrInvalidValue("forever", "ttl"),
+	}, {
+		name: "negative ttl",
+		target: &StreamTapSpec{
+			Stream: "my-stream",
+			TTL:    "-1h",
+		},
+		expected: validation.ErrInvalidValue("-1h", "ttl"),
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual := c.target.Validate()
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("validateStreamTapSpec(%s) (-expected, +actual) = %v", c.name, diff)
+			}
+		})
+	}
+}
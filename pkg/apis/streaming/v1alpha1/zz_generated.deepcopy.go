@@ -22,7 +22,10 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/projectriff/system/pkg/apis"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -57,6 +60,45 @@ func (in *Build) DeepCopy() *Build {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeliverySpec) DeepCopyInto(out *DeliverySpec) {
+	*out = *in
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeliverySpec.
+func (in *DeliverySpec) DeepCopy() *DeliverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeliveryStatus) DeepCopyInto(out *DeliveryStatus) {
+	*out = *in
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeliveryStatus.
+func (in *DeliveryStatus) DeepCopy() *DeliveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeliveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KafkaProvider) DeepCopyInto(out *KafkaProvider) {
 	*out = *in
@@ -151,6 +193,14 @@ func (in *KafkaProviderStatus) DeepCopyInto(out *KafkaProviderStatus) {
 		in, out := &in.ProvisionerServiceRef, &out.ProvisionerServiceRef
 		*out = (*in).DeepCopy()
 	}
+	if in.BrokerStatefulSetRef != nil {
+		in, out := &in.BrokerStatefulSetRef, &out.BrokerStatefulSetRef
+		*out = (*in).DeepCopy()
+	}
+	if in.BrokerServiceRef != nil {
+		in, out := &in.BrokerServiceRef, &out.BrokerServiceRef
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaProviderStatus.
@@ -190,6 +240,61 @@ func (in *Processor) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessorInvocation) DeepCopyInto(out *ProcessorInvocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessorInvocation.
+func (in *ProcessorInvocation) DeepCopy() *ProcessorInvocation {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessorInvocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessorJob) DeepCopyInto(out *ProcessorJob) {
+	*out = *in
+	if in.Completions != nil {
+		in, out := &in.Completions, &out.Completions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Parallelism != nil {
+		in, out := &in.Parallelism, &out.Parallelism
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessorJob.
+func (in *ProcessorJob) DeepCopy() *ProcessorJob {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessorJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessorJobStatus) DeepCopyInto(out *ProcessorJobStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessorJobStatus.
+func (in *ProcessorJobStatus) DeepCopy() *ProcessorJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessorJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProcessorList) DeepCopyInto(out *ProcessorList) {
 	*out = *in
@@ -245,6 +350,21 @@ func (in *ProcessorSpec) DeepCopyInto(out *ProcessorSpec) {
 		*out = new(v1.PodTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Job != nil {
+		in, out := &in.Job, &out.Job
+		*out = new(ProcessorJob)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.State != nil {
+		in, out := &in.State, &out.State
+		*out = new(ProcessorState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Invocation != nil {
+		in, out := &in.Invocation, &out.Invocation
+		*out = new(ProcessorInvocation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessorSpec.
@@ -257,6 +377,32 @@ func (in *ProcessorSpec) DeepCopy() *ProcessorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessorState) DeepCopyInto(out *ProcessorState) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessorState.
+func (in *ProcessorState) DeepCopy() *ProcessorState {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessorState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProcessorStatus) DeepCopyInto(out *ProcessorStatus) {
 	*out = *in
@@ -280,10 +426,23 @@ func (in *ProcessorStatus) DeepCopyInto(out *ProcessorStatus) {
 		in, out := &in.DeploymentRef, &out.DeploymentRef
 		*out = (*in).DeepCopy()
 	}
+	if in.StatefulSetRef != nil {
+		in, out := &in.StatefulSetRef, &out.StatefulSetRef
+		*out = (*in).DeepCopy()
+	}
 	if in.ScaledObjectRef != nil {
 		in, out := &in.ScaledObjectRef, &out.ScaledObjectRef
 		*out = (*in).DeepCopy()
 	}
+	if in.HorizontalPodAutoscalerRef != nil {
+		in, out := &in.HorizontalPodAutoscalerRef, &out.HorizontalPodAutoscalerRef
+		*out = (*in).DeepCopy()
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(ProcessorJobStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessorStatus.
@@ -407,7 +566,7 @@ func (in *Stream) DeepCopyInto(out *Stream) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -429,6 +588,55 @@ func (in *Stream) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamAccess) DeepCopyInto(out *StreamAccess) {
+	*out = *in
+	if in.Publishers != nil {
+		in, out := &in.Publishers, &out.Publishers
+		*out = make([]StreamAccessor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Consumers != nil {
+		in, out := &in.Consumers, &out.Consumers
+		*out = make([]StreamAccessor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamAccess.
+func (in *StreamAccess) DeepCopy() *StreamAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamAccessor) DeepCopyInto(out *StreamAccessor) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamAccessor.
+func (in *StreamAccessor) DeepCopy() *StreamAccessor {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamAccessor)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StreamAddress) DeepCopyInto(out *StreamAddress) {
 	*out = *in
@@ -459,6 +667,113 @@ func (in *StreamBinding) DeepCopy() *StreamBinding {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamIngress) DeepCopyInto(out *StreamIngress) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamIngress.
+func (in *StreamIngress) DeepCopy() *StreamIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StreamIngress) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamIngressList) DeepCopyInto(out *StreamIngressList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StreamIngress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamIngressList.
+func (in *StreamIngressList) DeepCopy() *StreamIngressList {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamIngressList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StreamIngressList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamIngressSpec) DeepCopyInto(out *StreamIngressSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamIngressSpec.
+func (in *StreamIngressSpec) DeepCopy() *StreamIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamIngressStatus) DeepCopyInto(out *StreamIngressStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.DeploymentRef != nil {
+		in, out := &in.DeploymentRef, &out.DeploymentRef
+		*out = (*in).DeepCopy()
+	}
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = (*in).DeepCopy()
+	}
+	if in.IngressRef != nil {
+		in, out := &in.IngressRef, &out.IngressRef
+		*out = (*in).DeepCopy()
+	}
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(apis.Addressable)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamIngressStatus.
+func (in *StreamIngressStatus) DeepCopy() *StreamIngressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamIngressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StreamList) DeepCopyInto(out *StreamList) {
 	*out = *in
@@ -491,9 +806,84 @@ func (in *StreamList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamMigration) DeepCopyInto(out *StreamMigration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamMigration.
+func (in *StreamMigration) DeepCopy() *StreamMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamMigrationStatus) DeepCopyInto(out *StreamMigrationStatus) {
+	*out = *in
+	out.FromAddress = in.FromAddress
+	out.ToAddress = in.ToAddress
+	if in.BridgeRef != nil {
+		in, out := &in.BridgeRef, &out.BridgeRef
+		*out = (*in).DeepCopy()
+	}
+	if in.DrainingSince != nil {
+		in, out := &in.DrainingSince, &out.DrainingSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamMigrationStatus.
+func (in *StreamMigrationStatus) DeepCopy() *StreamMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamSchema) DeepCopyInto(out *StreamSchema) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSchema.
+func (in *StreamSchema) DeepCopy() *StreamSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StreamSpec) DeepCopyInto(out *StreamSpec) {
 	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(StreamSchema)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Access != nil {
+		in, out := &in.Access, &out.Access
+		*out = new(StreamAccess)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(StreamMigration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSpec.
@@ -512,6 +902,11 @@ func (in *StreamStatus) DeepCopyInto(out *StreamStatus) {
 	in.Status.DeepCopyInto(&out.Status)
 	out.Address = in.Address
 	out.Binding = in.Binding
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(StreamMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamStatus.
@@ -523,3 +918,239 @@ func (in *StreamStatus) DeepCopy() *StreamStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamSubscription) DeepCopyInto(out *StreamSubscription) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSubscription.
+func (in *StreamSubscription) DeepCopy() *StreamSubscription {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamSubscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StreamSubscription) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamSubscriptionList) DeepCopyInto(out *StreamSubscriptionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StreamSubscription, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSubscriptionList.
+func (in *StreamSubscriptionList) DeepCopy() *StreamSubscriptionList {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamSubscriptionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StreamSubscriptionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamSubscriptionSpec) DeepCopyInto(out *StreamSubscriptionSpec) {
+	*out = *in
+	if in.Subscriber != nil {
+		in, out := &in.Subscriber, &out.Subscriber
+		*out = new(SubscriberReference)
+		**out = **in
+	}
+	if in.Delivery != nil {
+		in, out := &in.Delivery, &out.Delivery
+		*out = new(DeliverySpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSubscriptionSpec.
+func (in *StreamSubscriptionSpec) DeepCopy() *StreamSubscriptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamSubscriptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamSubscriptionStatus) DeepCopyInto(out *StreamSubscriptionStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.DeploymentRef != nil {
+		in, out := &in.DeploymentRef, &out.DeploymentRef
+		*out = (*in).DeepCopy()
+	}
+	if in.Delivery != nil {
+		in, out := &in.Delivery, &out.Delivery
+		*out = new(DeliveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamSubscriptionStatus.
+func (in *StreamSubscriptionStatus) DeepCopy() *StreamSubscriptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamSubscriptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamTap) DeepCopyInto(out *StreamTap) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamTap.
+func (in *StreamTap) DeepCopy() *StreamTap {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamTap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StreamTap) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamTapList) DeepCopyInto(out *StreamTapList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StreamTap, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamTapList.
+func (in *StreamTapList) DeepCopy() *StreamTapList {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamTapList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StreamTapList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamTapSpec) DeepCopyInto(out *StreamTapSpec) {
+	*out = *in
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamTapSpec.
+func (in *StreamTapSpec) DeepCopy() *StreamTapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamTapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StreamTapStatus) DeepCopyInto(out *StreamTapStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.PodRef != nil {
+		in, out := &in.PodRef, &out.PodRef
+		*out = (*in).DeepCopy()
+	}
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = (*in).DeepCopy()
+	}
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(apis.Addressable)
+		**out = **in
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StreamTapStatus.
+func (in *StreamTapStatus) DeepCopy() *StreamTapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StreamTapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriberReference) DeepCopyInto(out *SubscriberReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriberReference.
+func (in *SubscriberReference) DeepCopy() *SubscriberReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriberReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -55,6 +55,13 @@ type ScaledObjectSpec struct {
 // ScaledObjectScaleType distinguish between Deployment based and K8s Jobs
 type ScaledObjectScaleType string
 
+const (
+	// ScaleTypeDeployment specifies Deployment based ScaleObject
+	ScaleTypeDeployment ScaledObjectScaleType = "deployment"
+	// ScaleTypeJob specifies K8s Jobs based ScaleObject
+	ScaleTypeJob ScaledObjectScaleType = "job"
+)
+
 // ObjectReference holds the a reference to the deployment this
 // ScaledObject applies
 type ObjectReference struct {
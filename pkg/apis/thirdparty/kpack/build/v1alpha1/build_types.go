@@ -26,6 +26,22 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+const (
+	// BuildNumberLabel is the sequence number of a build for its image
+	BuildNumberLabel = "image.build.pivotal.io/buildNumber"
+	// ImageLabel is the name of the image a build is for
+	ImageLabel = "image.build.pivotal.io/image"
+)
+
+const (
+	// DockerSecretAnnotationKey on a Secret is the registry the credential is
+	// for
+	DockerSecretAnnotationKey = "build.pivotal.io/docker"
+	// GitSecretAnnotationKey on a Secret is the git server the credential is
+	// for
+	GitSecretAnnotationKey = "build.pivotal.io/git"
+)
+
 // BuildSpec is the spec for a Build resource.
 type BuildSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
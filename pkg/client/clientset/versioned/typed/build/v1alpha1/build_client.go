@@ -28,6 +28,7 @@ type BuildV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	ApplicationsGetter
 	ContainersGetter
+	CredentialsGetter
 	FunctionsGetter
 }
 
@@ -44,6 +45,10 @@ func (c *BuildV1alpha1Client) Containers(namespace string) ContainerInterface {
 	return newContainers(c, namespace)
 }
 
+func (c *BuildV1alpha1Client) Credentials(namespace string) CredentialInterface {
+	return newCredentials(c, namespace)
+}
+
 func (c *BuildV1alpha1Client) Functions(namespace string) FunctionInterface {
 	return newFunctions(c, namespace)
 }
@@ -0,0 +1,191 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	scheme "github.com/projectriff/system/pkg/client/clientset/versioned/scheme"
+)
+
+// CredentialsGetter has a method to return a CredentialInterface.
+// A group's client should implement this interface.
+type CredentialsGetter interface {
+	Credentials(namespace string) CredentialInterface
+}
+
+// CredentialInterface has methods to work with Credential resources.
+type CredentialInterface interface {
+	Create(*v1alpha1.Credential) (*v1alpha1.Credential, error)
+	Update(*v1alpha1.Credential) (*v1alpha1.Credential, error)
+	UpdateStatus(*v1alpha1.Credential) (*v1alpha1.Credential, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.Credential, error)
+	List(opts v1.ListOptions) (*v1alpha1.CredentialList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.Credential, err error)
+	CredentialExpansion
+}
+
+// credentials implements CredentialInterface
+type credentials struct {
+	client rest.Interface
+	ns     string
+}
+
+// newCredentials returns a Credentials
+func newCredentials(c *BuildV1alpha1Client, namespace string) *credentials {
+	return &credentials{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the credential, and returns the corresponding credential object, and an error if there is any.
+func (c *credentials) Get(name string, options v1.GetOptions) (result *v1alpha1.Credential, err error) {
+	result = &v1alpha1.Credential{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("credentials").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Credentials that match those selectors.
+func (c *credentials) List(opts v1.ListOptions) (result *v1alpha1.CredentialList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.CredentialList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("credentials").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested credentials.
+func (c *credentials) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("credentials").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a credential and creates it.  Returns the server's representation of the credential, and an error, if there is any.
+func (c *credentials) Create(credential *v1alpha1.Credential) (result *v1alpha1.Credential, err error) {
+	result = &v1alpha1.Credential{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("credentials").
+		Body(credential).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a credential and updates it. Returns the server's representation of the credential, and an error, if there is any.
+func (c *credentials) Update(credential *v1alpha1.Credential) (result *v1alpha1.Credential, err error) {
+	result = &v1alpha1.Credential{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("credentials").
+		Name(credential.Name).
+		Body(credential).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *credentials) UpdateStatus(credential *v1alpha1.Credential) (result *v1alpha1.Credential, err error) {
+	result = &v1alpha1.Credential{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("credentials").
+		Name(credential.Name).
+		SubResource("status").
+		Body(credential).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the credential and deletes it. Returns an error if one occurs.
+func (c *credentials) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("credentials").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *credentials) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("credentials").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched credential.
+func (c *credentials) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.Credential, err error) {
+	result = &v1alpha1.Credential{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("credentials").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -36,6 +36,10 @@ func (c *FakeBuildV1alpha1) Containers(namespace string) v1alpha1.ContainerInter
 	return &FakeContainers{c, namespace}
 }
 
+func (c *FakeBuildV1alpha1) Credentials(namespace string) v1alpha1.CredentialInterface {
+	return &FakeCredentials{c, namespace}
+}
+
 func (c *FakeBuildV1alpha1) Functions(namespace string) v1alpha1.FunctionInterface {
 	return &FakeFunctions{c, namespace}
 }
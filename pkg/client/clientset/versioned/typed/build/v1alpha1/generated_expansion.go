@@ -21,4 +21,6 @@ type ApplicationExpansion interface{}
 
 type ContainerExpansion interface{}
 
+type CredentialExpansion interface{}
+
 type FunctionExpansion interface{}
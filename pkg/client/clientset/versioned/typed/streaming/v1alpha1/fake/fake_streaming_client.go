@@ -44,6 +44,18 @@ func (c *FakeStreamingV1alpha1) Streams(namespace string) v1alpha1.StreamInterfa
 	return &FakeStreams{c, namespace}
 }
 
+func (c *FakeStreamingV1alpha1) StreamIngresses(namespace string) v1alpha1.StreamIngressInterface {
+	return &FakeStreamIngresses{c, namespace}
+}
+
+func (c *FakeStreamingV1alpha1) StreamSubscriptions(namespace string) v1alpha1.StreamSubscriptionInterface {
+	return &FakeStreamSubscriptions{c, namespace}
+}
+
+func (c *FakeStreamingV1alpha1) StreamTaps(namespace string) v1alpha1.StreamTapInterface {
+	return &FakeStreamTaps{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeStreamingV1alpha1) RESTClient() rest.Interface {
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+// FakeStreamIngresses implements StreamIngressInterface
+type FakeStreamIngresses struct {
+	Fake *FakeStreamingV1alpha1
+	ns   string
+}
+
+var streamingressesResource = schema.GroupVersionResource{Group: "streaming.projectriff.io", Version: "v1alpha1", Resource: "streamingresses"}
+
+var streamingressesKind = schema.GroupVersionKind{Group: "streaming.projectriff.io", Version: "v1alpha1", Kind: "StreamIngress"}
+
+// Get takes name of the streamIngress, and returns the corresponding streamIngress object, and an error if there is any.
+func (c *FakeStreamIngresses) Get(name string, options v1.GetOptions) (result *v1alpha1.StreamIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(streamingressesResource, c.ns, name), &v1alpha1.StreamIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamIngress), err
+}
+
+// List takes label and field selectors, and returns the list of StreamIngresses that match those selectors.
+func (c *FakeStreamIngresses) List(opts v1.ListOptions) (result *v1alpha1.StreamIngressList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(streamingressesResource, streamingressesKind, c.ns, opts), &v1alpha1.StreamIngressList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.StreamIngressList{ListMeta: obj.(*v1alpha1.StreamIngressList).ListMeta}
+	for _, item := range obj.(*v1alpha1.StreamIngressList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested streamIngresses.
+func (c *FakeStreamIngresses) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(streamingressesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a streamIngress and creates it.  Returns the server's representation of the streamIngress, and an error, if there is any.
+func (c *FakeStreamIngresses) Create(streamIngress *v1alpha1.StreamIngress) (result *v1alpha1.StreamIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(streamingressesResource, c.ns, streamIngress), &v1alpha1.StreamIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamIngress), err
+}
+
+// Update takes the representation of a streamIngress and updates it. Returns the server's representation of the streamIngress, and an error, if there is any.
+func (c *FakeStreamIngresses) Update(streamIngress *v1alpha1.StreamIngress) (result *v1alpha1.StreamIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(streamingressesResource, c.ns, streamIngress), &v1alpha1.StreamIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamIngress), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeStreamIngresses) UpdateStatus(streamIngress *v1alpha1.StreamIngress) (*v1alpha1.StreamIngress, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(streamingressesResource, "status", c.ns, streamIngress), &v1alpha1.StreamIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamIngress), err
+}
+
+// Delete takes name of the streamIngress and deletes it. Returns an error if one occurs.
+func (c *FakeStreamIngresses) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(streamingressesResource, c.ns, name), &v1alpha1.StreamIngress{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeStreamIngresses) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(streamingressesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.StreamIngressList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched streamIngress.
+func (c *FakeStreamIngresses) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamIngress, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(streamingressesResource, c.ns, name, pt, data, subresources...), &v1alpha1.StreamIngress{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamIngress), err
+}
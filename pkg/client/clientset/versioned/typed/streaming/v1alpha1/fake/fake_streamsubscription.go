@@ -0,0 +1,140 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+// FakeStreamSubscriptions implements StreamSubscriptionInterface
+type FakeStreamSubscriptions struct {
+	Fake *FakeStreamingV1alpha1
+	ns   string
+}
+
+var streamsubscriptionsResource = schema.GroupVersionResource{Group: "streaming.projectriff.io", Version: "v1alpha1", Resource: "streamsubscriptions"}
+
+var streamsubscriptionsKind = schema.GroupVersionKind{Group: "streaming.projectriff.io", Version: "v1alpha1", Kind: "StreamSubscription"}
+
+// Get takes name of the streamSubscription, and returns the corresponding streamSubscription object, and an error if there is any.
+func (c *FakeStreamSubscriptions) Get(name string, options v1.GetOptions) (result *v1alpha1.StreamSubscription, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(streamsubscriptionsResource, c.ns, name), &v1alpha1.StreamSubscription{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamSubscription), err
+}
+
+// List takes label and field selectors, and returns the list of StreamSubscriptions that match those selectors.
+func (c *FakeStreamSubscriptions) List(opts v1.ListOptions) (result *v1alpha1.StreamSubscriptionList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(streamsubscriptionsResource, streamsubscriptionsKind, c.ns, opts), &v1alpha1.StreamSubscriptionList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.StreamSubscriptionList{ListMeta: obj.(*v1alpha1.StreamSubscriptionList).ListMeta}
+	for _, item := range obj.(*v1alpha1.StreamSubscriptionList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested streamSubscriptions.
+func (c *FakeStreamSubscriptions) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(streamsubscriptionsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a streamSubscription and creates it.  Returns the server's representation of the streamSubscription, and an error, if there is any.
+func (c *FakeStreamSubscriptions) Create(streamSubscription *v1alpha1.StreamSubscription) (result *v1alpha1.StreamSubscription, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(streamsubscriptionsResource, c.ns, streamSubscription), &v1alpha1.StreamSubscription{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamSubscription), err
+}
+
+// Update takes the representation of a streamSubscription and updates it. Returns the server's representation of the streamSubscription, and an error, if there is any.
+func (c *FakeStreamSubscriptions) Update(streamSubscription *v1alpha1.StreamSubscription) (result *v1alpha1.StreamSubscription, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(streamsubscriptionsResource, c.ns, streamSubscription), &v1alpha1.StreamSubscription{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamSubscription), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeStreamSubscriptions) UpdateStatus(streamSubscription *v1alpha1.StreamSubscription) (*v1alpha1.StreamSubscription, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(streamsubscriptionsResource, "status", c.ns, streamSubscription), &v1alpha1.StreamSubscription{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamSubscription), err
+}
+
+// Delete takes name of the streamSubscription and deletes it. Returns an error if one occurs.
+func (c *FakeStreamSubscriptions) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(streamsubscriptionsResource, c.ns, name), &v1alpha1.StreamSubscription{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeStreamSubscriptions) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(streamsubscriptionsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.StreamSubscriptionList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched streamSubscription.
+func (c *FakeStreamSubscriptions) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamSubscription, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(streamsubscriptionsResource, c.ns, name, pt, data, subresources...), &v1alpha1.StreamSubscription{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamSubscription), err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+// FakeStreamTaps implements StreamTapInterface
+type FakeStreamTaps struct {
+	Fake *FakeStreamingV1alpha1
+	ns   string
+}
+
+var streamtapsResource = schema.GroupVersionResource{Group: "streaming.projectriff.io", Version: "v1alpha1", Resource: "streamtaps"}
+
+var streamtapsKind = schema.GroupVersionKind{Group: "streaming.projectriff.io", Version: "v1alpha1", Kind: "StreamTap"}
+
+// Get takes name of the streamTap, and returns the corresponding streamTap object, and an error if there is any.
+func (c *FakeStreamTaps) Get(name string, options v1.GetOptions) (result *v1alpha1.StreamTap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(streamtapsResource, c.ns, name), &v1alpha1.StreamTap{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamTap), err
+}
+
+// List takes label and field selectors, and returns the list of StreamTaps that match those selectors.
+func (c *FakeStreamTaps) List(opts v1.ListOptions) (result *v1alpha1.StreamTapList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(streamtapsResource, streamtapsKind, c.ns, opts), &v1alpha1.StreamTapList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.StreamTapList{ListMeta: obj.(*v1alpha1.StreamTapList).ListMeta}
+	for _, item := range obj.(*v1alpha1.StreamTapList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested streamTaps.
+func (c *FakeStreamTaps) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(streamtapsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a streamTap and creates it.  Returns the server's representation of the streamTap, and an error, if there is any.
+func (c *FakeStreamTaps) Create(streamTap *v1alpha1.StreamTap) (result *v1alpha1.StreamTap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(streamtapsResource, c.ns, streamTap), &v1alpha1.StreamTap{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamTap), err
+}
+
+// Update takes the representation of a streamTap and updates it. Returns the server's representation of the streamTap, and an error, if there is any.
+func (c *FakeStreamTaps) Update(streamTap *v1alpha1.StreamTap) (result *v1alpha1.StreamTap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(streamtapsResource, c.ns, streamTap), &v1alpha1.StreamTap{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamTap), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeStreamTaps) UpdateStatus(streamTap *v1alpha1.StreamTap) (*v1alpha1.StreamTap, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(streamtapsResource, "status", c.ns, streamTap), &v1alpha1.StreamTap{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamTap), err
+}
+
+// Delete takes name of the streamTap and deletes it. Returns an error if one occurs.
+func (c *FakeStreamTaps) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(streamtapsResource, c.ns, name), &v1alpha1.StreamTap{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeStreamTaps) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(streamtapsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.StreamTapList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched streamTap.
+func (c *FakeStreamTaps) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamTap, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(streamtapsResource, c.ns, name, pt, data, subresources...), &v1alpha1.StreamTap{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.StreamTap), err
+}
@@ -24,3 +24,9 @@ type ProcessorExpansion interface{}
 type PulsarProviderExpansion interface{}
 
 type StreamExpansion interface{}
+
+type StreamIngressExpansion interface{}
+
+type StreamSubscriptionExpansion interface{}
+
+type StreamTapExpansion interface{}
@@ -30,6 +30,9 @@ type StreamingV1alpha1Interface interface {
 	ProcessorsGetter
 	PulsarProvidersGetter
 	StreamsGetter
+	StreamIngressesGetter
+	StreamSubscriptionsGetter
+	StreamTapsGetter
 }
 
 // StreamingV1alpha1Client is used to interact with features provided by the streaming.projectriff.io group.
@@ -53,6 +56,18 @@ func (c *StreamingV1alpha1Client) Streams(namespace string) StreamInterface {
 	return newStreams(c, namespace)
 }
 
+func (c *StreamingV1alpha1Client) StreamIngresses(namespace string) StreamIngressInterface {
+	return newStreamIngresses(c, namespace)
+}
+
+func (c *StreamingV1alpha1Client) StreamSubscriptions(namespace string) StreamSubscriptionInterface {
+	return newStreamSubscriptions(c, namespace)
+}
+
+func (c *StreamingV1alpha1Client) StreamTaps(namespace string) StreamTapInterface {
+	return newStreamTaps(c, namespace)
+}
+
 // NewForConfig creates a new StreamingV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*StreamingV1alpha1Client, error) {
 	config := *c
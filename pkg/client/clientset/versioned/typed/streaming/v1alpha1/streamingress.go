@@ -0,0 +1,191 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	scheme "github.com/projectriff/system/pkg/client/clientset/versioned/scheme"
+)
+
+// StreamIngressesGetter has a method to return a StreamIngressInterface.
+// A group's client should implement this interface.
+type StreamIngressesGetter interface {
+	StreamIngresses(namespace string) StreamIngressInterface
+}
+
+// StreamIngressInterface has methods to work with StreamIngress resources.
+type StreamIngressInterface interface {
+	Create(*v1alpha1.StreamIngress) (*v1alpha1.StreamIngress, error)
+	Update(*v1alpha1.StreamIngress) (*v1alpha1.StreamIngress, error)
+	UpdateStatus(*v1alpha1.StreamIngress) (*v1alpha1.StreamIngress, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.StreamIngress, error)
+	List(opts v1.ListOptions) (*v1alpha1.StreamIngressList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamIngress, err error)
+	StreamIngressExpansion
+}
+
+// streamIngresses implements StreamIngressInterface
+type streamIngresses struct {
+	client rest.Interface
+	ns     string
+}
+
+// newStreamIngresses returns a StreamIngresses
+func newStreamIngresses(c *StreamingV1alpha1Client, namespace string) *streamIngresses {
+	return &streamIngresses{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the streamIngress, and returns the corresponding streamIngress object, and an error if there is any.
+func (c *streamIngresses) Get(name string, options v1.GetOptions) (result *v1alpha1.StreamIngress, err error) {
+	result = &v1alpha1.StreamIngress{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of StreamIngresses that match those selectors.
+func (c *streamIngresses) List(opts v1.ListOptions) (result *v1alpha1.StreamIngressList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.StreamIngressList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested streamIngresses.
+func (c *streamIngresses) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a streamIngress and creates it.  Returns the server's representation of the streamIngress, and an error, if there is any.
+func (c *streamIngresses) Create(streamIngress *v1alpha1.StreamIngress) (result *v1alpha1.StreamIngress, err error) {
+	result = &v1alpha1.StreamIngress{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		Body(streamIngress).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a streamIngress and updates it. Returns the server's representation of the streamIngress, and an error, if there is any.
+func (c *streamIngresses) Update(streamIngress *v1alpha1.StreamIngress) (result *v1alpha1.StreamIngress, err error) {
+	result = &v1alpha1.StreamIngress{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		Name(streamIngress.Name).
+		Body(streamIngress).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *streamIngresses) UpdateStatus(streamIngress *v1alpha1.StreamIngress) (result *v1alpha1.StreamIngress, err error) {
+	result = &v1alpha1.StreamIngress{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		Name(streamIngress.Name).
+		SubResource("status").
+		Body(streamIngress).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the streamIngress and deletes it. Returns an error if one occurs.
+func (c *streamIngresses) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *streamIngresses) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("streamingresses").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched streamIngress.
+func (c *streamIngresses) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamIngress, err error) {
+	result = &v1alpha1.StreamIngress{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("streamingresses").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
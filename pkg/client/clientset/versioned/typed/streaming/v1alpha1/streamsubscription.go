@@ -0,0 +1,191 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	scheme "github.com/projectriff/system/pkg/client/clientset/versioned/scheme"
+)
+
+// StreamSubscriptionsGetter has a method to return a StreamSubscriptionInterface.
+// A group's client should implement this interface.
+type StreamSubscriptionsGetter interface {
+	StreamSubscriptions(namespace string) StreamSubscriptionInterface
+}
+
+// StreamSubscriptionInterface has methods to work with StreamSubscription resources.
+type StreamSubscriptionInterface interface {
+	Create(*v1alpha1.StreamSubscription) (*v1alpha1.StreamSubscription, error)
+	Update(*v1alpha1.StreamSubscription) (*v1alpha1.StreamSubscription, error)
+	UpdateStatus(*v1alpha1.StreamSubscription) (*v1alpha1.StreamSubscription, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.StreamSubscription, error)
+	List(opts v1.ListOptions) (*v1alpha1.StreamSubscriptionList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamSubscription, err error)
+	StreamSubscriptionExpansion
+}
+
+// streamSubscriptions implements StreamSubscriptionInterface
+type streamSubscriptions struct {
+	client rest.Interface
+	ns     string
+}
+
+// newStreamSubscriptions returns a StreamSubscriptions
+func newStreamSubscriptions(c *StreamingV1alpha1Client, namespace string) *streamSubscriptions {
+	return &streamSubscriptions{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the streamSubscription, and returns the corresponding streamSubscription object, and an error if there is any.
+func (c *streamSubscriptions) Get(name string, options v1.GetOptions) (result *v1alpha1.StreamSubscription, err error) {
+	result = &v1alpha1.StreamSubscription{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of StreamSubscriptions that match those selectors.
+func (c *streamSubscriptions) List(opts v1.ListOptions) (result *v1alpha1.StreamSubscriptionList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.StreamSubscriptionList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested streamSubscriptions.
+func (c *streamSubscriptions) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a streamSubscription and creates it.  Returns the server's representation of the streamSubscription, and an error, if there is any.
+func (c *streamSubscriptions) Create(streamSubscription *v1alpha1.StreamSubscription) (result *v1alpha1.StreamSubscription, err error) {
+	result = &v1alpha1.StreamSubscription{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		Body(streamSubscription).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a streamSubscription and updates it. Returns the server's representation of the streamSubscription, and an error, if there is any.
+func (c *streamSubscriptions) Update(streamSubscription *v1alpha1.StreamSubscription) (result *v1alpha1.StreamSubscription, err error) {
+	result = &v1alpha1.StreamSubscription{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		Name(streamSubscription.Name).
+		Body(streamSubscription).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *streamSubscriptions) UpdateStatus(streamSubscription *v1alpha1.StreamSubscription) (result *v1alpha1.StreamSubscription, err error) {
+	result = &v1alpha1.StreamSubscription{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		Name(streamSubscription.Name).
+		SubResource("status").
+		Body(streamSubscription).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the streamSubscription and deletes it. Returns an error if one occurs.
+func (c *streamSubscriptions) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *streamSubscriptions) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched streamSubscription.
+func (c *streamSubscriptions) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamSubscription, err error) {
+	result = &v1alpha1.StreamSubscription{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("streamsubscriptions").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+
+	v1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	scheme "github.com/projectriff/system/pkg/client/clientset/versioned/scheme"
+)
+
+// StreamTapsGetter has a method to return a StreamTapInterface.
+// A group's client should implement this interface.
+type StreamTapsGetter interface {
+	StreamTaps(namespace string) StreamTapInterface
+}
+
+// StreamTapInterface has methods to work with StreamTap resources.
+type StreamTapInterface interface {
+	Create(*v1alpha1.StreamTap) (*v1alpha1.StreamTap, error)
+	Update(*v1alpha1.StreamTap) (*v1alpha1.StreamTap, error)
+	UpdateStatus(*v1alpha1.StreamTap) (*v1alpha1.StreamTap, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.StreamTap, error)
+	List(opts v1.ListOptions) (*v1alpha1.StreamTapList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamTap, err error)
+	StreamTapExpansion
+}
+
+// streamTaps implements StreamTapInterface
+type streamTaps struct {
+	client rest.Interface
+	ns     string
+}
+
+// newStreamTaps returns a StreamTaps
+func newStreamTaps(c *StreamingV1alpha1Client, namespace string) *streamTaps {
+	return &streamTaps{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the streamTap, and returns the corresponding streamTap object, and an error if there is any.
+func (c *streamTaps) Get(name string, options v1.GetOptions) (result *v1alpha1.StreamTap, err error) {
+	result = &v1alpha1.StreamTap{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of StreamTaps that match those selectors.
+func (c *streamTaps) List(opts v1.ListOptions) (result *v1alpha1.StreamTapList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.StreamTapList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested streamTaps.
+func (c *streamTaps) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a streamTap and creates it.  Returns the server's representation of the streamTap, and an error, if there is any.
+func (c *streamTaps) Create(streamTap *v1alpha1.StreamTap) (result *v1alpha1.StreamTap, err error) {
+	result = &v1alpha1.StreamTap{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		Body(streamTap).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a streamTap and updates it. Returns the server's representation of the streamTap, and an error, if there is any.
+func (c *streamTaps) Update(streamTap *v1alpha1.StreamTap) (result *v1alpha1.StreamTap, err error) {
+	result = &v1alpha1.StreamTap{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		Name(streamTap.Name).
+		Body(streamTap).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *streamTaps) UpdateStatus(streamTap *v1alpha1.StreamTap) (result *v1alpha1.StreamTap, err error) {
+	result = &v1alpha1.StreamTap{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		Name(streamTap.Name).
+		SubResource("status").
+		Body(streamTap).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the streamTap and deletes it. Returns an error if one occurs.
+func (c *streamTaps) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *streamTaps) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("streamtaps").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched streamTap.
+func (c *streamTaps) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.StreamTap, err error) {
+	result = &v1alpha1.StreamTap{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("streamtaps").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
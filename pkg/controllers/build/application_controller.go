@@ -32,11 +32,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
 	"github.com/projectriff/system/pkg/controllers"
 	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
 )
 
 const applicationIndexField = ".metadata.applicationController"
@@ -44,13 +46,17 @@ const applicationIndexField = ".metadata.applicationController"
 // ApplicationReconciler reconciles a Application object
 type ApplicationReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
 }
 
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=applications/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=build.pivotal.io,resources=images,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=build.pivotal.io,resources=builds,verbs=get;list;watch
+// +kubebuilder:rbac:groups=build.pivotal.io,resources=builders,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 
 func (r *ApplicationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -107,8 +113,23 @@ func (r *ApplicationReconciler) reconcile(ctx context.Context, log logr.Logger,
 	application.Status.MarkImageResolved()
 	application.Status.TargetImage = targetImage
 
+	// resolve builder
+	var builder *kpackbuildv1alpha1.ImageBuilder
+	var builderImage string
+	if application.Spec.Source != nil {
+		builder, builderImage, err = resolveBuilder(ctx, r.Client, r.Tracker, r.Namespace, types.NamespacedName{Namespace: application.Namespace, Name: application.Name}, application.Spec.Builder, "riff-application")
+		if err != nil {
+			if _, ok := err.(*builderNotFoundError); ok {
+				application.Status.MarkBuilderNotFound(err.Error())
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	application.Status.MarkBuilderResolved()
+
 	// reconcile child kpack image
-	childImage, err := r.reconcileChildKpackImage(ctx, log, application)
+	childImage, err := r.reconcileChildKpackImage(ctx, log, application, builder, builderImage)
 	if err != nil {
 		log.Error(err, "unable to reconcile child Image", "application", application)
 		return ctrl.Result{}, err
@@ -121,6 +142,14 @@ func (r *ApplicationReconciler) reconcile(ctx context.Context, log logr.Logger,
 		application.Status.KpackImageRef = refs.NewTypedLocalObjectReferenceForObject(childImage, r.Scheme)
 		application.Status.LatestImage = childImage.Status.LatestImage
 		application.Status.BuildCacheRef = refs.NewTypedLocalObjectReference(childImage.Status.BuildCacheName, schema.GroupKind{Kind: "PersistentVolumeClaim"})
+
+		builds, err := listKpackBuilds(ctx, r.Client, childImage)
+		if err != nil {
+			log.Error(err, "unable to list kpack Builds", "image", childImage.Name)
+			return ctrl.Result{}, err
+		}
+		application.Status.PropagateKpackBuilds(builds)
+		application.Status.PropagateBuilderImage(builderImage)
 		application.Status.PropagateKpackImageStatus(&childImage.Status)
 	}
 
@@ -152,7 +181,7 @@ func (r *ApplicationReconciler) resolveTargetImage(ctx context.Context, log logr
 	return image, nil
 }
 
-func (r *ApplicationReconciler) reconcileChildKpackImage(ctx context.Context, log logr.Logger, application *buildv1alpha1.Application) (*kpackbuildv1alpha1.Image, error) {
+func (r *ApplicationReconciler) reconcileChildKpackImage(ctx context.Context, log logr.Logger, application *buildv1alpha1.Application, builder *kpackbuildv1alpha1.ImageBuilder, builderImage string) (*kpackbuildv1alpha1.Image, error) {
 	var actualImage kpackbuildv1alpha1.Image
 	var childImages kpackbuildv1alpha1.ImageList
 	if err := r.List(ctx, &childImages, client.InNamespace(application.Namespace), client.MatchingField(applicationIndexField, application.Name)); err != nil {
@@ -171,7 +200,7 @@ func (r *ApplicationReconciler) reconcileChildKpackImage(ctx context.Context, lo
 		}
 	}
 
-	desiredImage, err := r.constructImageForApplication(application)
+	desiredImage, err := r.constructImageForApplication(application, builder, builderImage)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +250,7 @@ func (r *ApplicationReconciler) imageSemanticEquals(desiredImage, image *kpackbu
 		equality.Semantic.DeepEqual(desiredImage.ObjectMeta.Labels, image.ObjectMeta.Labels)
 }
 
-func (r *ApplicationReconciler) constructImageForApplication(application *buildv1alpha1.Application) (*kpackbuildv1alpha1.Image, error) {
+func (r *ApplicationReconciler) constructImageForApplication(application *buildv1alpha1.Application, builder *kpackbuildv1alpha1.ImageBuilder, builderImage string) (*kpackbuildv1alpha1.Image, error) {
 	if application.Spec.Source == nil {
 		return nil, nil
 	}
@@ -234,14 +263,9 @@ func (r *ApplicationReconciler) constructImageForApplication(application *buildv
 			Namespace:    application.Namespace,
 		},
 		Spec: kpackbuildv1alpha1.ImageSpec{
-			Tag: application.Status.TargetImage,
-			Builder: kpackbuildv1alpha1.ImageBuilder{
-				TypeMeta: metav1.TypeMeta{
-					Kind: "ClusterBuilder",
-				},
-				Name: "riff-application",
-			},
-			ServiceAccount:           "riff-build",
+			Tag:                      application.Status.TargetImage,
+			Builder:                  *builder,
+			ServiceAccount:           buildServiceAccountName(application.Spec.ServiceAccountName),
 			Source:                   *application.Spec.Source,
 			CacheSize:                application.Spec.CacheSize,
 			FailedBuildHistoryLimit:  application.Spec.FailedBuildHistoryLimit,
@@ -250,6 +274,20 @@ func (r *ApplicationReconciler) constructImageForApplication(application *buildv
 			Build:                    application.Spec.Build,
 		},
 	}
+	if trigger := application.Status.LatestTrigger; trigger.AppliesTo(application.Spec.Source) {
+		// changing build configuration triggers a kpack build
+		image.Spec.Build.Env = append(image.Spec.Build.Env, v1.EnvVar{Name: gitCommitEnvVar, Value: trigger.Commit})
+	}
+	if application.Spec.RebuildPolicy == buildv1alpha1.BuilderUpdateRebuildPolicy {
+		if builderImage == "" {
+			// hold the last value while the builder is not ready, avoiding a rebuild
+			builderImage = application.Status.LatestBuilderImage
+		}
+		if builderImage != "" {
+			// changing build configuration triggers a kpack build
+			image.Spec.Build.Env = append(image.Spec.Build.Env, v1.EnvVar{Name: builderImageEnvVar, Value: builderImage})
+		}
+	}
 	if err := ctrl.SetControllerReference(application, image, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -277,5 +315,11 @@ func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&buildv1alpha1.Application{}).
 		Owns(&kpackbuildv1alpha1.Image{}).
+		// track the builders catalog and namespaced builders
+		Watches(&source.Kind{Type: &v1.ConfigMap{}}, enqueueTrackedResources(r.Tracker, configMapGVK)).
+		Watches(&source.Kind{Type: &kpackbuildv1alpha1.Builder{}}, enqueueTrackedResources(r.Tracker, kpackBuilderGVK)).
+		// builds are owned by the image, map them back through the labels
+		// inherited from the image
+		Watches(&source.Kind{Type: &kpackbuildv1alpha1.Build{}}, enqueueForKpackBuild(buildv1alpha1.ApplicationLabelKey)).
 		Complete(r)
 }
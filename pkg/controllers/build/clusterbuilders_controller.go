@@ -18,7 +18,6 @@ package build
 
 import (
 	"context"
-	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -85,11 +84,11 @@ func (r *ClusterBuilderReconciler) reconcile(ctx context.Context, log logr.Logge
 		return ctrl.Result{Requeue: true}, err
 	}
 
+	// the catalog includes every ClusterBuilder as applications and functions
+	// may reference any of them
 	builderImages := make(map[string]string)
 	for _, builder := range clusterBuilders.Items {
-		if isTargetClusterBuilder(builder.Name) {
-			builderImages[builder.Name] = builder.Status.LatestImage
-		}
+		builderImages[builder.Name] = builder.Status.LatestImage
 	}
 
 	if configMap.Name == "" {
@@ -137,16 +136,9 @@ func configMapSemanticEquals(desiredConfigMap, configMap *corev1.ConfigMap) bool
 	return equality.Semantic.DeepEqual(desiredConfigMap.Data, configMap.Data)
 }
 
-func isTargetClusterBuilder(name string) bool {
-	return strings.HasPrefix(name, "riff-")
-}
-
 func (r *ClusterBuilderReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	enqueueConfigMap := &handler.EnqueueRequestsFromMapFunc{
 		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
-			if !isTargetClusterBuilder(a.Meta.GetName()) {
-				return []reconcile.Request{}
-			}
 			return []reconcile.Request{
 				{
 					NamespacedName: types.NamespacedName{
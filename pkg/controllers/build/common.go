@@ -16,6 +16,134 @@ limitations under the License.
 
 package build
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
 
 var errMissingDefaultPrefix = fmt.Errorf("missing default image prefix")
+
+var (
+	configMapGVK    = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	kpackBuilderGVK = kpackbuildv1alpha1.GroupVersion.WithKind("Builder")
+)
+
+type builderNotFoundError struct {
+	kind buildv1alpha1.BuilderKind
+	name string
+}
+
+func (e *builderNotFoundError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.kind, e.name)
+}
+
+// builderImageEnvVar is set on builds with the image of the builder when
+// builder updates trigger rebuilds, kpack builds again as the value changes.
+const builderImageEnvVar = "RIFF_BUILDER_IMAGE"
+
+// gitCommitEnvVar is set on builds with the most recently pushed commit, kpack
+// builds again as the value changes while the source keeps following the
+// branch. The value remains once the commit is built, removing it would
+// trigger yet another build.
+const gitCommitEnvVar = "RIFF_GIT_COMMIT"
+
+// resolveBuilder finds the kpack builder referenced by a build resource,
+// falling back to the default ClusterBuilder. ClusterBuilders are checked
+// against the builders catalog in the system namespace. The catalog or
+// namespaced Builder is tracked so the resource is reconciled as it changes.
+// The image the builder currently provides is returned alongside, empty when
+// the builder is not ready.
+func resolveBuilder(ctx context.Context, c client.Client, t tracker.Tracker, systemNamespace string, resource types.NamespacedName, ref *buildv1alpha1.BuilderReference, defaultName string) (*kpackbuildv1alpha1.ImageBuilder, string, error) {
+	if ref == nil {
+		ref = &buildv1alpha1.BuilderReference{Name: defaultName}
+	}
+	ref = ref.DeepCopy()
+	ref.Default()
+
+	var builderImage string
+	switch ref.Kind {
+	case buildv1alpha1.NamespacedBuilderKind:
+		builderKey := types.NamespacedName{Namespace: resource.Namespace, Name: ref.Name}
+		t.Track(tracker.NewKey(kpackBuilderGVK, builderKey), resource)
+		var builder kpackbuildv1alpha1.Builder
+		if err := c.Get(ctx, builderKey, &builder); err != nil {
+			if apierrs.IsNotFound(err) {
+				return nil, "", &builderNotFoundError{kind: ref.Kind, name: ref.Name}
+			}
+			return nil, "", err
+		}
+		builderImage = builder.Status.LatestImage
+	default:
+		catalogKey := types.NamespacedName{Namespace: systemNamespace, Name: buildersConfigMap}
+		t.Track(tracker.NewKey(configMapGVK, catalogKey), resource)
+		var catalog corev1.ConfigMap
+		if err := c.Get(ctx, catalogKey, &catalog); err != nil && !apierrs.IsNotFound(err) {
+			return nil, "", err
+		}
+		image, ok := catalog.Data[ref.Name]
+		if !ok {
+			return nil, "", &builderNotFoundError{kind: ref.Kind, name: ref.Name}
+		}
+		builderImage = image
+	}
+
+	return &kpackbuildv1alpha1.ImageBuilder{
+		TypeMeta: metav1.TypeMeta{
+			Kind: string(ref.Kind),
+		},
+		Name: ref.Name,
+	}, builderImage, nil
+}
+
+// listKpackBuilds finds the builds kpack created for the image.
+func listKpackBuilds(ctx context.Context, c client.Client, image *kpackbuildv1alpha1.Image) ([]kpackbuildv1alpha1.Build, error) {
+	var builds kpackbuildv1alpha1.BuildList
+	if err := c.List(ctx, &builds, client.InNamespace(image.Namespace), client.MatchingLabels{kpackbuildv1alpha1.ImageLabel: image.Name}); err != nil {
+		return nil, err
+	}
+	return builds.Items, nil
+}
+
+// enqueueForKpackBuild maps a kpack Build to the resource named by the label
+// kpack copies from the Image to its builds.
+func enqueueForKpackBuild(labelKey string) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			name, ok := a.Meta.GetLabels()[labelKey]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: name}},
+			}
+		}),
+	}
+}
+
+// enqueueTrackedResources maps a resource of the kind to the build resources
+// tracking it.
+func enqueueTrackedResources(t tracker.Tracker, gvk schema.GroupVersionKind) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			key := tracker.NewKey(gvk, types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()})
+			for _, item := range t.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+}
@@ -19,6 +19,7 @@ package build
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -31,18 +32,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
 )
 
 // ContainerReconciler reconciles a Container object
 type ContainerReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log            logr.Logger
+	Scheme         *runtime.Scheme
+	RegistryClient *RegistryClient
+	// PollInterval is how often the registry is checked for a new digest
+	PollInterval time.Duration
 }
 
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=containers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=containers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 
 func (r *ContainerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -95,15 +101,118 @@ func (r *ContainerReconciler) reconcile(ctx context.Context, log logr.Logger, co
 		}
 		return ctrl.Result{}, err
 	}
+	ref, err := parseImageReference(targetImage)
+	if err != nil {
+		// the image will not parse until the container changes
+		container.Status.MarkImageInvalid(err.Error())
+		return ctrl.Result{}, nil
+	}
+	username, password, err := r.registryCredentials(ctx, container.Namespace, ref.Registry)
+	if err != nil {
+		log.Error(err, "unable to find registry credentials")
+		return ctrl.Result{}, err
+	}
+	repository, err := r.RegistryClient.Repository(ref, username, password)
+	if err != nil {
+		// the registry will not parse until the container changes
+		container.Status.MarkImageInvalid(err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// select a tag from the repository
+	if container.Spec.TagPolicy != nil {
+		candidates, err := r.resolveTagCandidates(ctx, container.Spec.TagPolicy, repository)
+		if err != nil {
+			container.Status.MarkTagSelectionFailed("%s", err)
+			return ctrl.Result{}, err
+		}
+		if len(candidates) == 0 {
+			// keep the last resolved image, wait for a matching tag to be pushed
+			container.Status.TagCandidates = nil
+			container.Status.MarkTagNotFound("no tags in %s match the tag policy", targetImage)
+			return ctrl.Result{RequeueAfter: r.PollInterval}, nil
+		}
+		if len(candidates) > buildv1alpha1.TagCandidatesLimit {
+			candidates = candidates[:buildv1alpha1.TagCandidatesLimit]
+		}
+		if container.Status.LatestTag != candidates[0] {
+			log.Info("selected image tag", "image", targetImage, "tag", candidates[0])
+		}
+		container.Status.TagCandidates = candidates
+		container.Status.LatestTag = candidates[0]
+		ref.Tag = candidates[0]
+		targetImage = ref.String()
+	} else {
+		container.Status.TagCandidates = nil
+		container.Status.LatestTag = ""
+	}
 	container.Status.MarkImageResolved()
 	container.Status.TargetImage = targetImage
 
-	// TODO resolve to a digest
-	container.Status.LatestImage = container.Status.TargetImage
+	// resolve target image to a digest
+	latestImage, err := r.resolveLatestImage(ctx, log, container, ref, repository)
+	if err != nil {
+		// keep the last image resolved for this repository, the registry may
+		// be temporarily unavailable or require credentials only the nodes
+		// have, otherwise fall back to the unresolved image
+		log.Info("unable to resolve image digest", "image", targetImage, "error", err.Error())
+		container.Status.MarkDigestResolutionFailed("%s", err)
+		if !strings.HasPrefix(container.Status.LatestImage, ref.WithDigest("")) {
+			container.Status.LatestImage = targetImage
+		}
+	} else {
+		container.Status.MarkDigestResolved()
+		container.Status.LatestImage = latestImage
+	}
 
 	container.Status.ObservedGeneration = container.Generation
 
-	return ctrl.Result{}, nil
+	// poll the registry for the tag to be pushed again
+	return ctrl.Result{RequeueAfter: r.PollInterval}, nil
+}
+
+func (r *ContainerReconciler) resolveTagCandidates(ctx context.Context, policy *buildv1alpha1.TagPolicy, repository *RegistryRepository) ([]string, error) {
+	tags, err := repository.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return selectTags(ctx, policy, repository, tags)
+}
+
+func (r *ContainerReconciler) resolveLatestImage(ctx context.Context, log logr.Logger, container *buildv1alpha1.Container, ref *imageReference, repository *RegistryRepository) (string, error) {
+	if ref.Digest != "" {
+		// already pinned
+		return container.Status.TargetImage, nil
+	}
+
+	digest, err := repository.Digest(ctx, ref.Tag)
+	if err != nil {
+		return "", err
+	}
+	latestImage := ref.WithDigest(digest)
+	if latestImage != container.Status.LatestImage {
+		log.Info("resolved image digest", "image", container.Status.TargetImage, "digest", digest)
+	}
+	return latestImage, nil
+}
+
+// registryCredentials finds the namespace's build credential for a registry,
+// returning empty credentials when there is none.
+func (r *ContainerReconciler) registryCredentials(ctx context.Context, namespace, registry string) (string, string, error) {
+	var secrets v1.SecretList
+	if err := r.List(ctx, &secrets, client.InNamespace(namespace), MatchingLabels(buildv1alpha1.CredentialLabelKey)); err != nil {
+		return "", "", err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if !isRegistryCredential(secret) {
+			continue
+		}
+		if registryHost(secret.Annotations[kpackbuildv1alpha1.DockerSecretAnnotationKey]) == registryHost(registry) {
+			return string(secret.Data[v1.BasicAuthUsernameKey]), string(secret.Data[v1.BasicAuthPasswordKey]), nil
+		}
+	}
+	return "", "", nil
 }
 
 func (r *ContainerReconciler) resolveTargetImage(ctx context.Context, log logr.Logger, container *buildv1alpha1.Container) (string, error) {
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+)
+
+func TestContainerReconcilerReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = buildv1alpha1.AddToScheme(scheme)
+
+	const digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/projectriff/app/manifests/1.0":
+			w.Header().Set("Docker-Content-Digest", digest)
+		case "/v2/projectriff/app/tags/list":
+			tags := []string{}
+			for i := 0; i <= buildv1alpha1.LatestTagCandidatesLimit; i++ {
+				tags = append(tags, fmt.Sprintf("build-%d", i))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": "projectriff/app", "tags": tags})
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer registry.Close()
+	host := strings.TrimPrefix(registry.URL, "https://")
+
+	for _, c := range []struct {
+		name                   string
+		image                  string
+		tagPolicy              *buildv1alpha1.TagPolicy
+		expectedErr            bool
+		latestImage            string
+		expectedLatestImage    string
+		expectedReady          corev1.ConditionStatus
+		expectedReadyMessage   string
+		expectedDigestResolved corev1.ConditionStatus
+	}{{
+		name:                   "digest resolved",
+		image:                  host + "/projectriff/app:1.0",
+		expectedLatestImage:    host + "/projectriff/app@" + digest,
+		expectedReady:          corev1.ConditionTrue,
+		expectedDigestResolved: corev1.ConditionTrue,
+	}, {
+		name:                   "unresolved digest falls back to the target image",
+		image:                  host + "/projectriff/app:2.0",
+		expectedLatestImage:    host + "/projectriff/app:2.0",
+		expectedReady:          corev1.ConditionTrue,
+		expectedDigestResolved: corev1.ConditionFalse,
+	}, {
+		name:                   "unresolved digest keeps the last resolved image",
+		image:                  host + "/projectriff/app:2.0",
+		latestImage:            host + "/projectriff/app@" + digest,
+		expectedLatestImage:    host + "/projectriff/app@" + digest,
+		expectedReady:          corev1.ConditionTrue,
+		expectedDigestResolved: corev1.ConditionFalse,
+	}, {
+		name:                   "unresolved digest replaces an image from another repository",
+		image:                  host + "/projectriff/app:2.0",
+		latestImage:            host + "/projectriff/other@" + digest,
+		expectedLatestImage:    host + "/projectriff/app:2.0",
+		expectedReady:          corev1.ConditionTrue,
+		expectedDigestResolved: corev1.ConditionFalse,
+	}, {
+		name:  "tag selection failure",
+		image: host + "/projectriff/app",
+		tagPolicy: &buildv1alpha1.TagPolicy{
+			Latest: &buildv1alpha1.LatestTagPolicy{Pattern: "^build-[0-9]+%?$"},
+		},
+		expectedErr:          true,
+		expectedReady:        corev1.ConditionFalse,
+		expectedReadyMessage: `101 tags match pattern "^build-[0-9]+%?$", narrow the pattern to match at most 100 tags`,
+	}, {
+		name:          "invalid image",
+		image:         host + "/projectriff/App:1.0",
+		expectedReady: corev1.ConditionFalse,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			r := &ContainerReconciler{
+				Client:         fake.NewFakeClientWithScheme(scheme),
+				Log:            logf.NullLogger{},
+				Scheme:         scheme,
+				RegistryClient: &RegistryClient{HTTPClient: registry.Client()},
+				PollInterval:   time.Minute,
+			}
+			container := &buildv1alpha1.Container{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-container"},
+				Spec:       buildv1alpha1.ContainerSpec{Image: c.image, TagPolicy: c.tagPolicy},
+			}
+			container.Status.LatestImage = c.latestImage
+			container.Status.InitializeConditions()
+
+			if _, err := r.reconcile(context.Background(), logf.NullLogger{}, container); (err != nil) != c.expectedErr {
+				t.Fatalf("reconcile() expected error %v, got %v", c.expectedErr, err)
+			}
+
+			if container.Status.LatestImage != c.expectedLatestImage {
+				t.Errorf("expected latest image %q, got %q", c.expectedLatestImage, container.Status.LatestImage)
+			}
+			ready := container.Status.GetCondition(buildv1alpha1.ContainerConditionReady)
+			if ready.Status != c.expectedReady {
+				t.Errorf("expected %s condition %s, got %s", buildv1alpha1.ContainerConditionReady, c.expectedReady, ready.Status)
+			}
+			if c.expectedReadyMessage != "" && ready.Message != c.expectedReadyMessage {
+				t.Errorf("expected %s message %q, got %q", buildv1alpha1.ContainerConditionReady, c.expectedReadyMessage, ready.Message)
+			}
+			digestResolved := container.Status.GetCondition(buildv1alpha1.ContainerConditionDigestResolved)
+			if c.expectedDigestResolved == "" && digestResolved != nil {
+				t.Errorf("expected no %s condition, got %s", buildv1alpha1.ContainerConditionDigestResolved, digestResolved.Status)
+			}
+			if c.expectedDigestResolved != "" && (digestResolved == nil || digestResolved.Status != c.expectedDigestResolved) {
+				t.Errorf("expected %s condition %s, got %v", buildv1alpha1.ContainerConditionDigestResolved, c.expectedDigestResolved, digestResolved)
+			}
+		})
+	}
+}
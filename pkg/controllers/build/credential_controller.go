@@ -0,0 +1,317 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const credentialSecretIndexField = ".metadata.credentialController"
+
+var secretGVK = schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+
+// CredentialReconciler reconciles a Credential object
+type CredentialReconciler struct {
+	client.Client
+	Log            logr.Logger
+	Scheme         *runtime.Scheme
+	Tracker        tracker.Tracker
+	RegistryClient *RegistryClient
+}
+
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=credentials,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=credentials/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+func (r *CredentialReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("credential", req.NamespacedName)
+
+	var originalCredential buildv1alpha1.Credential
+	if err := r.Get(ctx, req.NamespacedName, &originalCredential); err != nil {
+		if apierrs.IsNotFound(err) {
+			// we'll ignore not-found errors, since they can't be fixed by an immediate
+			// requeue (we'll need to wait for a new notification), and we can get them
+			// on deleted requests.
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to fetch Credential")
+		return ctrl.Result{}, err
+	}
+	credential := *(originalCredential.DeepCopy())
+
+	credential.Default()
+	credential.Status.InitializeConditions()
+
+	result, err := r.reconcile(ctx, log, &credential)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(credential.Status, originalCredential.Status) {
+		// update status
+		log.Info("updating credential status", "diff", cmp.Diff(originalCredential.Status, credential.Status))
+		if updateErr := r.Status().Update(ctx, &credential); updateErr != nil {
+			log.Error(updateErr, "unable to update Credential status", "credential", credential)
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+
+	// return original reconcile result
+	return result, err
+}
+
+func (r *CredentialReconciler) reconcile(ctx context.Context, log logr.Logger, credential *buildv1alpha1.Credential) (ctrl.Result, error) {
+	if credential.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// resolve the sensitive value from the referenced secret
+	value, err := r.resolveSecretValue(ctx, credential)
+	if err != nil {
+		log.Error(err, "unable to resolve secret for Credential", "credential", credential)
+		return ctrl.Result{}, err
+	}
+	if value == nil {
+		// status was marked
+		return ctrl.Result{}, nil
+	}
+
+	// reconcile child secret
+	childSecret, err := r.reconcileChildSecret(ctx, log, credential, value)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Secret", "credential", credential)
+		return ctrl.Result{}, err
+	}
+	credential.Status.SecretRef = refs.NewTypedLocalObjectReferenceForObject(childSecret, r.Scheme)
+	credential.Status.MarkSecretReady()
+
+	// verify the credential with the registry, when requested
+	if dockerRegistry := credential.Spec.DockerRegistry; dockerRegistry != nil && dockerRegistry.VerifyLogin {
+		if err := r.RegistryClient.CheckLogin(ctx, dockerRegistry.Server, dockerRegistry.Username, string(value)); err != nil {
+			credential.Status.MarkLoginFailed("%s", err)
+		} else {
+			credential.Status.MarkLoginVerified()
+		}
+	} else {
+		credential.Status.MarkLoginNotRequested()
+	}
+
+	credential.Status.ObservedGeneration = credential.Generation
+
+	return ctrl.Result{}, nil
+}
+
+// resolveSecretValue returns the password or private key for the credential.
+// A nil value is returned when the secret or key is missing.
+func (r *CredentialReconciler) resolveSecretValue(ctx context.Context, credential *buildv1alpha1.Credential) ([]byte, error) {
+	var selector corev1.SecretKeySelector
+	switch {
+	case credential.Spec.DockerRegistry != nil:
+		selector = credential.Spec.DockerRegistry.PasswordRef
+	case credential.Spec.GitBasicAuth != nil:
+		selector = credential.Spec.GitBasicAuth.PasswordRef
+	case credential.Spec.GitSSH != nil:
+		selector = credential.Spec.GitSSH.PrivateKeyRef
+	default:
+		return nil, fmt.Errorf("unknown credential type")
+	}
+
+	secretKey := types.NamespacedName{Namespace: credential.Namespace, Name: selector.Name}
+	// track secret for credential changes
+	r.Tracker.Track(
+		tracker.NewKey(secretGVK, secretKey),
+		types.NamespacedName{Namespace: credential.Namespace, Name: credential.Name},
+	)
+	var secret corev1.Secret
+	if err := r.Get(ctx, secretKey, &secret); err != nil {
+		if apierrs.IsNotFound(err) {
+			credential.Status.MarkSecretNotFound(fmt.Sprintf("secret %q not found", selector.Name))
+			return nil, nil
+		}
+		return nil, err
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		credential.Status.MarkSecretKeyMissing(selector.Name, selector.Key)
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (r *CredentialReconciler) reconcileChildSecret(ctx context.Context, log logr.Logger, credential *buildv1alpha1.Credential, value []byte) (*corev1.Secret, error) {
+	var actualSecret corev1.Secret
+	var childSecrets corev1.SecretList
+	if err := r.List(ctx, &childSecrets, client.InNamespace(credential.Namespace), client.MatchingField(credentialSecretIndexField, credential.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childSecrets.Items) == 1 {
+		actualSecret = childSecrets.Items[0]
+	} else if len(childSecrets.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraSecret := range childSecrets.Items {
+			log.Info("deleting extra secret", "secret", extraSecret.Name)
+			if err := r.Delete(ctx, &extraSecret); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredSecret, err := r.constructSecretForCredential(credential, value)
+	if err != nil {
+		return nil, err
+	}
+
+	if actualSecret.Name != "" && actualSecret.Type != desiredSecret.Type {
+		// the type of a secret is immutable, replace it
+		log.Info("deleting secret to change type", "secret", actualSecret.Name)
+		if err := r.Delete(ctx, &actualSecret); err != nil {
+			log.Error(err, "unable to delete Secret for Credential", "secret", actualSecret.Name)
+			return nil, err
+		}
+		actualSecret = corev1.Secret{}
+	}
+
+	// create secret if it doesn't exist
+	if actualSecret.Name == "" {
+		log.Info("creating secret", "name", desiredSecret.GenerateName)
+		if err := r.Create(ctx, desiredSecret); err != nil {
+			log.Error(err, "unable to create Secret for Credential", "secret", desiredSecret.GenerateName)
+			return nil, err
+		}
+		return desiredSecret, nil
+	}
+
+	if r.secretSemanticEquals(desiredSecret, &actualSecret) {
+		// secret is unchanged
+		return &actualSecret, nil
+	}
+
+	// update secret with desired changes
+	secret := actualSecret.DeepCopy()
+	secret.ObjectMeta.Labels = desiredSecret.ObjectMeta.Labels
+	secret.ObjectMeta.Annotations = desiredSecret.ObjectMeta.Annotations
+	secret.Data = desiredSecret.Data
+	// avoid logging the secret's data
+	log.Info("reconciling secret", "diff", cmp.Diff(actualSecret.ObjectMeta.Annotations, secret.ObjectMeta.Annotations))
+	if err := r.Update(ctx, secret); err != nil {
+		log.Error(err, "unable to update Secret for Credential", "secret", secret.Name)
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func (r *CredentialReconciler) secretSemanticEquals(desiredSecret, secret *corev1.Secret) bool {
+	return equality.Semantic.DeepEqual(desiredSecret.Data, secret.Data) &&
+		equality.Semantic.DeepEqual(desiredSecret.ObjectMeta.Labels, secret.ObjectMeta.Labels) &&
+		equality.Semantic.DeepEqual(desiredSecret.ObjectMeta.Annotations, secret.ObjectMeta.Annotations)
+}
+
+func (r *CredentialReconciler) constructSecretForCredential(credential *buildv1alpha1.Credential, value []byte) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       r.constructLabelsForCredential(credential),
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-credential-", credential.Name),
+			Namespace:    credential.Namespace,
+		},
+	}
+
+	// annotate the secret as kpack expects
+	switch {
+	case credential.Spec.DockerRegistry != nil:
+		secret.Type = corev1.SecretTypeBasicAuth
+		secret.Annotations[kpackbuildv1alpha1.DockerSecretAnnotationKey] = credential.Spec.DockerRegistry.Server
+		secret.Data = map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(credential.Spec.DockerRegistry.Username),
+			corev1.BasicAuthPasswordKey: value,
+		}
+	case credential.Spec.GitBasicAuth != nil:
+		secret.Type = corev1.SecretTypeBasicAuth
+		secret.Annotations[kpackbuildv1alpha1.GitSecretAnnotationKey] = credential.Spec.GitBasicAuth.URL
+		secret.Data = map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(credential.Spec.GitBasicAuth.Username),
+			corev1.BasicAuthPasswordKey: value,
+		}
+	case credential.Spec.GitSSH != nil:
+		secret.Type = corev1.SecretTypeSSHAuth
+		secret.Annotations[kpackbuildv1alpha1.GitSecretAnnotationKey] = credential.Spec.GitSSH.URL
+		secret.Data = map[string][]byte{
+			corev1.SSHAuthPrivateKey: value,
+		}
+		if credential.Spec.GitSSH.KnownHosts != "" {
+			secret.Data["known_hosts"] = []byte(credential.Spec.GitSSH.KnownHosts)
+		}
+	}
+
+	if len(credential.Spec.ServiceAccounts) != 0 {
+		secret.Annotations[buildv1alpha1.ServiceAccountsAnnotationKey] = strings.Join(credential.Spec.ServiceAccounts, ",")
+	}
+
+	if err := ctrl.SetControllerReference(credential, secret, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func (r *CredentialReconciler) constructLabelsForCredential(credential *buildv1alpha1.Credential) map[string]string {
+	labels := make(map[string]string, len(credential.ObjectMeta.Labels)+1)
+	// pass through existing labels
+	for k, v := range credential.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	// the label binds the secret to build service accounts
+	labels[buildv1alpha1.CredentialLabelKey] = credential.Name
+
+	return labels
+}
+
+func (r *CredentialReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := controllers.IndexControllersOfType(mgr, credentialSecretIndexField, &buildv1alpha1.Credential{}, &corev1.Secret{}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&buildv1alpha1.Credential{}).
+		Owns(&corev1.Secret{}).
+		// watch for changes to the referenced secrets
+		Watches(&source.Kind{Type: &corev1.Secret{}}, enqueueTrackedResources(r.Tracker, secretGVK)).
+		Complete(r)
+}
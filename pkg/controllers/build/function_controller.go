@@ -33,11 +33,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
 	"github.com/projectriff/system/pkg/controllers"
 	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
 )
 
 const functionIndexField = ".metadata.functionController"
@@ -45,13 +47,17 @@ const functionIndexField = ".metadata.functionController"
 // FunctionReconciler reconciles a Function object
 type FunctionReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
 }
 
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=functions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=functions/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=build.pivotal.io,resources=images,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=build.pivotal.io,resources=builds,verbs=get;list;watch
+// +kubebuilder:rbac:groups=build.pivotal.io,resources=builders,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 
 func (r *FunctionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -108,8 +114,23 @@ func (r *FunctionReconciler) reconcile(ctx context.Context, log logr.Logger, fun
 	function.Status.MarkImageResolved()
 	function.Status.TargetImage = targetImage
 
+	// resolve builder
+	var builder *kpackbuildv1alpha1.ImageBuilder
+	var builderImage string
+	if function.Spec.Source != nil {
+		builder, builderImage, err = resolveBuilder(ctx, r.Client, r.Tracker, r.Namespace, types.NamespacedName{Namespace: function.Namespace, Name: function.Name}, function.Spec.Builder, "riff-function")
+		if err != nil {
+			if _, ok := err.(*builderNotFoundError); ok {
+				function.Status.MarkBuilderNotFound(err.Error())
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	function.Status.MarkBuilderResolved()
+
 	// reconcile child kpack image
-	childImage, err := r.reconcileChildKpackImage(ctx, log, function)
+	childImage, err := r.reconcileChildKpackImage(ctx, log, function, builder, builderImage)
 	if err != nil {
 		log.Error(err, "unable to reconcile child Image", "function", function)
 		return ctrl.Result{}, err
@@ -122,6 +143,14 @@ func (r *FunctionReconciler) reconcile(ctx context.Context, log logr.Logger, fun
 		function.Status.KpackImageRef = refs.NewTypedLocalObjectReferenceForObject(childImage, r.Scheme)
 		function.Status.LatestImage = childImage.Status.LatestImage
 		function.Status.BuildCacheRef = refs.NewTypedLocalObjectReference(childImage.Status.BuildCacheName, schema.GroupKind{Kind: "PersistentVolumeClaim"})
+
+		builds, err := listKpackBuilds(ctx, r.Client, childImage)
+		if err != nil {
+			log.Error(err, "unable to list kpack Builds", "image", childImage.Name)
+			return ctrl.Result{}, err
+		}
+		function.Status.PropagateKpackBuilds(builds)
+		function.Status.PropagateBuilderImage(builderImage)
 		function.Status.PropagateKpackImageStatus(&childImage.Status)
 	}
 
@@ -153,7 +182,7 @@ func (r *FunctionReconciler) resolveTargetImage(ctx context.Context, log logr.Lo
 	return image, nil
 }
 
-func (r *FunctionReconciler) reconcileChildKpackImage(ctx context.Context, log logr.Logger, function *buildv1alpha1.Function) (*kpackbuildv1alpha1.Image, error) {
+func (r *FunctionReconciler) reconcileChildKpackImage(ctx context.Context, log logr.Logger, function *buildv1alpha1.Function, builder *kpackbuildv1alpha1.ImageBuilder, builderImage string) (*kpackbuildv1alpha1.Image, error) {
 	var actualImage kpackbuildv1alpha1.Image
 	var childImages kpackbuildv1alpha1.ImageList
 	if err := r.List(ctx, &childImages, client.InNamespace(function.Namespace), client.MatchingField(functionIndexField, function.Name)); err != nil {
@@ -172,7 +201,7 @@ func (r *FunctionReconciler) reconcileChildKpackImage(ctx context.Context, log l
 		}
 	}
 
-	desiredImage, err := r.constructImageForFunction(function)
+	desiredImage, err := r.constructImageForFunction(function, builder, builderImage)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +251,7 @@ func (r *FunctionReconciler) imageSemanticEquals(desiredImage, image *kpackbuild
 		equality.Semantic.DeepEqual(desiredImage.ObjectMeta.Labels, image.ObjectMeta.Labels)
 }
 
-func (r *FunctionReconciler) constructImageForFunction(function *buildv1alpha1.Function) (*kpackbuildv1alpha1.Image, error) {
+func (r *FunctionReconciler) constructImageForFunction(function *buildv1alpha1.Function, builder *kpackbuildv1alpha1.ImageBuilder, builderImage string) (*kpackbuildv1alpha1.Image, error) {
 	if function.Spec.Source == nil {
 		return nil, nil
 	}
@@ -235,14 +264,9 @@ func (r *FunctionReconciler) constructImageForFunction(function *buildv1alpha1.F
 			Namespace:    function.Namespace,
 		},
 		Spec: kpackbuildv1alpha1.ImageSpec{
-			Tag: function.Status.TargetImage,
-			Builder: kpackbuildv1alpha1.ImageBuilder{
-				TypeMeta: metav1.TypeMeta{
-					Kind: "ClusterBuilder",
-				},
-				Name: "riff-function",
-			},
-			ServiceAccount:           "riff-build",
+			Tag:                      function.Status.TargetImage,
+			Builder:                  *builder,
+			ServiceAccount:           buildServiceAccountName(function.Spec.ServiceAccountName),
 			Source:                   *function.Spec.Source,
 			CacheSize:                function.Spec.CacheSize,
 			FailedBuildHistoryLimit:  function.Spec.FailedBuildHistoryLimit,
@@ -257,6 +281,20 @@ func (r *FunctionReconciler) constructImageForFunction(function *buildv1alpha1.F
 		corev1.EnvVar{Name: "RIFF_HANDLER", Value: function.Spec.Handler},
 		corev1.EnvVar{Name: "RIFF_OVERRIDE", Value: function.Spec.Invoker},
 	)
+	if trigger := function.Status.LatestTrigger; trigger.AppliesTo(function.Spec.Source) {
+		// changing build configuration triggers a kpack build
+		image.Spec.Build.Env = append(image.Spec.Build.Env, corev1.EnvVar{Name: gitCommitEnvVar, Value: trigger.Commit})
+	}
+	if function.Spec.RebuildPolicy == buildv1alpha1.BuilderUpdateRebuildPolicy {
+		if builderImage == "" {
+			// hold the last value while the builder is not ready, avoiding a rebuild
+			builderImage = function.Status.LatestBuilderImage
+		}
+		if builderImage != "" {
+			// changing build configuration triggers a kpack build
+			image.Spec.Build.Env = append(image.Spec.Build.Env, corev1.EnvVar{Name: builderImageEnvVar, Value: builderImage})
+		}
+	}
 	if err := ctrl.SetControllerReference(function, image, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -284,5 +322,11 @@ func (r *FunctionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&buildv1alpha1.Function{}).
 		Owns(&kpackbuildv1alpha1.Image{}).
+		// track the builders catalog and namespaced builders
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources(r.Tracker, configMapGVK)).
+		Watches(&source.Kind{Type: &kpackbuildv1alpha1.Builder{}}, enqueueTrackedResources(r.Tracker, kpackBuilderGVK)).
+		// builds are owned by the image, map them back through the labels
+		// inherited from the image
+		Watches(&source.Kind{Type: &kpackbuildv1alpha1.Build{}}, enqueueForKpackBuild(buildv1alpha1.FunctionLabelKey)).
 		Complete(r)
 }
@@ -0,0 +1,366 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+)
+
+const (
+	// GitWebhookSecretName is the Secret in the system namespace holding the
+	// shared secret git hosts sign push payloads with.
+	GitWebhookSecretName = "riff-build-git-webhook"
+	// GitWebhookSecretKey is the key within the Secret for the shared secret.
+	GitWebhookSecretKey = "secret"
+
+	maxGitWebhookPayloadSize = 5 * 1024 * 1024
+)
+
+// GitWebhookHandler receives push events from GitHub, GitLab and Gitea,
+// triggering builds for Applications and Functions whose source is the pushed
+// repository and branch.
+type GitWebhookHandler struct {
+	client.Client
+	Log       logr.Logger
+	Namespace string
+}
+
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=applications,verbs=get;list;watch
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=applications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=functions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=functions/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+func (h *GitWebhookHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := context.Background()
+	log := h.Log.WithValues("remote", req.RemoteAddr)
+
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxGitWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, "unable to read payload", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := h.resolveSecret(ctx)
+	if err != nil {
+		log.Error(err, "unable to resolve git webhook secret")
+		http.Error(w, "git webhooks are not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	event, err := verifyGitWebhook(req.Header, body, secret)
+	if err != nil {
+		log.Info("rejecting git webhook", "reason", err.Error())
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if event != "push" {
+		// pings and other events are acknowledged, but ignored
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var push gitPushEvent
+	if err := json.Unmarshal(body, &push); err != nil {
+		http.Error(w, "malformed push payload", http.StatusBadRequest)
+		return
+	}
+	branch := push.branch()
+	commit := push.commit()
+	if branch == "" || commit == "" {
+		// tags and deleted branches are not built
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	log = log.WithValues("repository", push.Repository.urls(), "branch", branch, "commit", commit)
+	triggered, err := h.trigger(ctx, log, push.Repository.urls(), branch, commit)
+	if err != nil {
+		log.Error(err, "unable to trigger builds")
+		http.Error(w, "unable to trigger builds", http.StatusInternalServerError)
+		return
+	}
+	log.Info("triggered builds", "resources", triggered)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string][]string{"triggered": triggered})
+}
+
+func (h *GitWebhookHandler) resolveSecret(ctx context.Context) ([]byte, error) {
+	var secret corev1.Secret
+	if err := h.Get(ctx, types.NamespacedName{Namespace: h.Namespace, Name: GitWebhookSecretName}, &secret); err != nil {
+		return nil, err
+	}
+	value := secret.Data[GitWebhookSecretKey]
+	if len(value) == 0 {
+		return nil, fmt.Errorf("secret %q is missing key %q", GitWebhookSecretName, GitWebhookSecretKey)
+	}
+	return value, nil
+}
+
+// trigger records the pushed commit on each matching Application and Function,
+// returning the namespaced names of the triggered resources.
+func (h *GitWebhookHandler) trigger(ctx context.Context, log logr.Logger, repositories []string, branch, commit string) ([]string, error) {
+	triggered := []string{}
+	now := metav1.Now()
+
+	var applications buildv1alpha1.ApplicationList
+	if err := h.List(ctx, &applications); err != nil {
+		return nil, err
+	}
+	for i := range applications.Items {
+		application := &applications.Items[i]
+		if !gitSourceMatches(application.Spec.Source, repositories, branch) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: application.Namespace, Name: application.Name}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := h.Get(ctx, key, application); err != nil {
+				return err
+			}
+			application.Status.LatestTrigger = newBuildTrigger(application.Spec.Source, commit, now)
+			return h.Status().Update(ctx, application)
+		})
+		if err != nil {
+			if apierrs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		triggered = append(triggered, fmt.Sprintf("application/%s", key))
+	}
+
+	var functions buildv1alpha1.FunctionList
+	if err := h.List(ctx, &functions); err != nil {
+		return nil, err
+	}
+	for i := range functions.Items {
+		function := &functions.Items[i]
+		if !gitSourceMatches(function.Spec.Source, repositories, branch) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: function.Namespace, Name: function.Name}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := h.Get(ctx, key, function); err != nil {
+				return err
+			}
+			function.Status.LatestTrigger = newBuildTrigger(function.Spec.Source, commit, now)
+			return h.Status().Update(ctx, function)
+		})
+		if err != nil {
+			if apierrs.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		triggered = append(triggered, fmt.Sprintf("function/%s", key))
+	}
+
+	return triggered, nil
+}
+
+func newBuildTrigger(source *buildv1alpha1.Source, commit string, now metav1.Time) *buildv1alpha1.BuildTrigger {
+	return &buildv1alpha1.BuildTrigger{
+		Repository: source.Git.URL,
+		Branch:     source.Git.Revision,
+		Commit:     commit,
+		Time:       now,
+	}
+}
+
+func gitSourceMatches(source *buildv1alpha1.Source, repositories []string, branch string) bool {
+	if source == nil || source.Git == nil || source.Git.Revision != branch {
+		return false
+	}
+	sourceURL := normalizeGitURL(source.Git.URL)
+	for _, repository := range repositories {
+		if sourceURL == normalizeGitURL(repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeGitURL reduces the http, ssh and scp-like forms of a repository url
+// to a common host and path.
+func normalizeGitURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		// scp-like syntax, git@example.com:org/repo.git
+		if i := strings.Index(raw, ":"); i > 0 {
+			raw = "ssh://" + raw[:i] + "/" + raw[i+1:]
+		}
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(raw)
+	}
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	return strings.ToLower(u.Hostname()) + "/" + path
+}
+
+// verifyGitWebhook authenticates the payload against the shared secret using
+// the scheme of the git host that sent it, returning the normalized event type.
+func verifyGitWebhook(header http.Header, body, secret []byte) (string, error) {
+	switch {
+	case header.Get("X-Gitea-Event") != "":
+		if !validHMAC(sha256.New, secret, body, header.Get("X-Gitea-Signature")) {
+			return "", fmt.Errorf("invalid gitea signature")
+		}
+		return header.Get("X-Gitea-Event"), nil
+	case header.Get("X-Gitlab-Event") != "":
+		// gitlab sends the secret token rather than a signature
+		if subtle.ConstantTimeCompare([]byte(header.Get("X-Gitlab-Token")), secret) != 1 {
+			return "", fmt.Errorf("invalid gitlab token")
+		}
+		if header.Get("X-Gitlab-Event") == "Push Hook" {
+			return "push", nil
+		}
+		return header.Get("X-Gitlab-Event"), nil
+	case header.Get("X-GitHub-Event") != "":
+		if signature := header.Get("X-Hub-Signature-256"); signature != "" {
+			if !strings.HasPrefix(signature, "sha256=") || !validHMAC(sha256.New, secret, body, strings.TrimPrefix(signature, "sha256=")) {
+				return "", fmt.Errorf("invalid github signature")
+			}
+		} else if signature := header.Get("X-Hub-Signature"); strings.HasPrefix(signature, "sha1=") {
+			if !validHMAC(sha1.New, secret, body, strings.TrimPrefix(signature, "sha1=")) {
+				return "", fmt.Errorf("invalid github signature")
+			}
+		} else {
+			return "", fmt.Errorf("missing github signature")
+		}
+		return header.Get("X-GitHub-Event"), nil
+	}
+	return "", fmt.Errorf("unknown git webhook sender")
+}
+
+func validHMAC(h func() hash.Hash, secret, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(h, secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// gitPushEvent is the subset of a push payload common to GitHub, GitLab and
+// Gitea.
+type gitPushEvent struct {
+	Ref         string            `json:"ref"`
+	After       string            `json:"after"`
+	CheckoutSHA string            `json:"checkout_sha"`
+	Repository  gitPushRepository `json:"repository"`
+}
+
+type gitPushRepository struct {
+	// GitHub and Gitea
+	CloneURL string `json:"clone_url"`
+	HTMLURL  string `json:"html_url"`
+	SSHURL   string `json:"ssh_url"`
+	// GitLab
+	GitHTTPURL string `json:"git_http_url"`
+	GitSSHURL  string `json:"git_ssh_url"`
+}
+
+func (e *gitPushEvent) branch() string {
+	if !strings.HasPrefix(e.Ref, "refs/heads/") {
+		return ""
+	}
+	return strings.TrimPrefix(e.Ref, "refs/heads/")
+}
+
+func (e *gitPushEvent) commit() string {
+	commit := e.After
+	if e.CheckoutSHA != "" {
+		commit = e.CheckoutSHA
+	}
+	if strings.Trim(commit, "0") == "" {
+		// the branch was deleted
+		return ""
+	}
+	return commit
+}
+
+func (r *gitPushRepository) urls() []string {
+	urls := []string{}
+	for _, u := range []string{r.CloneURL, r.HTMLURL, r.SSHURL, r.GitHTTPURL, r.GitSSHURL} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// GitWebhookServer serves a git webhook handler for the lifetime of the
+// manager. Every replica serves requests, regardless of leader election.
+type GitWebhookServer struct {
+	Addr    string
+	Handler http.Handler
+}
+
+func (s *GitWebhookServer) Start(stop <-chan struct{}) error {
+	server := &http.Server{Addr: s.Addr, Handler: s.Handler}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(ctx)
+	}
+}
+
+func (s *GitWebhookServer) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,496 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/cache"
+)
+
+// manifestMediaTypes are the manifest formats accepted from registries.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// RegistryClient talks to docker registries using the v2 registry api.
+type RegistryClient struct {
+	HTTPClient *http.Client
+
+	// created caches image creation times by digest, images are immutable
+	created     *cache.LRUExpireCache
+	createdOnce sync.Once
+}
+
+const (
+	createdCacheSize = 10000
+	createdCacheTTL  = 24 * time.Hour
+)
+
+func (c *RegistryClient) createdCache() *cache.LRUExpireCache {
+	c.createdOnce.Do(func() {
+		c.created = cache.NewLRUExpireCache(createdCacheSize)
+	})
+	return c.created
+}
+
+// CheckLogin authenticates against the registry, returning an error if the
+// registry rejects the credentials.
+func (c *RegistryClient) CheckLogin(ctx context.Context, server, username, password string) error {
+	endpoint, err := registryEndpoint(server)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.get(ctx, endpoint+"/v2/", "", "")
+	if err != nil {
+		return err
+	}
+	if res.StatusCode == http.StatusOK {
+		// anonymous access, nothing to verify
+		return nil
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("unexpected response from registry %s: %s", server, res.Status)
+	}
+
+	scheme, params := parseAuthenticateHeader(res.Header.Get("WWW-Authenticate"))
+	switch strings.ToLower(scheme) {
+	case "basic":
+		res, err = c.get(ctx, endpoint+"/v2/", username, password)
+	case "bearer":
+		realm, parseErr := url.Parse(params["realm"])
+		if parseErr != nil || realm.Host == "" {
+			return fmt.Errorf("invalid token realm %q for registry %s", params["realm"], server)
+		}
+		query := realm.Query()
+		if service, ok := params["service"]; ok {
+			query.Set("service", service)
+		}
+		realm.RawQuery = query.Encode()
+		res, err = c.get(ctx, realm.String(), username, password)
+	default:
+		return fmt.Errorf("unsupported authentication scheme %q for registry %s", scheme, server)
+	}
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("login to registry %s failed: %s", server, res.Status)
+	}
+	return nil
+}
+
+// Repository opens an image repository for reading. The credentials are used
+// when the registry requires authentication, anonymous access is attempted
+// when they are empty.
+func (c *RegistryClient) Repository(ref *imageReference, username, password string) (*RegistryRepository, error) {
+	endpoint, err := registryEndpoint(ref.Registry)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryRepository{
+		client:   c,
+		ref:      ref,
+		endpoint: endpoint,
+		username: username,
+		password: password,
+	}, nil
+}
+
+// RegistryRepository reads the tags and manifests of an image repository.
+type RegistryRepository struct {
+	client   *RegistryClient
+	ref      *imageReference
+	endpoint string
+	username string
+	password string
+	// authorization is reused across requests once the registry challenges
+	authorization string
+}
+
+// Digest returns the digest of the manifest a tag currently points to.
+func (r *RegistryRepository) Digest(ctx context.Context, tag string) (string, error) {
+	res, err := r.do(ctx, http.MethodHead, fmt.Sprintf("/v2/%s/manifests/%s", r.ref.Repository, tag), manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to resolve image %s:%s: %s", r.ref.name, tag, res.Status)
+	}
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s did not return a digest for image %s:%s", r.ref.Registry, r.ref.name, tag)
+	}
+	return digest, nil
+}
+
+// Tags lists the tags in the repository.
+func (r *RegistryRepository) Tags(ctx context.Context) ([]string, error) {
+	tags := []string{}
+	path := fmt.Sprintf("/v2/%s/tags/list", r.ref.Repository)
+	for path != "" {
+		res, err := r.do(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = r.decode(res, &body)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, body.Tags...)
+		path = nextLink(res.Header.Get("Link"))
+	}
+	return tags, nil
+}
+
+// Created returns the time the image a tag points to was created. For multi
+// platform images the creation time of the first platform's image is used.
+// Only the tag's digest is resolved for images seen before.
+func (r *RegistryRepository) Created(ctx context.Context, tag string) (time.Time, error) {
+	digest, err := r.Digest(ctx, tag)
+	if err != nil {
+		return time.Time{}, err
+	}
+	key := fmt.Sprintf("%s/%s@%s", r.ref.Registry, r.ref.Repository, digest)
+	if created, ok := r.client.createdCache().Get(key); ok {
+		return created.(time.Time), nil
+	}
+
+	var manifest struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Manifests []struct {
+			Digest string `json:"digest"`
+		} `json:"manifests"`
+	}
+	reference := digest
+	for {
+		res, err := r.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", r.ref.Repository, reference), manifestMediaTypes)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if err := r.decode(res, &manifest); err != nil {
+			return time.Time{}, err
+		}
+		if len(manifest.Manifests) == 0 || manifest.Config.Digest != "" {
+			break
+		}
+		reference = manifest.Manifests[0].Digest
+		manifest.Manifests = nil
+	}
+	if manifest.Config.Digest == "" {
+		return time.Time{}, fmt.Errorf("image %s:%s has no config", r.ref.name, tag)
+	}
+
+	res, err := r.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", r.ref.Repository, manifest.Config.Digest), nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var config struct {
+		Created time.Time `json:"created"`
+	}
+	if err := r.decode(res, &config); err != nil {
+		return time.Time{}, err
+	}
+	r.client.createdCache().Add(key, config.Created, createdCacheTTL)
+	return config.Created, nil
+}
+
+// do sends a request to the registry, authenticating when challenged. The
+// caller must close the response body.
+func (r *RegistryRepository) do(ctx context.Context, method, path string, accept []string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := r.client.newRequest(ctx, method, r.endpoint+path, "", "")
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) != 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		if r.authorization != "" {
+			req.Header.Set("Authorization", r.authorization)
+		}
+		return r.client.HTTPClient.Do(req)
+	}
+
+	res, err := send()
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	res.Body.Close()
+
+	scheme, params := parseAuthenticateHeader(res.Header.Get("WWW-Authenticate"))
+	switch strings.ToLower(scheme) {
+	case "basic":
+		r.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(r.username+":"+r.password))
+	case "bearer":
+		token, err := r.client.token(ctx, params, fmt.Sprintf("repository:%s:pull", r.ref.Repository), r.username, r.password)
+		if err != nil {
+			return nil, err
+		}
+		r.authorization = "Bearer " + token
+	default:
+		return nil, fmt.Errorf("unsupported authentication scheme %q for registry %s", scheme, r.ref.Registry)
+	}
+	return send()
+}
+
+// decode reads a successful json response, closing the response body.
+func (r *RegistryRepository) decode(res *http.Response, v interface{}) error {
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from registry %s for %s: %s", r.ref.Registry, res.Request.URL.Path, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// token requests a bearer token from the realm of an authentication challenge.
+func (c *RegistryClient) token(ctx context.Context, params map[string]string, scope, username, password string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := c.newRequest(ctx, http.MethodGet, realm.String(), username, password)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s failed: %s", realm.Host, res.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token request to %s returned no token", realm.Host)
+}
+
+func (c *RegistryClient) get(ctx context.Context, url, username, password string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, url, username, password)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return res, nil
+}
+
+func (c *RegistryClient) newRequest(ctx context.Context, method, url, username, password string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	return req, nil
+}
+
+// registryEndpoint resolves the base url of the v2 api for a registry server
+// as it appears in docker config files.
+func registryEndpoint(server string) (string, error) {
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid registry server %q", server)
+	}
+	host := u.Host
+	if isDockerHub(host) {
+		// docker hub's api is served from a different host than its index
+		host = "registry-1.docker.io"
+	}
+	return fmt.Sprintf("%s://%s", u.Scheme, host), nil
+}
+
+// registryHost returns the host of a registry server as it appears in docker
+// config files.
+func registryHost(server string) string {
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return ""
+	}
+	if isDockerHub(u.Host) {
+		return dockerHubRegistry
+	}
+	return u.Host
+}
+
+func isDockerHub(host string) bool {
+	return host == dockerHubRegistry || host == "index.docker.io" || host == "registry-1.docker.io"
+}
+
+const dockerHubRegistry = "docker.io"
+
+// imageReference is a parsed image name.
+type imageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+	// name is the image name without tag or digest, as it was written
+	name string
+}
+
+// parseImageReference splits an image name into its registry, repository and
+// tag or digest, applying docker's defaults for each.
+func parseImageReference(image string) (*imageReference, error) {
+	ref := &imageReference{}
+	name := image
+	if i := strings.Index(name, "@"); i != -1 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i != -1 && !strings.Contains(name[i+1:], "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+	ref.name = name
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = dockerHubRegistry
+		ref.Repository = name
+	}
+	if isDockerHub(ref.Registry) {
+		ref.Registry = dockerHubRegistry
+		if !strings.Contains(ref.Repository, "/") {
+			ref.Repository = "library/" + ref.Repository
+		}
+	}
+	if ref.Repository == "" || ref.Repository != strings.ToLower(ref.Repository) {
+		return nil, fmt.Errorf("invalid image %q", image)
+	}
+	return ref, nil
+}
+
+// WithDigest returns the image name pinned to the digest.
+func (r *imageReference) WithDigest(digest string) string {
+	return fmt.Sprintf("%s@%s", r.name, digest)
+}
+
+func (r *imageReference) String() string {
+	if r.Digest != "" {
+		return r.WithDigest(r.Digest)
+	}
+	return fmt.Sprintf("%s:%s", r.name, r.Tag)
+}
+
+// nextLink returns the path of the next page from a Link header, or empty
+// when there are no more pages.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 || !strings.Contains(strings.Join(parts[1:], ";"), `rel="next"`) {
+			continue
+		}
+		next, err := url.Parse(strings.Trim(strings.TrimSpace(parts[0]), "<>"))
+		if err != nil {
+			return ""
+		}
+		return next.RequestURI()
+	}
+	return ""
+}
+
+// parseAuthenticateHeader splits a WWW-Authenticate challenge into its scheme
+// and parameters.
+func parseAuthenticateHeader(header string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) == 2 {
+		for _, param := range splitAuthenticateParams(parts[1]) {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 {
+				params[strings.ToLower(kv[0])] = strings.Trim(kv[1], `"`)
+			}
+		}
+	}
+	return parts[0], params
+}
+
+// splitAuthenticateParams splits challenge parameters on commas outside of
+// quoted values, as a scope may list several comma separated actions.
+func splitAuthenticateParams(params string) []string {
+	split := []string{}
+	quoted := false
+	start := 0
+	for i, c := range params {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			split = append(split, params[start:i])
+			start = i + 1
+		}
+	}
+	return append(split, params[start:])
+}
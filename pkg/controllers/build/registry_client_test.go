@@ -0,0 +1,484 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseImageReference(t *testing.T) {
+	for _, c := range []struct {
+		name        string
+		image       string
+		expected    *imageReference
+		expectedErr bool
+	}{{
+		name:  "official image",
+		image: "ubuntu",
+		expected: &imageReference{
+			Registry:   "docker.io",
+			Repository: "library/ubuntu",
+			Tag:        "latest",
+			name:       "ubuntu",
+		},
+	}, {
+		name:  "docker hub image with tag",
+		image: "projectriff/system:v1.0.0",
+		expected: &imageReference{
+			Registry:   "docker.io",
+			Repository: "projectriff/system",
+			Tag:        "v1.0.0",
+			name:       "projectriff/system",
+		},
+	}, {
+		name:  "docker hub index host",
+		image: "index.docker.io/ubuntu:20.04",
+		expected: &imageReference{
+			Registry:   "docker.io",
+			Repository: "library/ubuntu",
+			Tag:        "20.04",
+			name:       "index.docker.io/ubuntu",
+		},
+	}, {
+		name:  "registry with nested repository",
+		image: "gcr.io/projectriff/system/controller:latest",
+		expected: &imageReference{
+			Registry:   "gcr.io",
+			Repository: "projectriff/system/controller",
+			Tag:        "latest",
+			name:       "gcr.io/projectriff/system/controller",
+		},
+	}, {
+		name:  "registry with port and no tag",
+		image: "registry.example.com:5000/app",
+		expected: &imageReference{
+			Registry:   "registry.example.com:5000",
+			Repository: "app",
+			Tag:        "latest",
+			name:       "registry.example.com:5000/app",
+		},
+	}, {
+		name:  "localhost registry",
+		image: "localhost/app:dev",
+		expected: &imageReference{
+			Registry:   "localhost",
+			Repository: "app",
+			Tag:        "dev",
+			name:       "localhost/app",
+		},
+	}, {
+		name:  "digest",
+		image: "gcr.io/projectriff/app@sha256:0123456789abcdef",
+		expected: &imageReference{
+			Registry:   "gcr.io",
+			Repository: "projectriff/app",
+			Digest:     "sha256:0123456789abcdef",
+			name:       "gcr.io/projectriff/app",
+		},
+	}, {
+		name:  "tag and digest",
+		image: "gcr.io/projectriff/app:1.0@sha256:0123456789abcdef",
+		expected: &imageReference{
+			Registry:   "gcr.io",
+			Repository: "projectriff/app",
+			Tag:        "1.0",
+			Digest:     "sha256:0123456789abcdef",
+			name:       "gcr.io/projectriff/app",
+		},
+	}, {
+		name:        "empty",
+		image:       "",
+		expectedErr: true,
+	}, {
+		name:        "tag only",
+		image:       ":latest",
+		expectedErr: true,
+	}, {
+		name:        "registry only",
+		image:       "gcr.io/",
+		expectedErr: true,
+	}, {
+		name:        "uppercase repository",
+		image:       "projectriff/System",
+		expectedErr: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := parseImageReference(c.image)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("parseImageReference() expected error %v, got %v", c.expectedErr, err)
+			}
+			if diff := cmp.Diff(c.expected, actual, cmp.AllowUnexported(imageReference{})); diff != "" {
+				t.Errorf("parseImageReference() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestImageReferenceString(t *testing.T) {
+	for _, c := range []struct {
+		image    string
+		expected string
+	}{{
+		image:    "ubuntu",
+		expected: "ubuntu:latest",
+	}, {
+		image:    "gcr.io/projectriff/app:1.0",
+		expected: "gcr.io/projectriff/app:1.0",
+	}, {
+		image:    "gcr.io/projectriff/app:1.0@sha256:0123456789abcdef",
+		expected: "gcr.io/projectriff/app@sha256:0123456789abcdef",
+	}} {
+		t.Run(c.image, func(t *testing.T) {
+			ref, err := parseImageReference(c.image)
+			if err != nil {
+				t.Fatalf("parseImageReference() unexpected error: %v", err)
+			}
+			if actual := ref.String(); actual != c.expected {
+				t.Errorf("String() expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRegistryHost(t *testing.T) {
+	for _, c := range []struct {
+		server   string
+		expected string
+	}{{
+		server:   "docker.io",
+		expected: "docker.io",
+	}, {
+		server:   "index.docker.io",
+		expected: "docker.io",
+	}, {
+		server:   "https://index.docker.io/v1/",
+		expected: "docker.io",
+	}, {
+		server:   "registry-1.docker.io",
+		expected: "docker.io",
+	}, {
+		server:   "gcr.io",
+		expected: "gcr.io",
+	}, {
+		server:   "https://gcr.io",
+		expected: "gcr.io",
+	}, {
+		server:   "localhost:5000",
+		expected: "localhost:5000",
+	}, {
+		server:   "http://registry.example.com:5000/v2/",
+		expected: "registry.example.com:5000",
+	}} {
+		t.Run(c.server, func(t *testing.T) {
+			if actual := registryHost(c.server); actual != c.expected {
+				t.Errorf("registryHost() expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRegistryEndpoint(t *testing.T) {
+	for _, c := range []struct {
+		server      string
+		expected    string
+		expectedErr bool
+	}{{
+		server:   "docker.io",
+		expected: "https://registry-1.docker.io",
+	}, {
+		server:   "https://index.docker.io/v1/",
+		expected: "https://registry-1.docker.io",
+	}, {
+		server:   "gcr.io",
+		expected: "https://gcr.io",
+	}, {
+		server:   "http://localhost:5000",
+		expected: "http://localhost:5000",
+	}, {
+		server:      "https://",
+		expectedErr: true,
+	}} {
+		t.Run(c.server, func(t *testing.T) {
+			actual, err := registryEndpoint(c.server)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("registryEndpoint() expected error %v, got %v", c.expectedErr, err)
+			}
+			if actual != c.expected {
+				t.Errorf("registryEndpoint() expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		header   string
+		expected string
+	}{{
+		name:     "empty",
+		header:   "",
+		expected: "",
+	}, {
+		name:     "relative next",
+		header:   `</v2/projectriff/app/tags/list?last=1.1&n=2>; rel="next"`,
+		expected: "/v2/projectriff/app/tags/list?last=1.1&n=2",
+	}, {
+		name:     "absolute next",
+		header:   `<https://gcr.io/v2/projectriff/app/tags/list?last=1.1&n=2>; rel="next"`,
+		expected: "/v2/projectriff/app/tags/list?last=1.1&n=2",
+	}, {
+		name:     "next after other relations",
+		header:   `</v2/projectriff/app/tags/list?n=2>; rel="first", </v2/projectriff/app/tags/list?last=1.1&n=2>; rel="next"`,
+		expected: "/v2/projectriff/app/tags/list?last=1.1&n=2",
+	}, {
+		name:     "no next",
+		header:   `</v2/projectriff/app/tags/list?n=2>; rel="prev"`,
+		expected: "",
+	}, {
+		name:     "missing relation",
+		header:   `</v2/projectriff/app/tags/list?last=1.1&n=2>`,
+		expected: "",
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := nextLink(c.header); actual != c.expected {
+				t.Errorf("nextLink() expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseAuthenticateHeader(t *testing.T) {
+	for _, c := range []struct {
+		name           string
+		header         string
+		expectedScheme string
+		expectedParams map[string]string
+	}{{
+		name:           "empty",
+		header:         "",
+		expectedScheme: "",
+		expectedParams: map[string]string{},
+	}, {
+		name:           "basic",
+		header:         `Basic realm="Registry Realm"`,
+		expectedScheme: "Basic",
+		expectedParams: map[string]string{
+			"realm": "Registry Realm",
+		},
+	}, {
+		name:           "bearer",
+		header:         `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`,
+		expectedScheme: "Bearer",
+		expectedParams: map[string]string{
+			"realm":   "https://auth.docker.io/token",
+			"service": "registry.docker.io",
+		},
+	}, {
+		name:           "bearer with scope listing several actions",
+		header:         `Bearer realm="https://gcr.io/v2/token", Service="gcr.io", scope="repository:projectriff/app:pull,push"`,
+		expectedScheme: "Bearer",
+		expectedParams: map[string]string{
+			"realm":   "https://gcr.io/v2/token",
+			"service": "gcr.io",
+			"scope":   "repository:projectriff/app:pull,push",
+		},
+	}, {
+		name:           "scheme without params",
+		header:         "Negotiate",
+		expectedScheme: "Negotiate",
+		expectedParams: map[string]string{},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			scheme, params := parseAuthenticateHeader(c.header)
+			if scheme != c.expectedScheme {
+				t.Errorf("parseAuthenticateHeader() expected scheme %q, got %q", c.expectedScheme, scheme)
+			}
+			if diff := cmp.Diff(c.expectedParams, params); diff != "" {
+				t.Errorf("parseAuthenticateHeader() params (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistryRepository(t *testing.T) {
+	const (
+		username = "riff"
+		password = "s3cr3t"
+		token    = "t0k3n"
+		digest   = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		platform = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	created := time.Date(2019, time.December, 1, 12, 0, 0, 0, time.UTC)
+
+	registry := func(scheme string, configRequests *int) *httptest.Server {
+		var server *httptest.Server
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if r.URL.Query().Get("scope") != "repository:projectriff/app:pull" || r.URL.Query().Get("service") != "registry.test" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"token": token})
+				return
+			}
+
+			authorized := false
+			switch scheme {
+			case "basic":
+				u, p, ok := r.BasicAuth()
+				authorized = ok && u == username && p == password
+				if !authorized {
+					w.Header().Set("WWW-Authenticate", `Basic realm="registry.test"`)
+				}
+			case "bearer":
+				authorized = r.Header.Get("Authorization") == "Bearer "+token
+				if !authorized {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry.test"`, server.URL))
+				}
+			default:
+				authorized = true
+			}
+			if !authorized {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			switch r.URL.Path {
+			case "/v2/projectriff/app/manifests/1.0":
+				if !strings.Contains(r.Header.Get("Accept"), "application/vnd.docker.distribution.manifest.v2+json") {
+					w.WriteHeader(http.StatusNotAcceptable)
+					return
+				}
+				w.Header().Set("Docker-Content-Digest", digest)
+			case "/v2/projectriff/app/manifests/" + digest:
+				w.Header().Set("Docker-Content-Digest", digest)
+				fmt.Fprintf(w, `{"manifests": [{"digest": %q}]}`, platform)
+			case "/v2/projectriff/app/manifests/" + platform:
+				w.Header().Set("Docker-Content-Digest", platform)
+				fmt.Fprint(w, `{"config": {"digest": "sha256:config"}}`)
+			case "/v2/projectriff/app/blobs/sha256:config":
+				*configRequests++
+				fmt.Fprintf(w, `{"created": %q}`, created.Format(time.RFC3339))
+			case "/v2/projectriff/app/manifests/no-digest":
+			case "/v2/projectriff/app/tags/list":
+				if r.URL.Query().Get("last") == "" {
+					w.Header().Set("Link", `</v2/projectriff/app/tags/list?last=1.1&n=2>; rel="next"`)
+					fmt.Fprint(w, `{"name": "projectriff/app", "tags": ["1.0", "1.1"]}`)
+				} else {
+					fmt.Fprint(w, `{"name": "projectriff/app", "tags": ["2.0"]}`)
+				}
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		return server
+	}
+
+	for _, scheme := range []string{"anonymous", "basic", "bearer"} {
+		t.Run(scheme, func(t *testing.T) {
+			configRequests := 0
+			server := registry(scheme, &configRequests)
+			defer server.Close()
+
+			ref, err := parseImageReference(strings.TrimPrefix(server.URL, "https://") + "/projectriff/app:1.0")
+			if err != nil {
+				t.Fatalf("parseImageReference() unexpected error: %v", err)
+			}
+			client := &RegistryClient{HTTPClient: server.Client()}
+			repository, err := client.Repository(ref, username, password)
+			if err != nil {
+				t.Fatalf("Repository() unexpected error: %v", err)
+			}
+			ctx := context.Background()
+
+			actualDigest, err := repository.Digest(ctx, "1.0")
+			if err != nil {
+				t.Fatalf("Digest() unexpected error: %v", err)
+			}
+			if actualDigest != digest {
+				t.Errorf("Digest() expected %q, got %q", digest, actualDigest)
+			}
+			if _, err := repository.Digest(ctx, "missing"); err == nil {
+				t.Errorf("Digest() expected error for missing tag")
+			}
+			if _, err := repository.Digest(ctx, "no-digest"); err == nil {
+				t.Errorf("Digest() expected error when the registry returns no digest")
+			}
+
+			tags, err := repository.Tags(ctx)
+			if err != nil {
+				t.Fatalf("Tags() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff([]string{"1.0", "1.1", "2.0"}, tags); diff != "" {
+				t.Errorf("Tags() (-expected, +actual): %s", diff)
+			}
+
+			actualCreated, err := repository.Created(ctx, "1.0")
+			if err != nil {
+				t.Fatalf("Created() unexpected error: %v", err)
+			}
+			if !actualCreated.Equal(created) {
+				t.Errorf("Created() expected %s, got %s", created, actualCreated)
+			}
+			actualCreated, err = repository.Created(ctx, "1.0")
+			if err != nil {
+				t.Fatalf("Created() unexpected error: %v", err)
+			}
+			if !actualCreated.Equal(created) {
+				t.Errorf("Created() expected %s, got %s", created, actualCreated)
+			}
+			if configRequests != 1 {
+				t.Errorf("Created() expected image config to be fetched once, got %d", configRequests)
+			}
+			if _, err := repository.Created(ctx, "missing"); err == nil {
+				t.Errorf("Created() expected error for missing tag")
+			}
+		})
+	}
+
+	t.Run("bad credentials", func(t *testing.T) {
+		server := registry("bearer", new(int))
+		defer server.Close()
+
+		ref, err := parseImageReference(strings.TrimPrefix(server.URL, "https://") + "/projectriff/app:1.0")
+		if err != nil {
+			t.Fatalf("parseImageReference() unexpected error: %v", err)
+		}
+		client := &RegistryClient{HTTPClient: server.Client()}
+		repository, err := client.Repository(ref, username, "wrong")
+		if err != nil {
+			t.Fatalf("Repository() unexpected error: %v", err)
+		}
+		if _, err := repository.Digest(context.Background(), "1.0"); err == nil {
+			t.Errorf("Digest() expected error for rejected credentials")
+		}
+	})
+}
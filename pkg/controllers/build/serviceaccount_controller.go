@@ -0,0 +1,532 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	kpackbuildv1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/kpack/build/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+)
+
+const riffBuildServiceAccount = "riff-build"
+
+// buildServiceAccountName resolves the service account a build runs as.
+func buildServiceAccountName(name string) string {
+	if name == "" {
+		return riffBuildServiceAccount
+	}
+	return name
+}
+
+// credentialServiceAccounts is the set of service accounts a credential binds
+// to, the riff-build service account unless the credential selects others.
+func credentialServiceAccounts(credential metav1.Object) sets.String {
+	value, ok := credential.GetAnnotations()[buildv1alpha1.ServiceAccountsAnnotationKey]
+	if !ok {
+		return sets.NewString(riffBuildServiceAccount)
+	}
+	serviceAccounts := sets.NewString()
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			serviceAccounts.Insert(name)
+		}
+	}
+	return serviceAccounts
+}
+
+// isManagedServiceAccount returns true for service accounts riff created or
+// is expected to manage.
+func isManagedServiceAccount(serviceAccount metav1.Object) bool {
+	if serviceAccount.GetName() == riffBuildServiceAccount {
+		return true
+	}
+	_, ok := serviceAccount.GetLabels()[buildv1alpha1.ServiceAccountLabelKey]
+	return ok
+}
+
+// imagePullSecretName is the name of the image pull secret generated for a
+// registry credential.
+func imagePullSecretName(credential string) string {
+	return fmt.Sprintf("%s-image-pull", credential)
+}
+
+// isRegistryCredential returns true for credentials kpack uses to push to an
+// image registry.
+func isRegistryCredential(credential *corev1.Secret) bool {
+	_, ok := credential.Annotations[kpackbuildv1alpha1.DockerSecretAnnotationKey]
+	return ok && credential.Type == corev1.SecretTypeBasicAuth
+}
+
+// ServiceAccountReconciler binds credentials to build ServiceAccount objects,
+// and registry credentials to the runtime ServiceAccount as image pull secrets
+type ServiceAccountReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=build.projectriff.io,resources=applications;functions,verbs=get;list;watch
+
+func (r *ServiceAccountReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("serviceaccount", req.NamespacedName)
+
+	var originalSerivceAccount corev1.ServiceAccount
+	if err := r.Get(ctx, req.NamespacedName, &originalSerivceAccount); err != nil && !apierrs.IsNotFound(err) {
+		log.Error(err, "unable to fetch ServiceAccount")
+		return ctrl.Result{}, err
+	}
+
+	// Don't modify the informers copy
+	var serviceAccount corev1.ServiceAccount
+	if &originalSerivceAccount != nil {
+		serviceAccount = *(originalSerivceAccount.DeepCopy())
+	}
+
+	return r.reconcile(ctx, log, &serviceAccount, req.Namespace, req.Name)
+}
+
+func (r *ServiceAccountReconciler) reconcile(ctx context.Context, log logr.Logger, serviceAccount *corev1.ServiceAccount, namespace, name string) (ctrl.Result, error) {
+	if serviceAccount != nil && serviceAccount.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	secretNames := sets.NewString()
+	var secrets corev1.SecretList
+	if err := r.List(ctx, &secrets, client.InNamespace(namespace), MatchingLabels(buildv1alpha1.CredentialLabelKey)); err != nil {
+		log.Error(err, "Failed to get Secrets", "serviceaccount", serviceAccount)
+		return ctrl.Result{Requeue: true}, err
+	}
+	for _, secret := range secrets.Items {
+		if credentialServiceAccounts(&secret).Has(name) {
+			secretNames.Insert(secret.Name)
+		}
+	}
+
+	runtimeServiceAccountName, err := controllers.RuntimeServiceAccount(ctx, r.Client, namespace)
+	if err != nil {
+		log.Error(err, "Failed to resolve runtime ServiceAccount")
+		return ctrl.Result{}, err
+	}
+	isRuntime := runtimeServiceAccountName == name
+
+	pullSecretNames := sets.NewString()
+	if isRuntime {
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			if !isRegistryCredential(secret) {
+				continue
+			}
+			pullSecret, err := r.reconcileImagePullSecret(ctx, log, secret)
+			if err != nil {
+				log.Error(err, "Failed to reconcile image pull Secret", "secret", secret.Name)
+				return ctrl.Result{}, err
+			}
+			pullSecretNames.Insert(pullSecret.Name)
+		}
+	}
+
+	if serviceAccount.Name == "" {
+		if needed, err := r.isServiceAccountNeeded(ctx, secretNames, namespace, name); err != nil {
+			return ctrl.Result{}, err
+		} else if needed || isRuntime {
+			serviceAccount, err := r.createServiceAccount(ctx, log, secretNames, pullSecretNames, isRuntime, namespace, name)
+			if err != nil {
+				log.Error(err, "Failed to create ServiceAccount", "serviceaccount", serviceAccount)
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if _, bound := serviceAccount.Annotations[buildv1alpha1.CredentialsAnnotationKey]; bound || secretNames.Len() != 0 || isManagedServiceAccount(serviceAccount) {
+		// leave service accounts we have never bound credentials to untouched
+		serviceAccount, err = r.reconcileServiceAccount(ctx, log, serviceAccount, secretNames)
+		if err != nil {
+			log.Error(err, "Failed to reconcile ServiceAccount", "serviceaccount", serviceAccount)
+			return ctrl.Result{}, err
+		}
+	}
+	if _, added := serviceAccount.Annotations[buildv1alpha1.ImagePullSecretsAnnotationKey]; added || isRuntime {
+		// remove image pull secrets from service accounts no longer used at runtime
+		serviceAccount, err = r.reconcileImagePullSecrets(ctx, log, serviceAccount, pullSecretNames, isRuntime)
+		if err != nil {
+			log.Error(err, "Failed to reconcile ServiceAccount image pull secrets", "serviceaccount", serviceAccount)
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ServiceAccountReconciler) reconcileServiceAccount(ctx context.Context, log logr.Logger, existingServiceAccount *corev1.ServiceAccount, desiredBoundSecrets sets.String) (*corev1.ServiceAccount, error) {
+	serviceAccount := existingServiceAccount.DeepCopy()
+	boundSecrets := sets.NewString(strings.Split(serviceAccount.Annotations[buildv1alpha1.CredentialsAnnotationKey], ",")...)
+	removeSecrets := boundSecrets.Difference(desiredBoundSecrets)
+
+	secrets := []corev1.ObjectReference{}
+	// filter out secrets no longer bound
+	for _, secret := range serviceAccount.Secrets {
+		if !removeSecrets.Has(secret.Name) {
+			secrets = append(secrets, secret)
+		}
+	}
+	// add new secrets
+	for _, secret := range desiredBoundSecrets.Difference(boundSecrets).UnsortedList() {
+		secrets = append(secrets, corev1.ObjectReference{Name: secret})
+	}
+	serviceAccount.Secrets = secrets
+
+	if serviceAccount.Annotations == nil {
+		serviceAccount.Annotations = map[string]string{}
+	}
+	serviceAccount.Annotations[buildv1alpha1.CredentialsAnnotationKey] = strings.Join(desiredBoundSecrets.List(), ",")
+
+	if serviceAccountSemanticEquals(serviceAccount, existingServiceAccount) {
+		// No differences to reconcile.
+		return serviceAccount, nil
+	}
+
+	log.Info("reconciling serviceaccount", "diff", cmp.Diff(existingServiceAccount.Secrets, serviceAccount.Secrets))
+	return serviceAccount, r.Update(ctx, serviceAccount)
+}
+
+func (r *ServiceAccountReconciler) reconcileImagePullSecrets(ctx context.Context, log logr.Logger, existingServiceAccount *corev1.ServiceAccount, desiredPullSecrets sets.String, isRuntime bool) (*corev1.ServiceAccount, error) {
+	serviceAccount := existingServiceAccount.DeepCopy()
+	addedPullSecrets := sets.NewString(strings.Split(serviceAccount.Annotations[buildv1alpha1.ImagePullSecretsAnnotationKey], ",")...)
+	removePullSecrets := addedPullSecrets.Difference(desiredPullSecrets)
+
+	pullSecrets := []corev1.LocalObjectReference{}
+	// filter out pull secrets no longer added, preserving user managed entries
+	for _, pullSecret := range serviceAccount.ImagePullSecrets {
+		if !removePullSecrets.Has(pullSecret.Name) {
+			pullSecrets = append(pullSecrets, pullSecret)
+		}
+	}
+	// add new pull secrets
+	for _, pullSecret := range desiredPullSecrets.Difference(addedPullSecrets).List() {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: pullSecret})
+	}
+	serviceAccount.ImagePullSecrets = pullSecrets
+
+	if serviceAccount.Annotations == nil {
+		serviceAccount.Annotations = map[string]string{}
+	}
+	if isRuntime {
+		serviceAccount.Annotations[buildv1alpha1.ImagePullSecretsAnnotationKey] = strings.Join(desiredPullSecrets.List(), ",")
+	} else {
+		delete(serviceAccount.Annotations, buildv1alpha1.ImagePullSecretsAnnotationKey)
+	}
+
+	if equality.Semantic.DeepEqual(serviceAccount.ImagePullSecrets, existingServiceAccount.ImagePullSecrets) &&
+		equality.Semantic.DeepEqual(serviceAccount.Annotations, existingServiceAccount.Annotations) {
+		// No differences to reconcile.
+		return serviceAccount, nil
+	}
+
+	log.Info("reconciling serviceaccount image pull secrets", "diff", cmp.Diff(existingServiceAccount.ImagePullSecrets, serviceAccount.ImagePullSecrets))
+	return serviceAccount, r.Update(ctx, serviceAccount)
+}
+
+func (r *ServiceAccountReconciler) reconcileImagePullSecret(ctx context.Context, log logr.Logger, credential *corev1.Secret) (*corev1.Secret, error) {
+	var actualPullSecret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: credential.Namespace, Name: imagePullSecretName(credential.Name)}, &actualPullSecret); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return nil, err
+		}
+		actualPullSecret = corev1.Secret{}
+	}
+
+	desiredPullSecret, err := r.constructImagePullSecretForCredential(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	// create image pull secret if it doesn't exist
+	if actualPullSecret.Name == "" {
+		log.Info("creating image pull secret", "secret", desiredPullSecret.Name)
+		if err := r.Create(ctx, desiredPullSecret); err != nil {
+			return nil, err
+		}
+		return desiredPullSecret, nil
+	}
+
+	if imagePullSecretSemanticEquals(desiredPullSecret, &actualPullSecret) {
+		// image pull secret is unchanged
+		return &actualPullSecret, nil
+	}
+
+	// update image pull secret with desired changes
+	pullSecret := actualPullSecret.DeepCopy()
+	pullSecret.ObjectMeta.Labels = desiredPullSecret.ObjectMeta.Labels
+	pullSecret.Type = desiredPullSecret.Type
+	pullSecret.Data = desiredPullSecret.Data
+	log.Info("reconciling image pull secret", "secret", pullSecret.Name)
+	return pullSecret, r.Update(ctx, pullSecret)
+}
+
+func (r *ServiceAccountReconciler) constructImagePullSecretForCredential(credential *corev1.Secret) (*corev1.Secret, error) {
+	username := string(credential.Data[corev1.BasicAuthUsernameKey])
+	password := string(credential.Data[corev1.BasicAuthPasswordKey])
+	dockerConfig, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			credential.Annotations[kpackbuildv1alpha1.DockerSecretAnnotationKey]: map[string]string{
+				"username": username,
+				"password": password,
+				"auth":     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pullSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      imagePullSecretName(credential.Name),
+			Namespace: credential.Namespace,
+			Labels: map[string]string{
+				buildv1alpha1.ImagePullSecretLabelKey: credential.Name,
+			},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfig,
+		},
+	}
+	if err := ctrl.SetControllerReference(credential, pullSecret, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return pullSecret, nil
+}
+
+func imagePullSecretSemanticEquals(desiredPullSecret, pullSecret *corev1.Secret) bool {
+	return equality.Semantic.DeepEqual(desiredPullSecret.Type, pullSecret.Type) &&
+		equality.Semantic.DeepEqual(desiredPullSecret.Data, pullSecret.Data) &&
+		equality.Semantic.DeepEqual(desiredPullSecret.ObjectMeta.Labels, pullSecret.ObjectMeta.Labels)
+}
+
+func (r *ServiceAccountReconciler) isServiceAccountNeeded(ctx context.Context, secretNames sets.String, namespace, name string) (bool, error) {
+	if secretNames.Len() != 0 {
+		return true, nil
+	}
+	var applications buildv1alpha1.ApplicationList
+	if err := r.List(ctx, &applications, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	for _, application := range applications.Items {
+		if buildServiceAccountName(application.Spec.ServiceAccountName) == name {
+			return true, nil
+		}
+	}
+	var functions buildv1alpha1.FunctionList
+	if err := r.List(ctx, &functions, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	for _, function := range functions.Items {
+		if buildServiceAccountName(function.Spec.ServiceAccountName) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *ServiceAccountReconciler) createServiceAccount(ctx context.Context, log logr.Logger, secretNames, pullSecretNames sets.String, isRuntime bool, namespace, name string) (*corev1.ServiceAccount, error) {
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				buildv1alpha1.ServiceAccountLabelKey: name,
+			},
+			Annotations: map[string]string{
+				buildv1alpha1.CredentialsAnnotationKey: strings.Join(secretNames.List(), ","),
+			},
+		},
+		Secrets: make([]corev1.ObjectReference, secretNames.Len()),
+	}
+	for i, secretName := range secretNames.UnsortedList() {
+		serviceAccount.Secrets[i] = corev1.ObjectReference{Name: secretName}
+	}
+	if isRuntime {
+		serviceAccount.Annotations[buildv1alpha1.ImagePullSecretsAnnotationKey] = strings.Join(pullSecretNames.List(), ",")
+		for _, pullSecretName := range pullSecretNames.List() {
+			serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets, corev1.LocalObjectReference{Name: pullSecretName})
+		}
+	}
+	log.Info("creating serviceaccount", "secrets", serviceAccount.Secrets, "imagePullSecrets", serviceAccount.ImagePullSecrets)
+	return serviceAccount, r.Create(ctx, serviceAccount)
+}
+
+func serviceAccountSemanticEquals(desiredServiceAccount, serviceAccount *corev1.ServiceAccount) bool {
+	return equality.Semantic.DeepEqual(desiredServiceAccount.Secrets, serviceAccount.Secrets) &&
+		equality.Semantic.DeepEqual(desiredServiceAccount.Annotations, serviceAccount.Annotations)
+}
+
+// MatchingLabels filters the list/delete operation for a given LabelSelctor
+type MatchingLabels string
+
+func (m MatchingLabels) ApplyToList(opts *client.ListOptions) {
+	sel, _ := labels.Parse(string(m))
+	opts.LabelSelector = sel
+}
+
+func (r *ServiceAccountReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueRuntimeServiceAccount := func(namespace string) []reconcile.Request {
+		name, err := controllers.RuntimeServiceAccount(context.Background(), r.Client, namespace)
+		if err != nil {
+			r.Log.Error(err, "unable to resolve runtime ServiceAccount", "namespace", namespace)
+			return []reconcile.Request{}
+		}
+		if name == "" {
+			return []reconcile.Request{}
+		}
+		return []reconcile.Request{
+			{
+				NamespacedName: types.NamespacedName{
+					Namespace: namespace,
+					Name:      name,
+				},
+			},
+		}
+	}
+
+	enqueueServiceAccountForCredential := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			if _, ok := a.Meta.GetLabels()[buildv1alpha1.ImagePullSecretLabelKey]; ok {
+				return enqueueRuntimeServiceAccount(a.Meta.GetNamespace())
+			}
+			if _, ok := a.Meta.GetLabels()[buildv1alpha1.CredentialLabelKey]; !ok {
+				// not all secrets are credentials
+				return []reconcile.Request{}
+			}
+			requests := []reconcile.Request{}
+			for _, name := range credentialServiceAccounts(a.Meta).List() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{
+						Namespace: a.Meta.GetNamespace(),
+						Name:      name,
+					},
+				})
+			}
+			if secret, ok := a.Object.(*corev1.Secret); ok && isRegistryCredential(secret) {
+				requests = append(requests, enqueueRuntimeServiceAccount(a.Meta.GetNamespace())...)
+			}
+			return requests
+		}),
+	}
+
+	enqueueServiceAccountForBuildConfigMap := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			if a.Meta.GetName() != buildv1alpha1.BuildConfigMapName {
+				return []reconcile.Request{}
+			}
+			cm, ok := a.Object.(*corev1.ConfigMap)
+			if !ok || cm.Data[buildv1alpha1.RuntimeServiceAccountConfigKey] == "" {
+				return []reconcile.Request{}
+			}
+			// both the previous and the current runtime service account are enqueued on update
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Namespace: a.Meta.GetNamespace(),
+						Name:      cm.Data[buildv1alpha1.RuntimeServiceAccountConfigKey],
+					},
+				},
+			}
+		}),
+	}
+
+	enqueueServiceAccountForBuild := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			var serviceAccountName string
+			switch build := a.Object.(type) {
+			case *buildv1alpha1.Application:
+				serviceAccountName = build.Spec.ServiceAccountName
+			case *buildv1alpha1.Function:
+				serviceAccountName = build.Spec.ServiceAccountName
+			}
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Namespace: a.Meta.GetNamespace(),
+						Name:      buildServiceAccountName(serviceAccountName),
+					},
+				},
+			}
+		}),
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.ServiceAccount{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				sa, ok := e.Object.(*corev1.ServiceAccount)
+				if !ok {
+					// not a serviceaccount, allow
+					return true
+				}
+				// filter services accounts to only be build service accounts
+				return isManagedServiceAccount(sa)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				sa, ok := e.ObjectNew.(*corev1.ServiceAccount)
+				if !ok {
+					// not a serviceaccount, allow
+					return true
+				}
+				// filter services accounts to only be build service accounts
+				return isManagedServiceAccount(sa)
+			},
+		}).
+		// watch for secret mutations to bind to service account
+		Watches(&source.Kind{Type: &corev1.Secret{}}, enqueueServiceAccountForCredential).
+		// watch for runtime service account configuration changes
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueServiceAccountForBuildConfigMap).
+		// watch for build mutations to create service account
+		Watches(&source.Kind{Type: &buildv1alpha1.Application{}}, enqueueServiceAccountForBuild).
+		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueServiceAccountForBuild).
+		Complete(r)
+}
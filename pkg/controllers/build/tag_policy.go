@@ -0,0 +1,142 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	"github.com/projectriff/system/pkg/semver"
+)
+
+// selectTags returns the tags chosen by the tag policy, best first.
+func selectTags(ctx context.Context, policy *buildv1alpha1.TagPolicy, repository *RegistryRepository, tags []string) ([]string, error) {
+	switch {
+	case policy.Semver != nil:
+		return selectSemverTags(policy.Semver, tags)
+	case policy.Regex != nil:
+		return selectRegexTags(policy.Regex, tags)
+	case policy.Latest != nil:
+		return selectLatestTags(ctx, policy.Latest, repository, tags)
+	}
+	return []string{}, nil
+}
+
+func selectSemverTags(policy *buildv1alpha1.SemverTagPolicy, tags []string) ([]string, error) {
+	versionRange, err := semver.ParseRange(policy.Range)
+	if err != nil {
+		return nil, err
+	}
+	versions := map[string]*semver.Version{}
+	selected := []string{}
+	for _, tag := range tags {
+		version, err := semver.ParseVersion(tag)
+		if err != nil || !versionRange.Matches(version) {
+			continue
+		}
+		versions[tag] = version
+		selected = append(selected, tag)
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := versions[selected[i]], versions[selected[j]]
+		if a.LessThan(b) || b.LessThan(a) {
+			return b.LessThan(a)
+		}
+		// equal versions differing in build metadata
+		return selected[i] > selected[j]
+	})
+	return selected, nil
+}
+
+func selectRegexTags(policy *buildv1alpha1.RegexTagPolicy, tags []string) ([]string, error) {
+	pattern, err := regexp.Compile(policy.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	numbers := map[string]float64{}
+	selected := []string{}
+	for _, tag := range tags {
+		submatches := pattern.FindStringSubmatchIndex(tag)
+		if submatches == nil {
+			continue
+		}
+		value := tag
+		if policy.Extract != "" {
+			value = string(pattern.ExpandString(nil, policy.Extract, tag, submatches))
+		}
+		if policy.Order == buildv1alpha1.NumericalTagOrder {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				// not orderable
+				continue
+			}
+			numbers[tag] = number
+		}
+		values[tag] = value
+		selected = append(selected, tag)
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		if policy.Order == buildv1alpha1.NumericalTagOrder && numbers[a] != numbers[b] {
+			return numbers[a] > numbers[b]
+		}
+		if values[a] != values[b] {
+			return values[a] > values[b]
+		}
+		return a > b
+	})
+	return selected, nil
+}
+
+func selectLatestTags(ctx context.Context, policy *buildv1alpha1.LatestTagPolicy, repository *RegistryRepository, tags []string) ([]string, error) {
+	pattern, err := regexp.Compile(policy.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	selected := []string{}
+	for _, tag := range tags {
+		if pattern.MatchString(tag) {
+			selected = append(selected, tag)
+		}
+	}
+	if len(selected) > buildv1alpha1.LatestTagCandidatesLimit {
+		// every candidate is inspected on every poll
+		return nil, fmt.Errorf("%d tags match pattern %q, narrow the pattern to match at most %d tags", len(selected), policy.Pattern, buildv1alpha1.LatestTagCandidatesLimit)
+	}
+	created := map[string]time.Time{}
+	for _, tag := range selected {
+		t, err := repository.Created(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		created[tag] = t
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		if !created[a].Equal(created[b]) {
+			return created[a].After(created[b])
+		}
+		return a > b
+	})
+	return selected, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package build
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+)
+
+func TestSelectLatestTags(t *testing.T) {
+	tooMany := []string{}
+	for i := 0; i <= buildv1alpha1.LatestTagCandidatesLimit; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("build-%d", i))
+	}
+
+	for _, c := range []struct {
+		name        string
+		policy      *buildv1alpha1.LatestTagPolicy
+		tags        []string
+		expected    []string
+		expectedErr bool
+	}{{
+		name:     "no matching tags",
+		policy:   &buildv1alpha1.LatestTagPolicy{Pattern: "^main-"},
+		tags:     []string{"1.0", "latest"},
+		expected: []string{},
+	}, {
+		name:        "too many matching tags",
+		policy:      &buildv1alpha1.LatestTagPolicy{},
+		tags:        tooMany,
+		expectedErr: true,
+	}, {
+		name:     "pattern narrows too many tags",
+		policy:   &buildv1alpha1.LatestTagPolicy{Pattern: "^main-"},
+		tags:     tooMany,
+		expected: []string{},
+	}, {
+		name:        "invalid pattern",
+		policy:      &buildv1alpha1.LatestTagPolicy{Pattern: "("},
+		tags:        []string{"1.0"},
+		expectedErr: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			// the registry is not consulted without candidates
+			actual, err := selectLatestTags(context.Background(), c.policy, nil, c.tags)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("selectLatestTags() expected error %v, got %v", c.expectedErr, err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("selectLatestTags() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -63,6 +63,7 @@ type DeployerReconciler struct {
 // +kubebuilder:rbac:groups=core.projectriff.io,resources=deployers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core.projectriff.io,resources=deployers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=applications;containers;functions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
@@ -130,6 +131,16 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 		return ctrl.Result{}, err
 	}
 
+	// run as the namespace's runtime service account by default
+	if deployer.Spec.Template.Spec.ServiceAccountName == "" {
+		serviceAccountName, err := controllers.ResolveRuntimeServiceAccount(ctx, r.Client, r.Tracker, deployer)
+		if err != nil {
+			log.Error(err, "unable to resolve runtime ServiceAccount", "deployer", deployer)
+			return ctrl.Result{}, err
+		}
+		deployer.Spec.Template.Spec.ServiceAccountName = serviceAccountName
+	}
+
 	// resolve build image
 	if err := r.reconcileBuildImage(ctx, log, deployer); err != nil {
 		if apierrs.IsNotFound(err) {
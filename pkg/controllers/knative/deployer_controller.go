@@ -22,10 +22,12 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,7 +35,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	"github.com/projectriff/system/pkg/apis"
 	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
 	knativev1alpha1 "github.com/projectriff/system/pkg/apis/knative/v1alpha1"
 	servingv1 "github.com/projectriff/system/pkg/apis/thirdparty/knative/serving/v1"
@@ -58,6 +59,7 @@ type DeployerReconciler struct {
 // +kubebuilder:rbac:groups=knative.projectriff.io,resources=deployers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=knative.projectriff.io,resources=deployers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=applications;containers;functions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=configurations;routes,verbs=get;list;watch;create;update;patch;delete
 
 func (r *DeployerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -101,6 +103,16 @@ func (r *DeployerReconciler) reconcile(ctx context.Context, log logr.Logger, dep
 		return ctrl.Result{}, nil
 	}
 
+	// run as the namespace's runtime service account by default
+	if deployer.Spec.Template.Spec.ServiceAccountName == "" {
+		serviceAccountName, err := controllers.ResolveRuntimeServiceAccount(ctx, r.Client, r.Tracker, deployer)
+		if err != nil {
+			log.Error(err, "unable to resolve runtime ServiceAccount", "deployer", deployer)
+			return ctrl.Result{}, err
+		}
+		deployer.Spec.Template.Spec.ServiceAccountName = serviceAccountName
+	}
+
 	// resolve build image
 	if err := r.reconcileBuildImage(ctx, log, deployer); err != nil {
 		if apierrs.IsNotFound(err) {
@@ -449,12 +461,12 @@ func (r *DeployerReconciler) constructAnnotationsForDeployer(deployer *knativev1
 }
 
 func (r *DeployerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	enqueueTrackedResources := func(t apis.Resource) handler.EventHandler {
+	enqueueTrackedResources := func(gvk schema.GroupVersionKind) handler.EventHandler {
 		return &handler.EnqueueRequestsFromMapFunc{
 			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
 				requests := []reconcile.Request{}
 				key := tracker.NewKey(
-					t.GetGroupVersionKind(),
+					gvk,
 					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
 				)
 				for _, item := range r.Tracker.Lookup(key) {
@@ -477,8 +489,10 @@ func (r *DeployerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&servingv1.Configuration{}).
 		Owns(&servingv1.Route{}).
 		// watch for build mutations to update dependent deployers
-		Watches(&source.Kind{Type: &buildv1alpha1.Application{}}, enqueueTrackedResources(&buildv1alpha1.Application{})).
-		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources(&buildv1alpha1.Container{})).
-		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources(&buildv1alpha1.Function{})).
+		Watches(&source.Kind{Type: &buildv1alpha1.Application{}}, enqueueTrackedResources((&buildv1alpha1.Application{}).GetGroupVersionKind())).
+		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources((&buildv1alpha1.Container{}).GetGroupVersionKind())).
+		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources((&buildv1alpha1.Function{}).GetGroupVersionKind())).
+		// watch for build ConfigMap mutations to update the runtime service account
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})).
 		Complete(r)
 }
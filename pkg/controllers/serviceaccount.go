@@ -0,0 +1,58 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+// RuntimeServiceAccount returns the service account workloads in the
+// namespace run as by default, or empty if not configured.
+func RuntimeServiceAccount(ctx context.Context, c client.Client, namespace string) (string, error) {
+	var cm corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: buildv1alpha1.BuildConfigMapName}, &cm); err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return cm.Data[buildv1alpha1.RuntimeServiceAccountConfigKey], nil
+}
+
+// ResolveRuntimeServiceAccount returns the runtime service account for the
+// namespace of the object. The namespace's build ConfigMap is tracked for
+// changes.
+func ResolveRuntimeServiceAccount(ctx context.Context, c client.Client, t tracker.Tracker, obj metav1.Object) (string, error) {
+	t.Track(
+		tracker.NewKey(
+			schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			types.NamespacedName{Namespace: obj.GetNamespace(), Name: buildv1alpha1.BuildConfigMapName},
+		),
+		types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+	)
+	return RuntimeServiceAccount(ctx, c, obj.GetNamespace())
+}
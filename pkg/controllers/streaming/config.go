@@ -23,7 +23,22 @@ const (
 	pulsarProviderImages = kustomizePrefix + "-pulsar-provider" // contains image names for the pulsar provider
 	gatewayImageKey      = "gatewayImage"
 	provisionerImageKey  = "provisionerImage"
+	brokerImageKey       = "brokerImage"
 
 	processorImages   = kustomizePrefix + "-processor" // contains image names for the streaming processor
 	processorImageKey = "processorImage"
+
+	streamIngressSettings  = kustomizePrefix + "-stream-ingress" // contains image and domain for stream ingresses
+	streamIngressImageKey  = "ingressImage"
+	streamIngressDomainKey = "defaultDomain"
+	defaultDomain          = "example.com"
+
+	streamSubscriptionImages = kustomizePrefix + "-stream-subscription" // contains image names for stream subscriptions
+	dispatcherImageKey       = "dispatcherImage"
+
+	streamTapImages = kustomizePrefix + "-stream-tap" // contains image names for stream taps
+	tapImageKey     = "tapImage"
+
+	streamBridgeImages = kustomizePrefix + "-stream-bridge" // contains image names for stream migration bridges
+	bridgeImageKey     = "bridgeImage"
 )
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrDeliveryStatsNotSupported is returned when the dispatcher does not serve
+// the stats endpoint, delivery failures are unknown
+var ErrDeliveryStatsNotSupported = errors.New("the dispatcher does not report delivery stats")
+
+// DeliveryStats are the messages handled by a dispatcher since it started.
+type DeliveryStats struct {
+	Delivered    int64            `json:"delivered"`
+	Failed       int64            `json:"failed"`
+	DeadLettered int64            `json:"deadLettered"`
+	LastFailure  *DeliveryFailure `json:"lastFailure,omitempty"`
+}
+
+// DeliveryFailure is the most recent message a dispatcher failed to deliver.
+type DeliveryFailure struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+type DeliveryStatsClient interface {
+	// DeliveryStats asks the dispatcher running in the pod for the messages it
+	// handled
+	DeliveryStats(pod *corev1.Pod) (*DeliveryStats, error)
+}
+
+type deliveryStatsRestClient struct {
+	httpClient *http.Client
+	logger     logr.Logger
+}
+
+func NewDeliveryStatsClient(httpClient *http.Client, logger logr.Logger) DeliveryStatsClient {
+	return &deliveryStatsRestClient{
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+func (d *deliveryStatsRestClient) DeliveryStats(pod *corev1.Pod) (*DeliveryStats, error) {
+	url := fmt.Sprintf("http://%s:%d/stats", pod.Status.PodIP, dispatcherStatsPort)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			d.logger.Error(err, "Error closing delivery stats response body")
+		}
+	}()
+	if res.StatusCode == http.StatusNotFound {
+		// dispatcher images predating the stats endpoint
+		return nil, ErrDeliveryStatsNotSupported
+	}
+	if res.StatusCode >= 400 {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return nil, fmt.Errorf("status: %d, body: %q", res.StatusCode, string(msg))
+	}
+	stats := &DeliveryStats{}
+	if err := json.NewDecoder(res.Body).Decode(stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
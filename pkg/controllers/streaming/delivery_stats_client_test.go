@@ -0,0 +1,99 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestDeliveryStatsClientDeliveryStats(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{PodIP: "10.0.0.7"},
+	}
+	respond := func(status int, body string) roundTripperFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if expected := "http://10.0.0.7:8081/stats"; req.URL.String() != expected {
+				t.Errorf("DeliveryStats() expected request to %q, got %q", expected, req.URL.String())
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}
+	}
+	failedAt := time.Date(2019, time.December, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		name                 string
+		transport            roundTripperFunc
+		expected             *DeliveryStats
+		expectedErr          bool
+		expectedNotSupported bool
+	}{{
+		name:      "stats",
+		transport: respond(http.StatusOK, `{"delivered": 8, "failed": 2, "deadLettered": 1, "lastFailure": {"time": "2019-12-01T12:00:00Z", "message": "subscriber responded 500"}}`),
+		expected: &DeliveryStats{
+			Delivered:    8,
+			Failed:       2,
+			DeadLettered: 1,
+			LastFailure:  &DeliveryFailure{Time: failedAt, Message: "subscriber responded 500"},
+		},
+	}, {
+		name:                 "stats endpoint not found",
+		transport:            respond(http.StatusNotFound, "404 page not found"),
+		expectedErr:          true,
+		expectedNotSupported: true,
+	}, {
+		name:        "dispatcher error",
+		transport:   respond(http.StatusInternalServerError, "oops"),
+		expectedErr: true,
+	}, {
+		name:        "malformed stats",
+		transport:   respond(http.StatusOK, "not json"),
+		expectedErr: true,
+	}, {
+		name: "dispatcher unavailable",
+		transport: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+		expectedErr: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			client := NewDeliveryStatsClient(&http.Client{Transport: c.transport}, logf.NullLogger{})
+			actual, err := client.DeliveryStats(pod)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("DeliveryStats() expected error %v, got %v", c.expectedErr, err)
+			}
+			if (err == ErrDeliveryStatsNotSupported) != c.expectedNotSupported {
+				t.Errorf("DeliveryStats() expected not supported %v, got %v", c.expectedNotSupported, err)
+			}
+			if diff := cmp.Diff(c.expected, actual); diff != "" {
+				t.Errorf("DeliveryStats() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
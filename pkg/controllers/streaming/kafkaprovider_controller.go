@@ -19,6 +19,8 @@ package streaming
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
@@ -43,8 +45,11 @@ import (
 )
 
 const (
-	kafkaProviderDeploymentIndexField = ".metadata.kafkaProviderDeploymentController"
-	kafkaProviderServiceIndexField    = ".metadata.kafkaProviderServiceController"
+	kafkaProviderDeploymentIndexField  = ".metadata.kafkaProviderDeploymentController"
+	kafkaProviderServiceIndexField     = ".metadata.kafkaProviderServiceController"
+	kafkaProviderStatefulSetIndexField = ".metadata.kafkaProviderStatefulSetController"
+
+	kafkaBrokerPort = 9092
 )
 
 // KafkaProviderReconciler reconciles a KafkaProvider object
@@ -54,11 +59,25 @@ type KafkaProviderReconciler struct {
 	Scheme    *runtime.Scheme
 	Tracker   tracker.Tracker
 	Namespace string
+	// HealthClient checks the provisioner reaches the brokers
+	HealthClient ProviderHealthClient
+	// HealthCheckInterval between broker connectivity checks
+	HealthCheckInterval time.Duration
+
+	// brokerHealth caches the last connectivity check for each provider
+	brokerHealth     map[types.NamespacedName]brokerHealthCheck
+	brokerHealthLock sync.Mutex
+}
+
+type brokerHealthCheck struct {
+	checked time.Time
+	err     error
 }
 
 // +kubebuilder:rbac:groups=streaming.projectriff.io,resources=kafkaproviders,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=streaming.projectriff.io,resources=kafkaproviders/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 
@@ -73,6 +92,7 @@ func (r *KafkaProviderReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		// we'll ignore not-found errors, since they can't be fixed by an immediate
 		// requeue (we'll need to wait for a new notification), and we can get them
 		// on deleted requests.
+		r.forgetBrokerHealth(req.NamespacedName)
 		return ctrl.Result{}, ignoreNotFound(err)
 	}
 
@@ -110,6 +130,30 @@ func (r *KafkaProviderReconciler) reconcile(ctx context.Context, log logr.Logger
 		return ctrl.Result{}, err
 	}
 
+	// Reconcile statefulSet for embedded broker
+	brokerStatefulSet, err := r.reconcileBrokerStatefulSet(ctx, log, kafkaProvider, &cm)
+	if err != nil {
+		log.Error(err, "unable to reconcile broker StatefulSet", "kafkaprovider", kafkaProvider)
+		return ctrl.Result{}, err
+	}
+	kafkaProvider.Status.BrokerStatefulSetRef = nil
+	if brokerStatefulSet != nil {
+		kafkaProvider.Status.BrokerStatefulSetRef = refs.NewTypedLocalObjectReferenceForObject(brokerStatefulSet, r.Scheme)
+	}
+
+	// Reconcile service for embedded broker
+	brokerService, err := r.reconcileBrokerService(ctx, log, kafkaProvider)
+	if err != nil {
+		log.Error(err, "unable to reconcile broker Service", "kafkaprovider", kafkaProvider)
+		return ctrl.Result{}, err
+	}
+	kafkaProvider.Status.BrokerServiceRef = nil
+	kafkaProvider.Status.BootstrapServers = kafkaProvider.Spec.BootstrapServers
+	if brokerService != nil {
+		kafkaProvider.Status.BrokerServiceRef = refs.NewTypedLocalObjectReferenceForObject(brokerService, r.Scheme)
+		kafkaProvider.Status.BootstrapServers = fmt.Sprintf("%s.%s:%d", brokerService.Name, brokerService.Namespace, kafkaBrokerPort)
+	}
+
 	// Reconcile deployment for gateway
 	gatewayDeployment, err := r.reconcileGatewayDeployment(ctx, log, kafkaProvider, &cm)
 	if err != nil {
@@ -146,8 +190,51 @@ func (r *KafkaProviderReconciler) reconcile(ctx context.Context, log logr.Logger
 	kafkaProvider.Status.ProvisionerServiceRef = refs.NewTypedLocalObjectReferenceForObject(provisionerService, r.Scheme)
 	kafkaProvider.Status.PropagateProvisionerServiceStatus(&provisionerService.Status)
 
-	return ctrl.Result{}, nil
+	// Check broker connectivity through the provisioner
+	if !kafkaProvider.Status.GetCondition(streamingv1alpha1.KafkaProviderConditionProvisionerDeploymentReady).IsTrue() {
+		kafkaProvider.Status.MarkBrokerReachableUnknown("ProvisionerNotReady", "waiting for the provisioner to check broker connectivity")
+	} else if err := r.checkBroker(kafkaProvider, provisionerService); err == ErrHealthCheckNotSupported {
+		kafkaProvider.Status.MarkBrokerReachabilityNotChecked("HealthCheckNotSupported", "%s", err)
+	} else if err != nil {
+		log.Info("broker is unreachable", "bootstrapServers", kafkaProvider.Status.BootstrapServers, "error", err.Error())
+		kafkaProvider.Status.MarkBrokerUnreachable("%s", err)
+	} else {
+		kafkaProvider.Status.MarkBrokerReachable()
+	}
+
+	// check again on a schedule, brokers may become unreachable at any time
+	return ctrl.Result{RequeueAfter: r.HealthCheckInterval}, nil
+
+}
+
+// checkBroker probes broker connectivity through the provisioner at most once
+// per health check interval, reconciles triggered by child resources reuse the
+// last result rather than blocking on the provisioner.
+func (r *KafkaProviderReconciler) checkBroker(kafkaProvider *streamingv1alpha1.KafkaProvider, provisionerService *corev1.Service) error {
+	key := types.NamespacedName{Namespace: kafkaProvider.Namespace, Name: kafkaProvider.Name}
+
+	r.brokerHealthLock.Lock()
+	last, ok := r.brokerHealth[key]
+	r.brokerHealthLock.Unlock()
+	if ok && time.Since(last.checked) < r.HealthCheckInterval {
+		return last.err
+	}
+
+	err := r.HealthClient.CheckBroker(provisionerService)
+
+	r.brokerHealthLock.Lock()
+	defer r.brokerHealthLock.Unlock()
+	if r.brokerHealth == nil {
+		r.brokerHealth = map[types.NamespacedName]brokerHealthCheck{}
+	}
+	r.brokerHealth[key] = brokerHealthCheck{checked: time.Now(), err: err}
+	return err
+}
 
+func (r *KafkaProviderReconciler) forgetBrokerHealth(key types.NamespacedName) {
+	r.brokerHealthLock.Lock()
+	defer r.brokerHealthLock.Unlock()
+	delete(r.brokerHealth, key)
 }
 
 func (r *KafkaProviderReconciler) reconcileGatewayDeployment(ctx context.Context, log logr.Logger, kafkaProvider *streamingv1alpha1.KafkaProvider, cm *corev1.ConfigMap) (*appsv1.Deployment, error) {
@@ -270,7 +357,7 @@ func (r *KafkaProviderReconciler) constructGatewayDeploymentForKafkaProvider(kaf
 
 func (r *KafkaProviderReconciler) gatewayEnvironmentForKafkaProvider(kafkaProvider *streamingv1alpha1.KafkaProvider) ([]corev1.EnvVar, error) {
 	return []corev1.EnvVar{
-		{Name: "kafka_bootstrapServers", Value: kafkaProvider.Spec.BootstrapServers},
+		{Name: "kafka_bootstrapServers", Value: kafkaProvider.Status.BootstrapServers},
 		{Name: "storage_positions_type", Value: "MEMORY"},
 		{Name: "storage_records_type", Value: "KAFKA"},
 	}, nil
@@ -470,7 +557,7 @@ func (r *KafkaProviderReconciler) constructProvisionerDeploymentForKafkaProvider
 
 	env := []corev1.EnvVar{
 		{Name: "GATEWAY", Value: fmt.Sprintf("%s.%s:6565", kafkaProvider.Status.GatewayServiceRef.Name, kafkaProvider.Namespace)}, // TODO get port number from svc lookup?
-		{Name: "BROKER", Value: kafkaProvider.Spec.BootstrapServers},
+		{Name: "BROKER", Value: kafkaProvider.Status.BootstrapServers},
 	}
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -616,6 +703,287 @@ func (r *KafkaProviderReconciler) constructProvisionerLabelsForKafkaProvider(kaf
 	return labels
 }
 
+func (r *KafkaProviderReconciler) reconcileBrokerStatefulSet(ctx context.Context, log logr.Logger, kafkaProvider *streamingv1alpha1.KafkaProvider, cm *corev1.ConfigMap) (*appsv1.StatefulSet, error) {
+	var actualStatefulSet appsv1.StatefulSet
+	var childStatefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &childStatefulSets,
+		client.InNamespace(kafkaProvider.Namespace),
+		client.MatchingLabels(map[string]string{streamingv1alpha1.KafkaProviderBrokerLabelKey: kafkaProvider.Name}),
+		client.MatchingField(kafkaProviderStatefulSetIndexField, kafkaProvider.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childStatefulSets.Items) == 1 {
+		actualStatefulSet = childStatefulSets.Items[0]
+	} else if len(childStatefulSets.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraStatefulSet := range childStatefulSets.Items {
+			log.Info("deleting extra broker statefulset", "statefulset", extraStatefulSet)
+			if err := r.Delete(ctx, &extraStatefulSet); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredStatefulSet, err := r.constructBrokerStatefulSetForKafkaProvider(kafkaProvider, cm)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete statefulset if no longer needed
+	if desiredStatefulSet == nil {
+		if actualStatefulSet.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting broker statefulset", "statefulset", actualStatefulSet)
+		if err := r.Delete(ctx, &actualStatefulSet); err != nil {
+			log.Error(err, "unable to delete broker StatefulSet for KafkaProvider", "statefulset", actualStatefulSet)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create statefulset if it doesn't exist
+	if actualStatefulSet.Name == "" {
+		log.Info("creating broker statefulset", "spec", desiredStatefulSet.Spec)
+		if err := r.Create(ctx, desiredStatefulSet); err != nil {
+			log.Error(err, "unable to create broker StatefulSet for KafkaProvider", "statefulset", desiredStatefulSet)
+			return nil, err
+		}
+		return desiredStatefulSet, nil
+	}
+
+	if r.statefulSetSemanticEquals(desiredStatefulSet, &actualStatefulSet) {
+		// statefulset is unchanged
+		return &actualStatefulSet, nil
+	}
+
+	// update statefulset with desired changes
+	statefulSet := actualStatefulSet.DeepCopy()
+	statefulSet.ObjectMeta.Labels = desiredStatefulSet.ObjectMeta.Labels
+	statefulSet.Spec.Replicas = desiredStatefulSet.Spec.Replicas
+	statefulSet.Spec.Template = desiredStatefulSet.Spec.Template
+	log.Info("reconciling broker statefulset", "diff", cmp.Diff(actualStatefulSet.Spec, statefulSet.Spec))
+	if err := r.Update(ctx, statefulSet); err != nil {
+		log.Error(err, "unable to update broker StatefulSet for KafkaProvider", "statefulset", statefulSet)
+		return nil, err
+	}
+
+	return statefulSet, nil
+}
+
+func (r *KafkaProviderReconciler) statefulSetSemanticEquals(desiredStatefulSet, statefulSet *appsv1.StatefulSet) bool {
+	return equality.Semantic.DeepEqual(desiredStatefulSet.Spec, statefulSet.Spec) &&
+		equality.Semantic.DeepEqual(desiredStatefulSet.ObjectMeta.Labels, statefulSet.ObjectMeta.Labels)
+}
+
+func (r *KafkaProviderReconciler) constructBrokerStatefulSetForKafkaProvider(kafkaProvider *streamingv1alpha1.KafkaProvider, cm *corev1.ConfigMap) (*appsv1.StatefulSet, error) {
+	if kafkaProvider.Spec.BootstrapServers != "" {
+		// the provider connects to existing brokers
+		return nil, nil
+	}
+
+	brokerImg := cm.Data[brokerImageKey]
+	if brokerImg == "" {
+		return nil, fmt.Errorf("missing broker image configuration")
+	}
+
+	labels := r.constructBrokerLabelsForKafkaProvider(kafkaProvider)
+	serviceName := r.brokerServiceNameForKafkaProvider(kafkaProvider)
+	replicas := int32(1)
+
+	env := []corev1.EnvVar{
+		// run a single node as both controller and broker, without zookeeper
+		{Name: "KAFKA_CFG_NODE_ID", Value: "0"},
+		{Name: "KAFKA_CFG_PROCESS_ROLES", Value: "controller,broker"},
+		{Name: "KAFKA_CFG_CONTROLLER_QUORUM_VOTERS", Value: "0@localhost:9093"},
+		{Name: "KAFKA_CFG_CONTROLLER_LISTENER_NAMES", Value: "CONTROLLER"},
+		{Name: "KAFKA_CFG_LISTENERS", Value: fmt.Sprintf("PLAINTEXT://:%d,CONTROLLER://:9093", kafkaBrokerPort)},
+		{Name: "KAFKA_CFG_ADVERTISED_LISTENERS", Value: fmt.Sprintf("PLAINTEXT://%s.%s:%d", serviceName, kafkaProvider.Namespace, kafkaBrokerPort)},
+		{Name: "KAFKA_CFG_LISTENER_SECURITY_PROTOCOL_MAP", Value: "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT"},
+		{Name: "KAFKA_CFG_OFFSETS_TOPIC_REPLICATION_FACTOR", Value: "1"},
+		{Name: "KAFKA_CFG_TRANSACTION_STATE_LOG_REPLICATION_FACTOR", Value: "1"},
+		{Name: "KAFKA_CFG_TRANSACTION_STATE_LOG_MIN_ISR", Value: "1"},
+		{Name: "ALLOW_PLAINTEXT_LISTENER", Value: "yes"},
+	}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-kafka-broker-", kafkaProvider.Name),
+			Namespace:    kafkaProvider.Namespace,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: serviceName,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.KafkaProviderBrokerLabelKey: kafkaProvider.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "broker",
+							Image:           brokerImg,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Ports: []corev1.ContainerPort{
+								{Name: "kafka", ContainerPort: kafkaBrokerPort},
+							},
+							Env: env,
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									TCPSocket: &corev1.TCPSocketAction{
+										Port: intstr.FromInt(kafkaBrokerPort),
+									},
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: "/bitnami/kafka"},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							// development brokers don't retain data across restarts
+							Name:         "data",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(kafkaProvider, statefulSet, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return statefulSet, nil
+}
+
+func (r *KafkaProviderReconciler) reconcileBrokerService(ctx context.Context, log logr.Logger, kafkaProvider *streamingv1alpha1.KafkaProvider) (*corev1.Service, error) {
+	var actualService corev1.Service
+	var childServices corev1.ServiceList
+	if err := r.List(ctx, &childServices,
+		client.InNamespace(kafkaProvider.Namespace),
+		client.MatchingLabels(map[string]string{streamingv1alpha1.KafkaProviderBrokerLabelKey: kafkaProvider.Name}),
+		client.MatchingField(kafkaProviderServiceIndexField, kafkaProvider.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childServices.Items) == 1 {
+		actualService = childServices.Items[0]
+	} else if len(childServices.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraService := range childServices.Items {
+			log.Info("deleting extra broker service", "service", extraService)
+			if err := r.Delete(ctx, &extraService); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredService, err := r.constructBrokerServiceForKafkaProvider(kafkaProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete service if no longer needed
+	if desiredService == nil {
+		if actualService.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting broker service", "service", actualService)
+		if err := r.Delete(ctx, &actualService); err != nil {
+			log.Error(err, "unable to delete broker Service for KafkaProvider", "service", actualService)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create service if it doesn't exist
+	if actualService.Name == "" {
+		log.Info("creating broker service", "spec", desiredService.Spec)
+		if err := r.Create(ctx, desiredService); err != nil {
+			log.Error(err, "unable to create broker Service for KafkaProvider", "service", desiredService)
+			return nil, err
+		}
+		return desiredService, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredService.Spec.ClusterIP = actualService.Spec.ClusterIP
+
+	if r.serviceSemanticEquals(desiredService, &actualService) {
+		// service is unchanged
+		return &actualService, nil
+	}
+
+	// update service with desired changes
+	service := actualService.DeepCopy()
+	service.ObjectMeta.Labels = desiredService.ObjectMeta.Labels
+	service.Spec = desiredService.Spec
+	log.Info("reconciling broker service", "diff", cmp.Diff(actualService.Spec, service.Spec))
+	if err := r.Update(ctx, service); err != nil {
+		log.Error(err, "unable to update broker Service for KafkaProvider", "service", service)
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *KafkaProviderReconciler) constructBrokerServiceForKafkaProvider(kafkaProvider *streamingv1alpha1.KafkaProvider) (*corev1.Service, error) {
+	if kafkaProvider.Spec.BootstrapServers != "" {
+		// the provider connects to existing brokers
+		return nil, nil
+	}
+
+	labels := r.constructBrokerLabelsForKafkaProvider(kafkaProvider)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      labels,
+			Annotations: make(map[string]string),
+			Name:        r.brokerServiceNameForKafkaProvider(kafkaProvider),
+			Namespace:   kafkaProvider.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "kafka", Port: kafkaBrokerPort},
+			},
+			Selector: map[string]string{
+				streamingv1alpha1.KafkaProviderBrokerLabelKey: kafkaProvider.Name,
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(kafkaProvider, service, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *KafkaProviderReconciler) brokerServiceNameForKafkaProvider(kafkaProvider *streamingv1alpha1.KafkaProvider) string {
+	return fmt.Sprintf("%s-kafka-broker", kafkaProvider.Name)
+}
+
+func (r *KafkaProviderReconciler) constructBrokerLabelsForKafkaProvider(kafkaProvider *streamingv1alpha1.KafkaProvider) map[string]string {
+	labels := make(map[string]string, len(kafkaProvider.ObjectMeta.Labels)+2)
+	// pass through existing labels
+	for k, v := range kafkaProvider.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[streamingv1alpha1.KafkaProviderLabelKey] = kafkaProvider.Name
+	labels[streamingv1alpha1.KafkaProviderBrokerLabelKey] = kafkaProvider.Name
+
+	return labels
+}
+
 func (r *KafkaProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	enqueueTrackedResources := &handler.EnqueueRequestsFromMapFunc{
 		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
@@ -639,11 +1007,15 @@ func (r *KafkaProviderReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := controllers.IndexControllersOfType(mgr, kafkaProviderServiceIndexField, &streamingv1alpha1.KafkaProvider{}, &corev1.Service{}); err != nil {
 		return err
 	}
+	if err := controllers.IndexControllersOfType(mgr, kafkaProviderStatefulSetIndexField, &streamingv1alpha1.KafkaProvider{}, &appsv1.StatefulSet{}); err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&streamingv1alpha1.KafkaProvider{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
+		Owns(&appsv1.StatefulSet{}).
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources).
 		Complete(r)
 }
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+type countingHealthClient struct {
+	calls int
+	err   error
+}
+
+func (c *countingHealthClient) CheckBroker(service *corev1.Service) error {
+	c.calls++
+	return c.err
+}
+
+func TestKafkaProviderReconcilerCheckBroker(t *testing.T) {
+	provider := &streamingv1alpha1.KafkaProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: "my-provider"},
+	}
+	service := &corev1.Service{}
+	healthClient := &countingHealthClient{err: errors.New("no brokers available")}
+	r := &KafkaProviderReconciler{
+		HealthClient:        healthClient,
+		HealthCheckInterval: time.Hour,
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := r.checkBroker(provider, service); err != healthClient.err {
+			t.Errorf("checkBroker() expected error %v, got %v", healthClient.err, err)
+		}
+	}
+	if healthClient.calls != 1 {
+		t.Errorf("checkBroker() expected the provisioner to be probed once per interval, got %d probes", healthClient.calls)
+	}
+
+	r.forgetBrokerHealth(types.NamespacedName{Namespace: provider.Namespace, Name: provider.Name})
+	healthClient.err = nil
+	if err := r.checkBroker(provider, service); err != nil {
+		t.Errorf("checkBroker() unexpected error %v", err)
+	}
+	if healthClient.calls != 2 {
+		t.Errorf("checkBroker() expected the provisioner to be probed again once forgotten, got %d probes", healthClient.calls)
+	}
+
+	r.HealthCheckInterval = 0
+	if err := r.checkBroker(provider, service); err != nil {
+		t.Errorf("checkBroker() unexpected error %v", err)
+	}
+	if healthClient.calls != 3 {
+		t.Errorf("checkBroker() expected the provisioner to be probed again after the interval, got %d probes", healthClient.calls)
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -53,13 +55,23 @@ import (
 
 const (
 	processorDeploymentIndexField   = ".metadata.processorDeploymentController"
+	processorStatefulSetIndexField  = ".metadata.processorStatefulSetController"
 	processorScaledObjectIndexField = ".metadata.processorScaledObjectController"
+	processorTriggerAuthIndexField  = ".metadata.processorTriggerAuthController"
+	processorHPAIndexField          = ".metadata.processorHPAController"
+	scaledObjectJobIndexField       = ".metadata.scaledObjectJobController"
 )
 
 const (
 	bindingsRootPath = "/var/riff/bindings"
+	stateRootPath    = "/var/riff/state"
 )
 
+// gatewayCredentialKeys are the keys of a stream binding secret that, when
+// present, hold credentials required to connect to a secured gateway. They
+// are exposed to the KEDA scaler as trigger parameters of the same name.
+var gatewayCredentialKeys = []string{"username", "password", "token", "ca.crt"}
+
 // ProcessorReconciler reconciles a Processor object
 type ProcessorReconciler struct {
 	client.Client
@@ -67,6 +79,11 @@ type ProcessorReconciler struct {
 	Scheme    *runtime.Scheme
 	Tracker   tracker.Tracker
 	Namespace string
+
+	// KEDAEnabled is true when the KEDA CRDs are installed. Without KEDA,
+	// processors fall back to a HorizontalPodAutoscaler or a fixed number
+	// of replicas.
+	KEDAEnabled bool
 }
 
 // For
@@ -74,12 +91,18 @@ type ProcessorReconciler struct {
 // +kubebuilder:rbac:groups=streaming.projectriff.io,resources=processors/status,verbs=get;update;patch
 // Owns
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=keda.k8s.io,resources=scaledobjects,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=keda.k8s.io,resources=triggerauthentications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 // Watches
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=kafkaproviders,verbs=get;watch
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=containers,verbs=get;watch
 // +kubebuilder:rbac:groups=build.projectriff.io,resources=functions,verbs=get;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
 
 func (r *ProcessorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -121,6 +144,16 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 
 	processor.Status.InitializeConditions()
 
+	// run as the namespace's runtime service account by default
+	if processor.Spec.Template.Spec.ServiceAccountName == "" {
+		serviceAccountName, err := controllers.ResolveRuntimeServiceAccount(ctx, r.Client, r.Tracker, processor)
+		if err != nil {
+			logger.Error(err, "unable to resolve runtime ServiceAccount", "processor", processor)
+			return ctrl.Result{}, err
+		}
+		processor.Spec.Template.Spec.ServiceAccountName = serviceAccountName
+	}
+
 	processorNSName := namespacedNamedFor(processor)
 
 	// Lookup and track configMap to know which images to use
@@ -136,6 +169,7 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 	}
 
 	// resolve image
+	invoker := ""
 	if processor.Spec.Build != nil {
 		if processor.Spec.Build.FunctionRef != "" {
 			functionNSName := types.NamespacedName{Namespace: processor.Namespace, Name: processor.Spec.Build.FunctionRef}
@@ -153,6 +187,7 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 			}
 
 			processor.Status.LatestImage = function.Status.LatestImage
+			invoker = function.Spec.Invoker
 
 		} else if processor.Spec.Build.ContainerRef != "" {
 			containerNSName := types.NamespacedName{Namespace: processor.Namespace, Name: processor.Spec.Build.ContainerRef}
@@ -180,6 +215,13 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 		return ctrl.Result{}, fmt.Errorf("could not resolve an image")
 	}
 
+	// the defaulter guarantees an invocation
+	if errs := processor.Spec.Invocation.ValidateInvoker(invoker); len(errs) != 0 {
+		processor.Status.MarkFunctionNotReady("UnsupportedProtocol", "The %q invoker does not support the %q protocol.", invoker, processor.Spec.Invocation.Protocol)
+		return ctrl.Result{}, nil
+	}
+	processor.Status.MarkFunctionReady()
+
 	// Resolve input addresses
 	inputStreams, err := r.resolveStreams(ctx, processorNSName, processor.Spec.Inputs)
 	if err != nil {
@@ -195,15 +237,6 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 	processor.Status.DeprecatedOutputAddresses = r.collectStreamAddresses(outputStreams)
 	processor.Status.DeprecatedOutputContentTypes = r.collectStreamContentTypes(outputStreams)
 
-	// Reconcile deployment for processor
-	deployment, err := r.reconcileProcessorDeployment(ctx, logger, processor, inputStreams, outputStreams, &cm)
-	if err != nil {
-		logger.Error(err, "unable to reconcile deployment")
-		return ctrl.Result{}, err
-	}
-	processor.Status.DeploymentRef = refs.NewTypedLocalObjectReferenceForObject(deployment, r.Scheme)
-	processor.Status.PropagateDeploymentStatus(&deployment.Status)
-
 	processor.Status.MarkStreamsReady()
 	streams := []streamingv1alpha1.Stream{}
 	streams = append(streams, inputStreams...)
@@ -219,8 +252,104 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 		}
 	}
 
+	// Enforce stream access, processors must not publish to or consume from
+	// streams that do not allow them
+	accessDenied := false
+	if message := r.checkStreamAccess(processor, inputStreams, outputStreams); message != "" {
+		processor.Status.MarkStreamAccessDenied(message)
+		accessDenied = true
+	}
+
+	// Select how the processor is scaled
+	backend := r.resolveScalingBackend(processor, accessDenied)
+
+	// Reconcile deployment for processor
+	deployment, err := r.reconcileProcessorDeployment(ctx, logger, processor, inputStreams, outputStreams, &cm, backend)
+	if err != nil {
+		logger.Error(err, "unable to reconcile deployment")
+		return ctrl.Result{}, err
+	}
+
+	// Reconcile statefulSet for processor
+	statefulSet, err := r.reconcileProcessorStatefulSet(ctx, logger, processor, inputStreams, outputStreams, &cm)
+	if err != nil {
+		logger.Error(err, "unable to reconcile statefulSet")
+		return ctrl.Result{}, err
+	}
+
+	processor.Status.DeploymentRef = nil
+	processor.Status.StatefulSetRef = nil
+	if deployment != nil {
+		processor.Status.DeploymentRef = refs.NewTypedLocalObjectReferenceForObject(deployment, r.Scheme)
+		processor.Status.PropagateDeploymentStatus(&deployment.Status)
+	} else if statefulSet != nil {
+		processor.Status.StatefulSetRef = refs.NewTypedLocalObjectReferenceForObject(statefulSet, r.Scheme)
+		processor.Status.PropagateStatefulSetStatus(&statefulSet.Status)
+	} else {
+		processor.Status.MarkDeploymentNotRequired()
+	}
+
+	// Reconcile horizontalPodAutoscaler for processor
+	hpa, err := r.reconcileProcessorHorizontalPodAutoscaler(ctx, logger, processor, deployment, backend)
+	if err != nil {
+		logger.Error(err, "unable to reconcile horizontalPodAutoscaler")
+		return ctrl.Result{}, err
+	}
+	if hpa != nil {
+		processor.Status.HorizontalPodAutoscalerRef = refs.NewTypedLocalObjectReferenceForObject(hpa, r.Scheme)
+	} else {
+		processor.Status.HorizontalPodAutoscalerRef = nil
+	}
+
+	if backend != streamingv1alpha1.ScalingBackendKEDA {
+		if r.KEDAEnabled {
+			// remove the scaledObject left over from a previous backend
+			if _, err := r.reconcileProcessorScaledObject(ctx, logger, processor, nil, nil, nil); err != nil {
+				logger.Error(err, "unable to reconcile scaledObject")
+				return ctrl.Result{}, err
+			}
+			// and the triggerAuthentications it referenced
+			if _, err := r.reconcileProcessorTriggerAuthentications(ctx, logger, processor, nil); err != nil {
+				logger.Error(err, "unable to reconcile triggerAuthentications")
+				return ctrl.Result{}, err
+			}
+		}
+		processor.Status.ScaledObjectRef = nil
+		processor.Status.Jobs = nil
+		if processor.Spec.Mode == streamingv1alpha1.ProcessorModeJob && !r.KEDAEnabled {
+			processor.Status.MarkScaledObjectNotAvailable("KEDA is required to run processors in job mode.")
+		} else {
+			processor.Status.MarkScaledObjectNotRequired()
+		}
+
+		processor.Status.ObservedGeneration = processor.Generation
+
+		return ctrl.Result{}, nil
+	}
+
+	// Reconcile triggerAuthentications for inputs on secured gateways
+	triggerAuths, err := r.reconcileProcessorTriggerAuthentications(ctx, logger, processor, inputStreams)
+	if err != nil {
+		logger.Error(err, "unable to reconcile triggerAuthentications")
+		return ctrl.Result{}, err
+	}
+
+	// Resolve triggers to scale the processor
+	scaleTriggers, err := r.resolveScaleTriggers(ctx, processor, inputStreams, triggerAuths)
+	if err != nil {
+		logger.Error(err, "unable to resolve scale triggers")
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	// Construct the jobs spawned in job mode
+	jobSpec, err := r.constructJobSpecForProcessor(processor, inputStreams, outputStreams, &cm)
+	if err != nil {
+		logger.Error(err, "unable to construct job spec")
+		return ctrl.Result{}, err
+	}
+
 	// Reconcile scaledObject for processor
-	scaledObject, err := r.reconcileProcessorScaledObject(ctx, logger, processor, deployment)
+	scaledObject, err := r.reconcileProcessorScaledObject(ctx, logger, processor, deployment, jobSpec, scaleTriggers)
 	if err != nil {
 		logger.Error(err, "unable to reconcile scaledObject")
 		return ctrl.Result{}, err
@@ -228,12 +357,24 @@ func (r *ProcessorReconciler) reconcile(ctx context.Context, logger logr.Logger,
 	processor.Status.ScaledObjectRef = refs.NewTypedLocalObjectReferenceForObject(scaledObject, r.Scheme)
 	processor.Status.PropagateScaledObjectStatus(&scaledObject.Status)
 
+	// Summarize jobs spawned by the scaledObject
+	if jobSpec != nil {
+		jobs, err := r.summarizeProcessorJobs(ctx, processor, scaledObject)
+		if err != nil {
+			logger.Error(err, "unable to summarize jobs")
+			return ctrl.Result{Requeue: true}, err
+		}
+		processor.Status.Jobs = jobs
+	} else {
+		processor.Status.Jobs = nil
+	}
+
 	processor.Status.ObservedGeneration = processor.Generation
 
 	return ctrl.Result{}, nil
 }
 
-func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment) (*kedav1alpha1.ScaledObject, error) {
+func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, jobSpec *batchv1.JobSpec, scaleTriggers []kedav1alpha1.ScaleTriggers) (*kedav1alpha1.ScaledObject, error) {
 	var actualScaledObject kedav1alpha1.ScaledObject
 	var childScaledObjects kedav1alpha1.ScaledObjectList
 	if err := r.List(ctx, &childScaledObjects, client.InNamespace(processor.Namespace), client.MatchingField(processorScaledObjectIndexField, processor.Name)); err != nil {
@@ -252,13 +393,16 @@ func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context
 		}
 	}
 
-	desiredScaledObject, err := r.constructScaledObjectForProcessor(processor, deployment)
+	desiredScaledObject, err := r.constructScaledObjectForProcessor(processor, deployment, jobSpec, scaleTriggers)
 	if err != nil {
 		return nil, err
 	}
 
 	// delete scaledObject if no longer needed
 	if desiredScaledObject == nil {
+		if actualScaledObject.Name == "" {
+			return nil, nil
+		}
 		if err := r.Delete(ctx, &actualScaledObject); err != nil {
 			log.Error(err, "unable to delete ScaledObject for Processor", "scaledObject", actualScaledObject)
 			return nil, err
@@ -295,15 +439,18 @@ func (r *ProcessorReconciler) reconcileProcessorScaledObject(ctx context.Context
 	return scaledObject, nil
 }
 
-func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment) (*kedav1alpha1.ScaledObject, error) {
+func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, jobSpec *batchv1.JobSpec, scaleTriggers []kedav1alpha1.ScaleTriggers) (*kedav1alpha1.ScaledObject, error) {
+	if deployment == nil && jobSpec == nil {
+		// nothing to scale
+		return nil, nil
+	}
+
 	labels := r.constructLabelsForProcessor(processor)
 
 	zero := int32(0)
 	one := int32(1)
 	thirty := int32(30)
 
-	labels["deploymentName"] = deployment.Name
-
 	maxReplicas := thirty
 	if processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsFalse() {
 		// scale to zero while dependencies are not ready
@@ -317,17 +464,26 @@ func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *strea
 			Labels:       labels,
 		},
 		Spec: kedav1alpha1.ScaledObjectSpec{
-			ScaleTargetRef: &kedav1alpha1.ObjectReference{
-				DeploymentName: deployment.Name,
-			},
 			PollingInterval: &one,
 			CooldownPeriod:  &thirty,
-			Triggers:        triggers(processor),
+			Triggers:        scaleTriggers,
 			MinReplicaCount: &one,
 			MaxReplicaCount: &maxReplicas,
 		},
 	}
 
+	if jobSpec != nil {
+		// jobs are spawned on demand, there is nothing to keep warm
+		scaledObject.Spec.ScaleType = kedav1alpha1.ScaleTypeJob
+		scaledObject.Spec.JobTargetRef = jobSpec
+		scaledObject.Spec.MinReplicaCount = &zero
+	} else {
+		labels["deploymentName"] = deployment.Name
+		scaledObject.Spec.ScaleTargetRef = &kedav1alpha1.ObjectReference{
+			DeploymentName: deployment.Name,
+		}
+	}
+
 	if err := ctrl.SetControllerReference(processor, scaledObject, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -335,7 +491,7 @@ func (r *ProcessorReconciler) constructScaledObjectForProcessor(processor *strea
 	return scaledObject, nil
 }
 
-func triggers(proc *streamingv1alpha1.Processor) []kedav1alpha1.ScaleTriggers {
+func triggers(proc *streamingv1alpha1.Processor, triggerAuths []*kedav1alpha1.TriggerAuthentication) []kedav1alpha1.ScaleTriggers {
 	result := make([]kedav1alpha1.ScaleTriggers, len(proc.Status.DeprecatedInputAddresses))
 	for i, topic := range proc.Status.DeprecatedInputAddresses {
 		result[i].Type = "liiklus"
@@ -344,16 +500,244 @@ func triggers(proc *streamingv1alpha1.Processor) []kedav1alpha1.ScaleTriggers {
 			"group":   proc.Name,
 			"topic":   strings.SplitN(topic, "/", 2)[1],
 		}
+		if i < len(triggerAuths) && triggerAuths[i] != nil {
+			result[i].AuthenticationRef = &kedav1alpha1.ScaledObjectAuthRef{
+				Name: triggerAuths[i].Name,
+			}
+		}
+	}
+	return result
+}
+
+// resolveScaleTriggers selects the KEDA triggers for the processor inputs. The
+// native kafka trigger is used when requested by the scaler policy and all
+// inputs are provided by a KafkaProvider, otherwise the liiklus trigger is
+// used.
+func (r *ProcessorReconciler) resolveScaleTriggers(ctx context.Context, processor *streamingv1alpha1.Processor, inputStreams []streamingv1alpha1.Stream, triggerAuths []*kedav1alpha1.TriggerAuthentication) ([]kedav1alpha1.ScaleTriggers, error) {
+	if processor.Spec.ScalerPolicy != streamingv1alpha1.ScalerPolicyNative {
+		processor.Status.MarkNativeScalerNotUsed("liiklus", "NotRequested", "The scaler policy is %q.", processor.Spec.ScalerPolicy)
+		return triggers(processor, triggerAuths), nil
+	}
+
+	kafkaProviders := make([]streamingv1alpha1.KafkaProvider, len(inputStreams))
+	for i, stream := range inputStreams {
+		kafkaProvider, err := r.resolveKafkaProvider(ctx, processor, stream)
+		if err != nil {
+			return nil, err
+		}
+		if kafkaProvider == nil {
+			// the reason has been recorded on the processor status
+			return triggers(processor, triggerAuths), nil
+		}
+		kafkaProviders[i] = *kafkaProvider
+	}
+
+	processor.Status.MarkNativeScaler("kafka")
+	return kafkaTriggers(processor, inputStreams, kafkaProviders, triggerAuths), nil
+}
+
+// resolveKafkaProvider returns the KafkaProvider backing the stream. The
+// provider is found through the controller of the stream's provisioner
+// service. When the stream is provided by something else, or the provider
+// can not be found, nil is returned and the processor status records why the
+// native scaler is not used.
+func (r *ProcessorReconciler) resolveKafkaProvider(ctx context.Context, processor *streamingv1alpha1.Processor, stream streamingv1alpha1.Stream) (*streamingv1alpha1.KafkaProvider, error) {
+	var provisioner corev1.Service
+	provisionerNSName := types.NamespacedName{Namespace: stream.Namespace, Name: stream.Spec.Provider}
+	if err := r.Get(ctx, provisionerNSName, &provisioner); err != nil {
+		if errors.IsNotFound(err) {
+			processor.Status.MarkNativeScalerNotUsed("liiklus", "ProvisionerNotFound", "Provisioner service %q for stream %q was not found.", stream.Spec.Provider, stream.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	kafkaProviderName := ""
+	if owner := metav1.GetControllerOf(&provisioner); owner != nil {
+		if gv, err := schema.ParseGroupVersion(owner.APIVersion); err == nil && gv.Group == streamingv1alpha1.GroupVersion.Group && owner.Kind == "KafkaProvider" {
+			kafkaProviderName = owner.Name
+		}
+	}
+	if kafkaProviderName == "" {
+		kafkaProviderName = provisioner.Labels[streamingv1alpha1.KafkaProviderProvisionerLabelKey]
+	}
+	if kafkaProviderName == "" {
+		processor.Status.MarkNativeScalerNotUsed("liiklus", "UnsupportedProvider", "Stream %q is not provided by a KafkaProvider.", stream.Name)
+		return nil, nil
+	}
+
+	var kafkaProvider streamingv1alpha1.KafkaProvider
+	kafkaProviderNSName := types.NamespacedName{Namespace: stream.Namespace, Name: kafkaProviderName}
+	// track provider for new bootstrap servers
+	r.Tracker.Track(
+		tracker.NewKey(kafkaProvider.GetGroupVersionKind(), kafkaProviderNSName),
+		namespacedNamedFor(processor),
+	)
+	if err := r.Get(ctx, kafkaProviderNSName, &kafkaProvider); err != nil {
+		if errors.IsNotFound(err) {
+			processor.Status.MarkNativeScalerNotUsed("liiklus", "KafkaProviderNotFound", "KafkaProvider %q for stream %q was not found.", kafkaProviderName, stream.Name)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &kafkaProvider, nil
+}
+
+func kafkaTriggers(proc *streamingv1alpha1.Processor, inputStreams []streamingv1alpha1.Stream, kafkaProviders []streamingv1alpha1.KafkaProvider, triggerAuths []*kedav1alpha1.TriggerAuthentication) []kedav1alpha1.ScaleTriggers {
+	result := make([]kedav1alpha1.ScaleTriggers, len(inputStreams))
+	for i, stream := range inputStreams {
+		result[i].Type = "kafka"
+		result[i].Metadata = map[string]string{
+			"brokerList":    kafkaProviders[i].Status.BootstrapServers,
+			"consumerGroup": proc.Name,
+			"topic":         stream.Status.Address.Topic,
+		}
+		if i < len(triggerAuths) && triggerAuths[i] != nil {
+			result[i].AuthenticationRef = &kedav1alpha1.ScaledObjectAuthRef{
+				Name: triggerAuths[i].Name,
+			}
+		}
 	}
 	return result
 }
 
+// reconcileProcessorTriggerAuthentications reconciles a TriggerAuthentication
+// for each input stream whose binding secret holds gateway credentials. The
+// returned slice is aligned with the processor inputs, entries are nil for
+// inputs that do not require authentication.
+func (r *ProcessorReconciler) reconcileProcessorTriggerAuthentications(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, inputStreams []streamingv1alpha1.Stream) ([]*kedav1alpha1.TriggerAuthentication, error) {
+	var childTriggerAuths kedav1alpha1.TriggerAuthenticationList
+	if err := r.List(ctx, &childTriggerAuths, client.InNamespace(processor.Namespace), client.MatchingField(processorTriggerAuthIndexField, processor.Name)); err != nil {
+		return nil, err
+	}
+	actualTriggerAuths := map[string]kedav1alpha1.TriggerAuthentication{}
+	for _, triggerAuth := range childTriggerAuths.Items {
+		actualTriggerAuths[triggerAuth.Name] = triggerAuth
+	}
+
+	triggerAuths := make([]*kedav1alpha1.TriggerAuthentication, len(inputStreams))
+	for i, stream := range inputStreams {
+		secret, err := r.resolveBindingSecret(ctx, processor, stream)
+		if err != nil {
+			return nil, err
+		}
+		desiredTriggerAuth, err := r.constructTriggerAuthenticationForProcessor(processor, i, secret)
+		if err != nil {
+			return nil, err
+		}
+		if desiredTriggerAuth == nil {
+			continue
+		}
+
+		actualTriggerAuth, ok := actualTriggerAuths[desiredTriggerAuth.Name]
+		delete(actualTriggerAuths, desiredTriggerAuth.Name)
+
+		// create triggerAuthentication if it doesn't exist
+		if !ok {
+			log.Info("creating trigger authentication", "spec", desiredTriggerAuth.Spec)
+			if err := r.Create(ctx, desiredTriggerAuth); err != nil {
+				log.Error(err, "unable to create TriggerAuthentication for Processor", "triggerAuthentication", desiredTriggerAuth)
+				return nil, err
+			}
+			triggerAuths[i] = desiredTriggerAuth
+			continue
+		}
+
+		if r.triggerAuthenticationSemanticEquals(desiredTriggerAuth, &actualTriggerAuth) {
+			// triggerAuthentication is unchanged
+			triggerAuths[i] = &actualTriggerAuth
+			continue
+		}
+
+		// update triggerAuthentication with desired changes
+		triggerAuth := actualTriggerAuth.DeepCopy()
+		triggerAuth.ObjectMeta.Labels = desiredTriggerAuth.ObjectMeta.Labels
+		triggerAuth.Spec = desiredTriggerAuth.Spec
+		log.Info("reconciling trigger authentication", "diff", cmp.Diff(actualTriggerAuth.Spec, triggerAuth.Spec))
+		if err := r.Update(ctx, triggerAuth); err != nil {
+			log.Error(err, "unable to update TriggerAuthentication for Processor", "triggerAuthentication", triggerAuth)
+			return nil, err
+		}
+		triggerAuths[i] = triggerAuth
+	}
+
+	// delete triggerAuthentications no longer needed
+	for _, extraTriggerAuth := range actualTriggerAuths {
+		log.Info("deleting trigger authentication", "triggerAuthentication", extraTriggerAuth)
+		if err := r.Delete(ctx, &extraTriggerAuth); err != nil {
+			log.Error(err, "unable to delete TriggerAuthentication for Processor", "triggerAuthentication", extraTriggerAuth)
+			return nil, err
+		}
+	}
+
+	return triggerAuths, nil
+}
+
+func (r *ProcessorReconciler) resolveBindingSecret(ctx context.Context, processor *streamingv1alpha1.Processor, stream streamingv1alpha1.Stream) (*corev1.Secret, error) {
+	if stream.Status.Binding.SecretRef.Name == "" {
+		return nil, nil
+	}
+	var secret corev1.Secret
+	secretNSName := types.NamespacedName{Namespace: stream.Namespace, Name: stream.Status.Binding.SecretRef.Name}
+	// changes to the binding secret are mapped to the stream, which is
+	// already tracked by resolveStreams
+	if err := r.Get(ctx, secretNSName, &secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &secret, nil
+}
+
+func (r *ProcessorReconciler) constructTriggerAuthenticationForProcessor(processor *streamingv1alpha1.Processor, input int, secret *corev1.Secret) (*kedav1alpha1.TriggerAuthentication, error) {
+	if secret == nil {
+		return nil, nil
+	}
+	secretTargetRefs := []kedav1alpha1.AuthSecretTargetRef{}
+	for _, key := range gatewayCredentialKeys {
+		if _, ok := secret.Data[key]; !ok {
+			continue
+		}
+		secretTargetRefs = append(secretTargetRefs, kedav1alpha1.AuthSecretTargetRef{
+			Parameter: key,
+			Name:      secret.Name,
+			Key:       key,
+		})
+	}
+	if len(secretTargetRefs) == 0 {
+		// gateway does not require credentials
+		return nil, nil
+	}
+
+	triggerAuth := &kedav1alpha1.TriggerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-processor-input-%03d", processor.Name, input),
+			Namespace: processor.Namespace,
+			Labels:    r.constructLabelsForProcessor(processor),
+		},
+		Spec: kedav1alpha1.TriggerAuthenticationSpec{
+			SecretTargetRef: secretTargetRefs,
+		},
+	}
+	if err := ctrl.SetControllerReference(processor, triggerAuth, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return triggerAuth, nil
+}
+
+func (r *ProcessorReconciler) triggerAuthenticationSemanticEquals(desiredTriggerAuth, triggerAuth *kedav1alpha1.TriggerAuthentication) bool {
+	return equality.Semantic.DeepEqual(desiredTriggerAuth.Spec, triggerAuth.Spec) &&
+		equality.Semantic.DeepEqual(desiredTriggerAuth.ObjectMeta.Labels, triggerAuth.ObjectMeta.Labels)
+}
+
 func (r *ProcessorReconciler) scaledObjectSemanticEquals(desiredDeployment, deployment *kedav1alpha1.ScaledObject) bool {
 	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
 		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
 }
 
-func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, cm *corev1.ConfigMap) (*appsv1.Deployment, error) {
+func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, cm *corev1.ConfigMap, backend streamingv1alpha1.ScalingBackend) (*appsv1.Deployment, error) {
 	var actualDeployment appsv1.Deployment
 	var childDeployments appsv1.DeploymentList
 	if err := r.List(ctx, &childDeployments, client.InNamespace(processor.Namespace), client.MatchingField(processorDeploymentIndexField, processor.Name)); err != nil {
@@ -377,13 +761,16 @@ func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context,
 		return nil, fmt.Errorf("missing processor image configuration")
 	}
 
-	desiredDeployment, err := r.constructDeploymentForProcessor(processor, inputStreams, outputStreams, processorImg)
+	desiredDeployment, err := r.constructDeploymentForProcessor(processor, inputStreams, outputStreams, processorImg, backend)
 	if err != nil {
 		return nil, err
 	}
 
 	// delete deployment if no longer needed
 	if desiredDeployment == nil {
+		if actualDeployment.Name == "" {
+			return nil, nil
+		}
 		if err := r.Delete(ctx, &actualDeployment); err != nil {
 			log.Error(err, "unable to delete Deployment for Processor", "deployment", actualDeployment)
 			return nil, err
@@ -402,7 +789,10 @@ func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context,
 	}
 
 	// overwrite fields that should not be mutated
-	desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+	if backend != streamingv1alpha1.ScalingBackendFixedReplicas {
+		// replicas are managed by the autoscaler
+		desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+	}
 
 	if r.deploymentSemanticEquals(desiredDeployment, &actualDeployment) {
 		// deployment is unchanged
@@ -423,10 +813,437 @@ func (r *ProcessorReconciler) reconcileProcessorDeployment(ctx context.Context,
 	return deployment, nil
 }
 
-func (r *ProcessorReconciler) constructDeploymentForProcessor(processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, processorImg string) (*appsv1.Deployment, error) {
+func (r *ProcessorReconciler) constructDeploymentForProcessor(processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, processorImg string, backend streamingv1alpha1.ScalingBackend) (*appsv1.Deployment, error) {
+	if processor.Spec.Mode == streamingv1alpha1.ProcessorModeJob {
+		// jobs are spawned by the scaledObject instead
+		return nil, nil
+	}
+	if processor.Spec.State != nil {
+		// stateful processors run as a statefulSet instead
+		return nil, nil
+	}
+
 	labels := r.constructLabelsForProcessor(processor)
 
-	zero := int32(0)
+	replicas := int32(0)
+	switch backend {
+	case streamingv1alpha1.ScalingBackendHorizontalPodAutoscaler:
+		// an autoscaler ignores deployments scaled to zero
+		replicas = 1
+	case streamingv1alpha1.ScalingBackendFixedReplicas:
+		if !processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsFalse() {
+			replicas = 1
+		}
+	}
+	template, err := r.constructPodTemplateSpecForProcessor(processor, inputStreams, outputStreams, processorImg)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-processor-", processor.Name),
+			Namespace:    processor.Namespace,
+			Labels:       labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.ProcessorLabelKey: processor.Name,
+				},
+			},
+			Template: *template,
+		},
+	}
+	if err := ctrl.SetControllerReference(processor, deployment, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// resolveScalingBackend selects what scales the processor. Stateful processors
+// are not scaled. KEDA is used when installed. Otherwise, a HorizontalPodAutoscaler scales the deployment on CPU
+// utilization when the function requests CPU and the streams are ready, or a
+// single replica is run.
+func (r *ProcessorReconciler) resolveScalingBackend(processor *streamingv1alpha1.Processor, accessDenied bool) streamingv1alpha1.ScalingBackend {
+	if accessDenied {
+		// fixed replicas scale to zero while streams are not ready
+		processor.Status.MarkScalingBackend(streamingv1alpha1.ScalingBackendFixedReplicas, "Stream access is denied, scaling to zero.")
+		return streamingv1alpha1.ScalingBackendFixedReplicas
+	}
+	if processor.Spec.State != nil {
+		processor.Status.MarkScalingBackend(streamingv1alpha1.ScalingBackendFixedReplicas, "Stateful processors run a fixed number of replicas.")
+		return streamingv1alpha1.ScalingBackendFixedReplicas
+	}
+	if r.KEDAEnabled {
+		processor.Status.MarkScalingBackend(streamingv1alpha1.ScalingBackendKEDA, "")
+		return streamingv1alpha1.ScalingBackendKEDA
+	}
+	// defaulter guarantees a container
+	if _, ok := processor.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]; ok {
+		if processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsFalse() {
+			// an autoscaler never scales below one replica, hold the
+			// deployment at zero until the streams are ready
+			processor.Status.MarkScalingBackend(streamingv1alpha1.ScalingBackendFixedReplicas, "KEDA is not installed and streams are not ready, scaling to zero.")
+			return streamingv1alpha1.ScalingBackendFixedReplicas
+		}
+		processor.Status.MarkScalingBackend(streamingv1alpha1.ScalingBackendHorizontalPodAutoscaler, "KEDA is not installed, scaling on CPU utilization.")
+		return streamingv1alpha1.ScalingBackendHorizontalPodAutoscaler
+	}
+	processor.Status.MarkScalingBackend(streamingv1alpha1.ScalingBackendFixedReplicas, "KEDA is not installed and the function does not request CPU, running a single replica.")
+	return streamingv1alpha1.ScalingBackendFixedReplicas
+}
+
+func (r *ProcessorReconciler) reconcileProcessorHorizontalPodAutoscaler(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, backend streamingv1alpha1.ScalingBackend) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	var actualHPA autoscalingv1.HorizontalPodAutoscaler
+	var childHPAs autoscalingv1.HorizontalPodAutoscalerList
+	if err := r.List(ctx, &childHPAs, client.InNamespace(processor.Namespace), client.MatchingField(processorHPAIndexField, processor.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childHPAs.Items) == 1 {
+		actualHPA = childHPAs.Items[0]
+	} else if len(childHPAs.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraHPA := range childHPAs.Items {
+			log.Info("deleting extra horizontal pod autoscaler", "hpa", extraHPA)
+			if err := r.Delete(ctx, &extraHPA); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredHPA, err := r.constructHorizontalPodAutoscalerForProcessor(processor, deployment, backend)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete hpa if no longer needed
+	if desiredHPA == nil {
+		if actualHPA.Name == "" {
+			return nil, nil
+		}
+		if err := r.Delete(ctx, &actualHPA); err != nil {
+			log.Error(err, "unable to delete HorizontalPodAutoscaler for Processor", "hpa", actualHPA)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create hpa if it doesn't exist
+	if actualHPA.Name == "" {
+		log.Info("creating horizontal pod autoscaler", "spec", desiredHPA.Spec)
+		if err := r.Create(ctx, desiredHPA); err != nil {
+			log.Error(err, "unable to create HorizontalPodAutoscaler for Processor", "hpa", desiredHPA)
+			return nil, err
+		}
+		return desiredHPA, nil
+	}
+
+	if r.hpaSemanticEquals(desiredHPA, &actualHPA) {
+		// hpa is unchanged
+		return &actualHPA, nil
+	}
+
+	// update hpa with desired changes
+
+	hpa := actualHPA.DeepCopy()
+	hpa.ObjectMeta.Labels = desiredHPA.ObjectMeta.Labels
+	hpa.Spec = desiredHPA.Spec
+	log.Info("reconciling horizontal pod autoscaler", "diff", cmp.Diff(actualHPA.Spec, hpa.Spec))
+	if err := r.Update(ctx, hpa); err != nil {
+		log.Error(err, "unable to update HorizontalPodAutoscaler for Processor", "hpa", hpa)
+		return nil, err
+	}
+
+	return hpa, nil
+}
+
+func (r *ProcessorReconciler) constructHorizontalPodAutoscalerForProcessor(processor *streamingv1alpha1.Processor, deployment *appsv1.Deployment, backend streamingv1alpha1.ScalingBackend) (*autoscalingv1.HorizontalPodAutoscaler, error) {
+	if backend != streamingv1alpha1.ScalingBackendHorizontalPodAutoscaler || deployment == nil {
+		return nil, nil
+	}
+
+	labels := r.constructLabelsForProcessor(processor)
+
+	one := int32(1)
+	eighty := int32(80)
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-processor-", processor.Name),
+			Namespace:    processor.Namespace,
+			Labels:       labels,
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deployment.Name,
+			},
+			MinReplicas:                    &one,
+			MaxReplicas:                    30,
+			TargetCPUUtilizationPercentage: &eighty,
+		},
+	}
+
+	if err := ctrl.SetControllerReference(processor, hpa, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return hpa, nil
+}
+
+func (r *ProcessorReconciler) hpaSemanticEquals(desiredHPA, hpa *autoscalingv1.HorizontalPodAutoscaler) bool {
+	return equality.Semantic.DeepEqual(desiredHPA.Spec, hpa.Spec) &&
+		equality.Semantic.DeepEqual(desiredHPA.ObjectMeta.Labels, hpa.ObjectMeta.Labels)
+}
+
+func (r *ProcessorReconciler) reconcileProcessorStatefulSet(ctx context.Context, log logr.Logger, processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, cm *corev1.ConfigMap) (*appsv1.StatefulSet, error) {
+	var actualStatefulSet appsv1.StatefulSet
+	var childStatefulSets appsv1.StatefulSetList
+	if err := r.List(ctx, &childStatefulSets, client.InNamespace(processor.Namespace), client.MatchingField(processorStatefulSetIndexField, processor.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childStatefulSets.Items) == 1 {
+		actualStatefulSet = childStatefulSets.Items[0]
+	} else if len(childStatefulSets.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraStatefulSet := range childStatefulSets.Items {
+			log.Info("deleting extra stateful set", "statefulSet", extraStatefulSet)
+			if err := r.Delete(ctx, &extraStatefulSet); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	processorImg := cm.Data[processorImageKey]
+	if processorImg == "" {
+		return nil, fmt.Errorf("missing processor image configuration")
+	}
+
+	desiredStatefulSet, err := r.constructStatefulSetForProcessor(processor, inputStreams, outputStreams, processorImg)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete statefulSet if no longer needed
+	if desiredStatefulSet == nil {
+		if actualStatefulSet.Name == "" {
+			return nil, nil
+		}
+		// volume claims are retained, the state is lost only once they are deleted
+		if err := r.Delete(ctx, &actualStatefulSet); err != nil {
+			log.Error(err, "unable to delete StatefulSet for Processor", "statefulSet", actualStatefulSet)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create statefulSet if it doesn't exist
+	if actualStatefulSet.Name == "" {
+		log.Info("creating processor stateful set", "spec", desiredStatefulSet.Spec)
+		if err := r.Create(ctx, desiredStatefulSet); err != nil {
+			log.Error(err, "unable to create StatefulSet for Processor", "statefulSet", desiredStatefulSet)
+			return nil, err
+		}
+		return desiredStatefulSet, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredStatefulSet.Spec.VolumeClaimTemplates = actualStatefulSet.Spec.VolumeClaimTemplates
+
+	if r.statefulSetSemanticEquals(desiredStatefulSet, &actualStatefulSet) {
+		// statefulSet is unchanged
+		return &actualStatefulSet, nil
+	}
+
+	// update statefulSet with desired changes
+
+	statefulSet := actualStatefulSet.DeepCopy()
+	statefulSet.ObjectMeta.Labels = desiredStatefulSet.ObjectMeta.Labels
+	statefulSet.Spec.Replicas = desiredStatefulSet.Spec.Replicas
+	statefulSet.Spec.Template = desiredStatefulSet.Spec.Template
+	log.Info("reconciling processor stateful set", "diff", cmp.Diff(actualStatefulSet.Spec, statefulSet.Spec))
+	if err := r.Update(ctx, statefulSet); err != nil {
+		log.Error(err, "unable to update StatefulSet for Processor", "statefulSet", statefulSet)
+		return nil, err
+	}
+
+	return statefulSet, nil
+}
+
+func (r *ProcessorReconciler) constructStatefulSetForProcessor(processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, processorImg string) (*appsv1.StatefulSet, error) {
+	if processor.Spec.State == nil {
+		return nil, nil
+	}
+
+	labels := r.constructLabelsForProcessor(processor)
+
+	replicas := *processor.Spec.State.Replicas
+	if processor.Status.GetCondition(streamingv1alpha1.ProcessorConditionStreamsReady).IsFalse() {
+		// scale to zero while dependencies are not ready
+		replicas = 0
+	}
+
+	template, err := r.constructPodTemplateSpecForProcessor(processor, inputStreams, outputStreams, processorImg)
+	if err != nil {
+		return nil, err
+	}
+	template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      "state",
+		MountPath: stateRootPath,
+	})
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == "processor" {
+			// partitions are assigned to replicas by the ordinal suffixing
+			// the pod name rather than by consumer group rebalancing
+			template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env,
+				corev1.EnvVar{
+					Name:  "PARTITION_ASSIGNMENT",
+					Value: "ordinal",
+				},
+				corev1.EnvVar{
+					Name:  "REPLICAS",
+					Value: fmt.Sprintf("%d", *processor.Spec.State.Replicas),
+				},
+				corev1.EnvVar{
+					Name: "REPLICA_NAME",
+					ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{
+							FieldPath: "metadata.name",
+						},
+					},
+				},
+			)
+		}
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-processor-", processor.Name),
+			Namespace:    processor.Namespace,
+			Labels:       labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.ProcessorLabelKey: processor.Name,
+				},
+			},
+			Template:            *template,
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "state",
+						Labels: r.constructLabelsForProcessor(processor),
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{
+							corev1.ReadWriteOnce,
+						},
+						StorageClassName: processor.Spec.State.StorageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: processor.Spec.State.Size,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(processor, statefulSet, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return statefulSet, nil
+}
+
+func (r *ProcessorReconciler) statefulSetSemanticEquals(desiredStatefulSet, statefulSet *appsv1.StatefulSet) bool {
+	return equality.Semantic.DeepEqual(desiredStatefulSet.Spec, statefulSet.Spec) &&
+		equality.Semantic.DeepEqual(desiredStatefulSet.ObjectMeta.Labels, statefulSet.ObjectMeta.Labels)
+}
+
+func (r *ProcessorReconciler) constructJobSpecForProcessor(processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, cm *corev1.ConfigMap) (*batchv1.JobSpec, error) {
+	if processor.Spec.Mode != streamingv1alpha1.ProcessorModeJob {
+		return nil, nil
+	}
+
+	processorImg := cm.Data[processorImageKey]
+	if processorImg == "" {
+		return nil, fmt.Errorf("missing processor image configuration")
+	}
+
+	template, err := r.constructPodTemplateSpecForProcessor(processor, inputStreams, outputStreams, processorImg)
+	if err != nil {
+		return nil, err
+	}
+	// the processor stops the function once the inputs are drained
+	shareProcessNamespace := true
+	template.Spec.ShareProcessNamespace = &shareProcessNamespace
+	template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == "processor" {
+			template.Spec.Containers[i].Env = append(template.Spec.Containers[i].Env, corev1.EnvVar{
+				Name: "MODE",
+				// the processor expects the mode in lower case
+				Value: "job",
+			})
+		}
+	}
+
+	jobSpec := &batchv1.JobSpec{
+		Template: *template,
+	}
+	if processor.Spec.Job != nil {
+		jobSpec.Completions = processor.Spec.Job.Completions
+		jobSpec.Parallelism = processor.Spec.Job.Parallelism
+	}
+
+	return jobSpec, nil
+}
+
+func (r *ProcessorReconciler) summarizeProcessorJobs(ctx context.Context, processor *streamingv1alpha1.Processor, scaledObject *kedav1alpha1.ScaledObject) (*streamingv1alpha1.ProcessorJobStatus, error) {
+	var jobs batchv1.JobList
+	if err := r.List(ctx, &jobs, client.InNamespace(processor.Namespace), client.MatchingField(scaledObjectJobIndexField, scaledObject.Name)); err != nil {
+		return nil, err
+	}
+
+	summary := &streamingv1alpha1.ProcessorJobStatus{}
+	for _, job := range jobs.Items {
+		if owner := metav1.GetControllerOf(&job); owner.UID != scaledObject.UID {
+			// job of a previous scaledObject with the same name
+			continue
+		}
+		switch {
+		case jobConditionIsTrue(job.Status.Conditions, batchv1.JobComplete):
+			summary.Completed++
+		case jobConditionIsTrue(job.Status.Conditions, batchv1.JobFailed):
+			summary.Failed++
+		default:
+			summary.Active++
+		}
+	}
+
+	return summary, nil
+}
+
+func jobConditionIsTrue(conditions []batchv1.JobCondition, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (r *ProcessorReconciler) constructPodTemplateSpecForProcessor(processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream, processorImg string) (*corev1.PodTemplateSpec, error) {
 	environmentVariables, err := r.computeEnvironmentVariables(processor)
 	if err != nil {
 		return nil, err
@@ -523,9 +1340,11 @@ func (r *ProcessorReconciler) constructDeploymentForProcessor(processor *streami
 	template.Spec.Containers[0].Image = processor.Status.LatestImage
 	template.Spec.Containers[0].Ports = []v1.ContainerPort{
 		{
-			ContainerPort: 8081,
+			ContainerPort: processor.Spec.Invocation.Port,
 		},
 	}
+	// tell the invoker which port to listen on
+	template.Spec.Containers[0].Env = functionPortEnv(template.Spec.Containers[0].Env, processor.Spec.Invocation)
 	template.Spec.Containers = append(template.Spec.Containers, v1.Container{
 		Name:            "processor",
 		Image:           processorImg,
@@ -535,27 +1354,33 @@ func (r *ProcessorReconciler) constructDeploymentForProcessor(processor *streami
 	})
 	template.Spec.Volumes = append(template.Spec.Volumes, volumes...)
 
-	deployment := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-processor-", processor.Name),
-			Namespace:    processor.Namespace,
-			Labels:       labels,
+	return template, nil
+}
+
+// functionPortEnv sets the variables riff invokers read the port of the
+// protocol from. PORT is honored by invokers serving a single protocol.
+// Variables with the same name in env are replaced, the port is controlled by
+// the invocation.
+func functionPortEnv(env []v1.EnvVar, invocation *streamingv1alpha1.ProcessorInvocation) []v1.EnvVar {
+	port := fmt.Sprintf("%d", invocation.Port)
+	portEnv := []v1.EnvVar{
+		{
+			Name:  "PORT",
+			Value: port,
 		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &zero,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					streamingv1alpha1.ProcessorLabelKey: processor.Name,
-				},
-			},
-			Template: *template,
+		{
+			Name:  fmt.Sprintf("%s_PORT", strings.ToUpper(string(invocation.Protocol))),
+			Value: port,
 		},
 	}
-	if err := ctrl.SetControllerReference(processor, deployment, r.Scheme); err != nil {
-		return nil, err
+	result := make([]v1.EnvVar, 0, len(env)+len(portEnv))
+	for _, e := range env {
+		if e.Name == portEnv[0].Name || e.Name == portEnv[1].Name {
+			continue
+		}
+		result = append(result, e)
 	}
-
-	return deployment, nil
+	return append(result, portEnv...)
 }
 
 func (r *ProcessorReconciler) constructLabelsForProcessor(processor *streamingv1alpha1.Processor) map[string]string {
@@ -595,6 +1420,20 @@ func (r *ProcessorReconciler) resolveStreams(ctx context.Context, processorCoord
 	return streams, nil
 }
 
+func (r *ProcessorReconciler) checkStreamAccess(processor *streamingv1alpha1.Processor, inputStreams, outputStreams []streamingv1alpha1.Stream) string {
+	for _, stream := range inputStreams {
+		if !stream.AllowsConsumer(processor) {
+			return fmt.Sprintf("processor is not allowed to consume from stream %s", stream.Name)
+		}
+	}
+	for _, stream := range outputStreams {
+		if !stream.AllowsPublisher(processor) {
+			return fmt.Sprintf("processor is not allowed to publish to stream %s", stream.Name)
+		}
+	}
+	return ""
+}
+
 func (r *ProcessorReconciler) collectStreamAddresses(streams []streamingv1alpha1.Stream) []string {
 	addresses := make([]string, len(streams))
 	for i, stream := range streams {
@@ -618,7 +1457,7 @@ func (r *ProcessorReconciler) computeEnvironmentVariables(processor *streamingv1
 	}
 	inputsNames := r.collectAliases(processor.Spec.Inputs)
 	outputsNames := r.collectAliases(processor.Spec.Outputs)
-	return []v1.EnvVar{
+	env := []v1.EnvVar{
 		{
 			Name:  "CNB_BINDINGS",
 			Value: bindingsRootPath,
@@ -647,14 +1486,39 @@ func (r *ProcessorReconciler) computeEnvironmentVariables(processor *streamingv1
 		},
 		{
 			Name:  "FUNCTION",
-			Value: "localhost:8081",
+			Value: fmt.Sprintf("localhost:%d", processor.Spec.Invocation.Port),
+		},
+		{
+			Name:  "FUNCTION_PROTOCOL",
+			Value: string(processor.Spec.Invocation.Protocol),
 		},
 		{
 			// TODO remove once the processor images consumes bindings
 			Name:  "OUTPUT_CONTENT_TYPES",
 			Value: string(contentTypesJson),
 		},
-	}, nil
+	}
+
+	filtered := false
+	inputFilters := make([]string, len(processor.Spec.Inputs))
+	for i, input := range processor.Spec.Inputs {
+		inputFilters[i] = input.Filter
+		filtered = filtered || input.Filter != ""
+	}
+	if filtered {
+		// messages not matching the filter of their input are acknowledged
+		// without invoking the function
+		inputFiltersJson, err := json.Marshal(inputFilters)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, v1.EnvVar{
+			Name:  "INPUT_FILTERS",
+			Value: string(inputFiltersJson),
+		})
+	}
+
+	return env, nil
 }
 
 func (*ProcessorReconciler) collectAliases(bindings []streamingv1alpha1.StreamBinding) []string {
@@ -686,20 +1550,96 @@ func (r *ProcessorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		}
 	}
 
+	// jobs are owned by the scaledObject, map them back to the processor
+	// through the labels inherited from the pod template
+	enqueueProcessorForJob := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			job, ok := a.Object.(*batchv1.Job)
+			if !ok {
+				return nil
+			}
+			name, ok := job.Spec.Template.Labels[streamingv1alpha1.ProcessorLabelKey]
+			if !ok {
+				return nil
+			}
+			return []reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: job.Namespace, Name: name}},
+			}
+		}),
+	}
+
+	// binding secrets are labeled with and owned by their stream, map them to
+	// the processors tracking that stream. Other secrets are ignored.
+	enqueueTrackedStreamsForBindingSecret := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			streamName, ok := a.Meta.GetLabels()[streamingv1alpha1.StreamLabelKey]
+			if !ok {
+				return nil
+			}
+			owner := metav1.GetControllerOf(a.Meta)
+			if owner == nil || owner.Kind != "Stream" || owner.Name != streamName {
+				return nil
+			}
+			var requests []reconcile.Request
+			key := tracker.NewKey(
+				(&streamingv1alpha1.Stream{}).GetGroupVersionKind(),
+				types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: streamName},
+			)
+			for _, item := range r.Tracker.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+
 	if err := controllers.IndexControllersOfType(mgr, processorDeploymentIndexField, &streamingv1alpha1.Processor{}, &appsv1.Deployment{}); err != nil {
 		return err
 	}
-	if err := controllers.IndexControllersOfType(mgr, processorScaledObjectIndexField, &streamingv1alpha1.Processor{}, &kedav1alpha1.ScaledObject{}); err != nil {
+	if err := controllers.IndexControllersOfType(mgr, processorStatefulSetIndexField, &streamingv1alpha1.Processor{}, &appsv1.StatefulSet{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, processorHPAIndexField, &streamingv1alpha1.Processor{}, &autoscalingv1.HorizontalPodAutoscaler{}); err != nil {
 		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&streamingv1alpha1.Processor{}).
 		Owns(&appsv1.Deployment{}).
-		Owns(&kedav1alpha1.ScaledObject{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&autoscalingv1.HorizontalPodAutoscaler{})
+
+	// KEDA resources can only be watched when the CRDs are installed
+	if r.KEDAEnabled {
+		if err := controllers.IndexControllersOfType(mgr, processorScaledObjectIndexField, &streamingv1alpha1.Processor{}, &kedav1alpha1.ScaledObject{}); err != nil {
+			return err
+		}
+		if err := controllers.IndexControllersOfType(mgr, processorTriggerAuthIndexField, &streamingv1alpha1.Processor{}, &kedav1alpha1.TriggerAuthentication{}); err != nil {
+			return err
+		}
+		// jobs are spawned by KEDA, index them by the scaledObject controlling them
+		if err := mgr.GetFieldIndexer().IndexField(&batchv1.Job{}, scaledObjectJobIndexField, func(rawObj runtime.Object) []string {
+			ownerRef := metav1.GetControllerOf(rawObj.(metav1.Object))
+			if ownerRef == nil || ownerRef.APIVersion != kedav1alpha1.GroupVersion.String() || ownerRef.Kind != "ScaledObject" {
+				return nil
+			}
+			return []string{ownerRef.Name}
+		}); err != nil {
+			return err
+		}
+		builder = builder.
+			Owns(&kedav1alpha1.ScaledObject{}).
+			Owns(&kedav1alpha1.TriggerAuthentication{}).
+			Watches(&source.Kind{Type: &batchv1.Job{}}, enqueueProcessorForJob)
+	}
+
+	return builder.
 		Watches(&source.Kind{Type: &buildv1alpha1.Container{}}, enqueueTrackedResources(&buildv1alpha1.Container{})).
 		Watches(&source.Kind{Type: &buildv1alpha1.Function{}}, enqueueTrackedResources(&buildv1alpha1.Function{})).
 		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{})).
+		Watches(&source.Kind{Type: &streamingv1alpha1.KafkaProvider{}}, enqueueTrackedResources(&streamingv1alpha1.KafkaProvider{})).
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources(&v1.ConfigMap{})).
+		// the secret informer is shared with the stream controller, which owns
+		// the binding secrets
+		Watches(&source.Kind{Type: &corev1.Secret{}}, enqueueTrackedStreamsForBindingSecret).
 		Complete(r)
 }
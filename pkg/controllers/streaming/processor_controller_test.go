@@ -0,0 +1,243 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	kedav1alpha1 "github.com/projectriff/system/pkg/apis/thirdparty/keda/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+func TestProcessorReconcilerResolveScaleTriggers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = streamingv1alpha1.AddToScheme(scheme)
+
+	isController := true
+	kafkaProvider := &streamingv1alpha1.KafkaProvider{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-kafka"},
+		Status: streamingv1alpha1.KafkaProviderStatus{
+			BootstrapServers: "kafka:9092",
+		},
+	}
+	ownedProvisioner := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-ns",
+			Name:      "owned-provisioner",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: streamingv1alpha1.GroupVersion.String(),
+				Kind:       "KafkaProvider",
+				Name:       "my-kafka",
+				Controller: &isController,
+			}},
+		},
+	}
+	labeledProvisioner := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-ns",
+			Name:      "labeled-provisioner",
+			Labels: map[string]string{
+				streamingv1alpha1.KafkaProviderProvisionerLabelKey: "my-kafka",
+			},
+		},
+	}
+	orphanedProvisioner := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-ns",
+			Name:      "orphaned-provisioner",
+			Labels: map[string]string{
+				streamingv1alpha1.KafkaProviderProvisionerLabelKey: "missing-kafka",
+			},
+		},
+	}
+	pulsarProvisioner := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-pulsar-pulsar-provisioner"},
+	}
+
+	processor := &streamingv1alpha1.Processor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-processor"},
+		Spec: streamingv1alpha1.ProcessorSpec{
+			ScalerPolicy: streamingv1alpha1.ScalerPolicyNative,
+		},
+		Status: streamingv1alpha1.ProcessorStatus{
+			DeprecatedInputAddresses: []string{"gateway:6565/my-ns_my-stream"},
+		},
+	}
+	processor.Status.InitializeConditions()
+	triggerAuths := []*kedav1alpha1.TriggerAuthentication{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-processor-processor-input-000"}},
+	}
+	streamFor := func(provider string) streamingv1alpha1.Stream {
+		return streamingv1alpha1.Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-stream"},
+			Spec:       streamingv1alpha1.StreamSpec{Provider: provider},
+			Status: streamingv1alpha1.StreamStatus{
+				Address: streamingv1alpha1.StreamAddress{Gateway: "gateway:6565", Topic: "my-ns_my-stream"},
+			},
+		}
+	}
+	kafkaTrigger := kedav1alpha1.ScaleTriggers{
+		Type: "kafka",
+		Metadata: map[string]string{
+			"brokerList":    "kafka:9092",
+			"consumerGroup": "my-processor",
+			"topic":         "my-ns_my-stream",
+		},
+		AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "my-processor-processor-input-000"},
+	}
+	liiklusTrigger := kedav1alpha1.ScaleTriggers{
+		Type: "liiklus",
+		Metadata: map[string]string{
+			"address": "gateway:6565",
+			"group":   "my-processor",
+			"topic":   "my-ns_my-stream",
+		},
+		AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "my-processor-processor-input-000"},
+	}
+
+	tests := []struct {
+		name           string
+		provider       string
+		expected       kedav1alpha1.ScaleTriggers
+		expectedScaler string
+		expectedReason string
+	}{{
+		name:           "provisioner owned by a kafka provider",
+		provider:       "owned-provisioner",
+		expected:       kafkaTrigger,
+		expectedScaler: "kafka",
+	}, {
+		name:           "provisioner labeled for a kafka provider",
+		provider:       "labeled-provisioner",
+		expected:       kafkaTrigger,
+		expectedScaler: "kafka",
+	}, {
+		name:           "kafka provider not found",
+		provider:       "orphaned-provisioner",
+		expected:       liiklusTrigger,
+		expectedScaler: "liiklus",
+		expectedReason: "KafkaProviderNotFound",
+	}, {
+		name:           "provisioner not backed by a kafka provider",
+		provider:       "my-pulsar-pulsar-provisioner",
+		expected:       liiklusTrigger,
+		expectedScaler: "liiklus",
+		expectedReason: "UnsupportedProvider",
+	}, {
+		name:           "provisioner not found",
+		provider:       "missing-provisioner",
+		expected:       liiklusTrigger,
+		expectedScaler: "liiklus",
+		expectedReason: "ProvisionerNotFound",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &ProcessorReconciler{
+				Client: fake.NewFakeClientWithScheme(scheme,
+					kafkaProvider.DeepCopy(),
+					ownedProvisioner.DeepCopy(),
+					labeledProvisioner.DeepCopy(),
+					orphanedProvisioner.DeepCopy(),
+					pulsarProvisioner.DeepCopy(),
+				),
+				Log:     logf.NullLogger{},
+				Scheme:  scheme,
+				Tracker: tracker.New(time.Hour, logf.NullLogger{}),
+			}
+			proc := processor.DeepCopy()
+
+			actual, err := r.resolveScaleTriggers(context.TODO(), proc, []streamingv1alpha1.Stream{streamFor(test.provider)}, triggerAuths)
+			if err != nil {
+				t.Fatalf("resolveScaleTriggers() unexpected error %v", err)
+			}
+			if diff := cmp.Diff([]kedav1alpha1.ScaleTriggers{test.expected}, actual); diff != "" {
+				t.Errorf("resolveScaleTriggers() (-expected, +actual): %s", diff)
+			}
+			if expected, actual := test.expectedScaler, proc.Status.Scaler; expected != actual {
+				t.Errorf("expected scaler %q, got %q", expected, actual)
+			}
+			if expected, actual := test.expectedReason, proc.Status.GetCondition(streamingv1alpha1.ProcessorConditionNativeScaler).Reason; expected != actual {
+				t.Errorf("expected native scaler reason %q, got %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestFunctionPortEnv(t *testing.T) {
+	invocation := &streamingv1alpha1.ProcessorInvocation{
+		Protocol: streamingv1alpha1.FunctionProtocolHTTP,
+		Port:     8080,
+	}
+
+	tests := []struct {
+		name     string
+		env      []corev1.EnvVar
+		expected []corev1.EnvVar
+	}{{
+		name: "empty",
+		expected: []corev1.EnvVar{
+			{Name: "PORT", Value: "8080"},
+			{Name: "HTTP_PORT", Value: "8080"},
+		},
+	}, {
+		name: "preserves user variables",
+		env: []corev1.EnvVar{
+			{Name: "FOO", Value: "bar"},
+		},
+		expected: []corev1.EnvVar{
+			{Name: "FOO", Value: "bar"},
+			{Name: "PORT", Value: "8080"},
+			{Name: "HTTP_PORT", Value: "8080"},
+		},
+	}, {
+		name: "replaces user port variables",
+		env: []corev1.EnvVar{
+			{Name: "PORT", Value: "9090"},
+			{Name: "FOO", Value: "bar"},
+			{Name: "HTTP_PORT", Value: "9090"},
+			{Name: "GRPC_PORT", Value: "9091"},
+		},
+		expected: []corev1.EnvVar{
+			{Name: "FOO", Value: "bar"},
+			{Name: "GRPC_PORT", Value: "9091"},
+			{Name: "PORT", Value: "8080"},
+			{Name: "HTTP_PORT", Value: "8080"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := functionPortEnv(test.env, invocation)
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Errorf("functionPortEnv() (-expected, +actual): %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"net/http"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	buildv1alpha1 "github.com/projectriff/system/pkg/apis/build/v1alpha1"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/validation"
+)
+
+const (
+	ProcessorStreamAccessWebhookPath = "/validate-streaming-projectriff-io-v1alpha1-processor-stream-access"
+	ProcessorInvocationWebhookPath   = "/validate-streaming-projectriff-io-v1alpha1-processor-invocation"
+)
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-processor-stream-access,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=processors,verbs=create;update,versions=v1alpha1,name=processor-stream-access.streaming.projectriff.io
+
+// ProcessorStreamAccessValidator rejects processors binding streams that do not
+// allow them to publish or consume
+type ProcessorStreamAccessValidator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+var (
+	_ admission.Handler         = &ProcessorStreamAccessValidator{}
+	_ admission.DecoderInjector = &ProcessorStreamAccessValidator{}
+)
+
+func (v *ProcessorStreamAccessValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	processor := &streamingv1alpha1.Processor{}
+	if err := v.decoder.Decode(req, processor); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// the request namespace is authoritative for new resources
+	processor.Namespace = req.Namespace
+
+	errs := validation.FieldErrors{}
+	for i, binding := range processor.Spec.Inputs {
+		stream, err := v.lookupStream(ctx, processor.Namespace, binding.Stream)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if stream != nil && !stream.AllowsConsumer(processor) {
+			errs = errs.Also(validation.ErrDisallowedFields("stream", "the stream does not allow the processor to consume").ViaFieldIndex("inputs", i))
+		}
+	}
+	for i, binding := range processor.Spec.Outputs {
+		stream, err := v.lookupStream(ctx, processor.Namespace, binding.Stream)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if stream != nil && !stream.AllowsPublisher(processor) {
+			errs = errs.Also(validation.ErrDisallowedFields("stream", "the stream does not allow the processor to publish").ViaFieldIndex("outputs", i))
+		}
+	}
+
+	if len(errs) != 0 {
+		return admission.Denied(errs.ViaField("spec").ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// lookupStream returns nil when the stream does not exist. Streams created
+// after the processor are enforced by the reconciler.
+func (v *ProcessorStreamAccessValidator) lookupStream(ctx context.Context, namespace, name string) (*streamingv1alpha1.Stream, error) {
+	if name == "" {
+		return nil, nil
+	}
+	var stream streamingv1alpha1.Stream
+	if err := v.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &stream); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &stream, nil
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *ProcessorStreamAccessValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-streaming-projectriff-io-v1alpha1-processor-invocation,mutating=false,failurePolicy=fail,groups=streaming.projectriff.io,resources=processors,verbs=create;update,versions=v1alpha1,name=processor-invocation.streaming.projectriff.io
+
+// ProcessorInvocationValidator rejects processors invoking a function over a
+// protocol its invoker does not support
+type ProcessorInvocationValidator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+var (
+	_ admission.Handler         = &ProcessorInvocationValidator{}
+	_ admission.DecoderInjector = &ProcessorInvocationValidator{}
+)
+
+func (v *ProcessorInvocationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	processor := &streamingv1alpha1.Processor{}
+	if err := v.decoder.Decode(req, processor); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// the request namespace is authoritative for new resources
+	processor.Namespace = req.Namespace
+
+	if processor.Spec.Build == nil || processor.Spec.Build.FunctionRef == "" || processor.Spec.Invocation == nil {
+		return admission.Allowed("")
+	}
+
+	// functions created after the processor are enforced by the reconciler
+	var function buildv1alpha1.Function
+	if err := v.Client.Get(ctx, types.NamespacedName{Namespace: processor.Namespace, Name: processor.Spec.Build.FunctionRef}, &function); err != nil {
+		if apierrs.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if errs := processor.Spec.Invocation.ValidateInvoker(function.Spec.Invoker); len(errs) != 0 {
+		return admission.Denied(errs.ViaField("spec.invocation").ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *ProcessorInvocationValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
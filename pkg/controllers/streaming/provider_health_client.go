@@ -0,0 +1,75 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrHealthCheckNotSupported is returned when the provisioner does not serve
+// the health endpoint, broker connectivity is unknown
+var ErrHealthCheckNotSupported = errors.New("the provisioner does not report broker connectivity")
+
+type ProviderHealthClient interface {
+	// CheckBroker asks the provisioner behind the service to probe the broker
+	// metadata, returning an error when the brokers are unreachable
+	CheckBroker(service *corev1.Service) error
+}
+
+type providerHealthRestClient struct {
+	httpClient *http.Client
+	logger     logr.Logger
+}
+
+func NewProviderHealthClient(httpClient *http.Client, logger logr.Logger) ProviderHealthClient {
+	return &providerHealthRestClient{
+		httpClient: httpClient,
+		logger:     logger,
+	}
+}
+
+func (p *providerHealthRestClient) CheckBroker(service *corev1.Service) error {
+	url := fmt.Sprintf("http://%s.%s.svc.cluster.local/health", service.Name, service.Namespace)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			p.logger.Error(err, "Error closing health check response body")
+		}
+	}()
+	if res.StatusCode == http.StatusNotFound {
+		// provisioner images predating the health endpoint
+		return ErrHealthCheckNotSupported
+	}
+	if res.StatusCode >= 400 {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("status: %d, body: %q", res.StatusCode, string(msg))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestProviderHealthClientCheckBroker(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "riff-system",
+			Name:      "my-provider-kafka-provisioner",
+		},
+	}
+	respond := func(status int, body string) roundTripperFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if expected := "http://my-provider-kafka-provisioner.riff-system.svc.cluster.local/health"; req.URL.String() != expected {
+				t.Errorf("CheckBroker() expected request to %q, got %q", expected, req.URL.String())
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		}
+	}
+
+	for _, c := range []struct {
+		name                 string
+		transport            roundTripperFunc
+		expectedErr          bool
+		expectedNotSupported bool
+	}{{
+		name:      "reachable",
+		transport: respond(http.StatusOK, ""),
+	}, {
+		name:                 "health endpoint not found",
+		transport:            respond(http.StatusNotFound, "404 page not found"),
+		expectedErr:          true,
+		expectedNotSupported: true,
+	}, {
+		name:        "unreachable",
+		transport:   respond(http.StatusServiceUnavailable, "no brokers available"),
+		expectedErr: true,
+	}, {
+		name: "provisioner unavailable",
+		transport: func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		},
+		expectedErr: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			client := NewProviderHealthClient(&http.Client{Transport: c.transport}, logf.NullLogger{})
+			err := client.CheckBroker(service)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("CheckBroker() expected error %v, got %v", c.expectedErr, err)
+			}
+			if (err == ErrHealthCheckNotSupported) != c.expectedNotSupported {
+				t.Errorf("CheckBroker() expected not supported %v, got %v", c.expectedNotSupported, err)
+			}
+		})
+	}
+}
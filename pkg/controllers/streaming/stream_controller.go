@@ -19,23 +19,45 @@ package streaming
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
 	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
 )
 
 const (
 	bindingMetadataIndexField = ".metadata.bindingMetadataController"
 	bindingSecretIndexField   = ".metadata.bindingSecretController"
+	streamBridgeIndexField    = ".metadata.streamBridgeController"
+
+	schemaTypeMetadataKey          = "schemaType"
+	schemaCompatibilityMetadataKey = "schemaCompatibility"
+	schemaMetadataKey              = "schema"
+)
+
+var (
+	// streamGenerationAnnotationKey records the stream generation a bridge
+	// was created for, a failed bridge is retried once the stream changes
+	streamGenerationAnnotationKey = streamingv1alpha1.GroupVersion.Group + "/stream-generation"
 )
 
 // StreamReconciler reconciles a Stream object
@@ -43,7 +65,9 @@ type StreamReconciler struct {
 	client.Client
 	Log                     logr.Logger
 	Scheme                  *runtime.Scheme
+	Tracker                 tracker.Tracker
 	StreamProvisionerClient StreamProvisionerClient
+	Namespace               string
 }
 
 // For
@@ -52,6 +76,8 @@ type StreamReconciler struct {
 // Owns
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=kafkaproviders,verbs=get;list;watch
 
 func (r *StreamReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -94,6 +120,20 @@ func (r *StreamReconciler) reconcile(ctx context.Context, log logr.Logger, strea
 
 	stream.Status.InitializeConditions()
 
+	// start, or abandon, a migration between providers
+	if err := r.resolveMigration(ctx, log, stream); err != nil {
+		log.Error(err, "unable to resolve migration", "stream", stream)
+		return ctrl.Result{}, err
+	}
+
+	// reflect the broker connectivity of the provider
+	if err := r.propagateBrokerReachable(ctx, stream); err != nil {
+		log.Error(err, "unable to resolve broker connectivity", "stream", stream)
+		return ctrl.Result{}, err
+	}
+
+	result := ctrl.Result{}
+
 	// delegate to the provider via its REST API
 	log.Info("calling provisioner for Stream", "provisioner", stream.Spec.Provider)
 	address, err := r.StreamProvisionerClient.ProvisionStream(stream)
@@ -102,10 +142,29 @@ func (r *StreamReconciler) reconcile(ctx context.Context, log logr.Logger, strea
 		return ctrl.Result{Requeue: true}, err
 	}
 	stream.Status.MarkStreamProvisioned()
-	stream.Status.Address = *address
+	if stream.Status.Migration == nil {
+		stream.Status.Provider = stream.Spec.Provider
+		stream.Status.Address = *address
+	} else {
+		// the binding keeps the previous address until the migration switches it
+		if stream.Status.Migration.ToProvider == stream.Spec.Provider {
+			stream.Status.Migration.ToAddress = *address
+		}
+		if result, err = r.reconcileMigration(ctx, log, stream); err != nil {
+			log.Error(err, "unable to reconcile migration", "stream", stream)
+			return ctrl.Result{}, err
+		}
+	}
+
+	// resolve schema
+	schemaDefinition, err := r.resolveSchema(ctx, log, stream)
+	if err != nil {
+		log.Error(err, "unable to resolve schema", "stream", stream)
+		return ctrl.Result{}, err
+	}
 
 	// reconcile binding metadata
-	childBindingMetadata, err := r.reconcileChildBindingMetadata(ctx, log, stream)
+	childBindingMetadata, err := r.reconcileChildBindingMetadata(ctx, log, stream, schemaDefinition)
 	if err != nil {
 		log.Error(err, "unable to reconcile child binding metadata ConfigMap", "stream", stream)
 		return ctrl.Result{}, err
@@ -136,10 +195,340 @@ func (r *StreamReconciler) reconcile(ctx context.Context, log logr.Logger, strea
 
 	stream.Status.ObservedGeneration = stream.Generation
 
+	return result, nil
+}
+
+// resolveSchema returns the schema definition for the stream, or an empty
+// string when the definition is not available or not valid.
+func (r *StreamReconciler) resolveSchema(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) (string, error) {
+	if stream.Spec.Schema == nil {
+		stream.Status.MarkSchemaReady()
+		return "", nil
+	}
+
+	definition := stream.Spec.Schema.Inline
+	if ref := stream.Spec.Schema.ConfigMapRef; ref != nil {
+		cmKey := types.NamespacedName{Namespace: stream.Namespace, Name: ref.Name}
+		// track config map for schema changes
+		r.Tracker.Track(
+			tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, cmKey),
+			types.NamespacedName{Namespace: stream.Namespace, Name: stream.Name},
+		)
+		var cm corev1.ConfigMap
+		if err := r.Get(ctx, cmKey, &cm); err != nil {
+			if apierrs.IsNotFound(err) {
+				stream.Status.MarkSchemaNotReady("ConfigMapNotFound", "configmap %q not found", ref.Name)
+				return "", nil
+			}
+			return "", err
+		}
+		var ok bool
+		if definition, ok = cm.Data[ref.Key]; !ok {
+			stream.Status.MarkSchemaNotReady("ConfigMapKeyNotFound", "key %q not found in configmap %q", ref.Key, ref.Name)
+			return "", nil
+		}
+	}
+
+	if err := streamingv1alpha1.ValidateSchemaDefinition(stream.Spec.Schema.Type, definition); err != nil {
+		stream.Status.MarkSchemaNotReady("InvalidSchema", "%s", err)
+		return "", nil
+	}
+	stream.Status.MarkSchemaReady()
+
+	return definition, nil
+}
+
+// propagateBrokerReachable reflects the broker connectivity reported by the
+// provider the stream is bound to. Only kafka providers report connectivity.
+func (r *StreamReconciler) propagateBrokerReachable(ctx context.Context, stream *streamingv1alpha1.Stream) error {
+	provider := stream.Status.Provider
+	if provider == "" {
+		provider = stream.Spec.Provider
+	}
+
+	var kafkaProviders streamingv1alpha1.KafkaProviderList
+	if err := r.List(ctx, &kafkaProviders, client.InNamespace(stream.Namespace)); err != nil {
+		return err
+	}
+	for _, kafkaProvider := range kafkaProviders.Items {
+		if kafkaProvider.Status.ProvisionerServiceRef == nil || kafkaProvider.Status.ProvisionerServiceRef.Name != provider {
+			continue
+		}
+		reachable := kafkaProvider.Status.GetCondition(streamingv1alpha1.KafkaProviderConditionBrokerReachable)
+		switch {
+		case reachable.IsTrue():
+			stream.Status.MarkBrokerReachable()
+		case reachable.IsFalse():
+			stream.Status.MarkBrokerUnreachable("broker for provider %q is unreachable: %s", provider, reachable.Message)
+		default:
+			stream.Status.MarkBrokerReachableUnknown("BrokerReachableUnknown", "broker connectivity for provider %q is unknown", provider)
+		}
+		return nil
+	}
+
+	stream.Status.MarkBrokerReachable()
+	return nil
+}
+
+// resolveMigration starts a migration when the provider changes. A migration
+// targeting a provider that is no longer desired is abandoned, unless the
+// binding already switched to it.
+func (r *StreamReconciler) resolveMigration(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) error {
+	migration := stream.Status.Migration
+	if migration != nil && migration.ToProvider != stream.Spec.Provider && !migration.Phase.Switched() {
+		log.Info("abandoning migration", "provider", migration.ToProvider)
+		if _, err := r.reconcileStreamBridge(ctx, log, stream, nil); err != nil {
+			return err
+		}
+		if err := r.StreamProvisionerClient.DeprovisionStream(stream, migration.ToProvider); err != nil {
+			stream.Status.MarkMigrationFailed("DeprovisionFailed", "unable to deprovision stream from provider %q: %s", migration.ToProvider, err)
+			return err
+		}
+		stream.Status.Migration = nil
+		stream.Status.MarkMigrated()
+	}
+
+	if stream.Status.Migration == nil && stream.Status.Provider != "" && stream.Status.Provider != stream.Spec.Provider {
+		log.Info("migrating stream", "from", stream.Status.Provider, "to", stream.Spec.Provider)
+		stream.Status.Migration = &streamingv1alpha1.StreamMigrationStatus{
+			Phase:        streamingv1alpha1.StreamMigrationPhaseProvisioning,
+			FromProvider: stream.Status.Provider,
+			FromAddress:  stream.Status.Address,
+			ToProvider:   stream.Spec.Provider,
+		}
+		stream.Status.MarkMigrating(streamingv1alpha1.StreamMigrationPhaseProvisioning, "provisioning stream on provider %q", stream.Spec.Provider)
+	}
+
+	return nil
+}
+
+// reconcileMigration advances a migration once the stream is provisioned on
+// the new provider. The binding is switched first, producers and consumers are
+// given the drain timeout to move to the new provider. The data remaining on
+// the previous provider is then bridged, when enabled, before the previous
+// provider is deprovisioned.
+func (r *StreamReconciler) reconcileMigration(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) (ctrl.Result, error) {
+	migration := stream.Status.Migration
+	migrationSpec := stream.Spec.Migration
+	if migrationSpec == nil {
+		migrationSpec = &streamingv1alpha1.StreamMigration{}
+		migrationSpec.Default()
+	}
+
+	if migration.Phase == streamingv1alpha1.StreamMigrationPhaseProvisioning {
+		migration.Phase = streamingv1alpha1.StreamMigrationPhaseDraining
+	}
+
+	// switch the binding to the new provider
+	stream.Status.Provider = migration.ToProvider
+	stream.Status.Address = migration.ToAddress
+
+	if migration.Phase == streamingv1alpha1.StreamMigrationPhaseDraining {
+		// the controller can not observe when workloads pick up the new
+		// binding, or consumer lag, they are given a fixed time
+		if migration.DrainingSince == nil {
+			now := metav1.Now()
+			migration.DrainingSince = &now
+		}
+		// the defaulter and validation guarantee a valid timeout
+		drainTimeout, err := time.ParseDuration(migrationSpec.DrainTimeout)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if remaining := time.Until(migration.DrainingSince.Add(drainTimeout)); remaining > 0 {
+			stream.Status.MarkMigrating(migration.Phase, "waiting %s for workloads to drain provider %q", remaining.Round(time.Second), migration.FromProvider)
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+		migration.Phase = streamingv1alpha1.StreamMigrationPhaseDeprovisioning
+		if migrationSpec.Bridge {
+			migration.Phase = streamingv1alpha1.StreamMigrationPhaseBridging
+		}
+	}
+
+	if migration.Phase == streamingv1alpha1.StreamMigrationPhaseBridging {
+		bridge, err := r.reconcileStreamBridge(ctx, log, stream, migration)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		migration.BridgeRef = nil
+		if bridge != nil {
+			migration.BridgeRef = refs.NewTypedLocalObjectReferenceForObject(bridge, r.Scheme)
+		}
+		switch {
+		case bridge == nil:
+			stream.Status.MarkMigrating(migration.Phase, "waiting for bridge job")
+			return ctrl.Result{}, nil
+		case jobConditionIsTrue(bridge.Status.Conditions, batchv1.JobFailed):
+			stream.Status.MarkMigrationFailed("BridgeFailed", "bridge job %q failed, update the stream to retry", bridge.Name)
+			return ctrl.Result{}, nil
+		case !jobConditionIsTrue(bridge.Status.Conditions, batchv1.JobComplete):
+			stream.Status.MarkMigrating(migration.Phase, "bridging data from provider %q", migration.FromProvider)
+			return ctrl.Result{}, nil
+		}
+		// the previous provider is drained
+		migration.Phase = streamingv1alpha1.StreamMigrationPhaseDeprovisioning
+	}
+
+	stream.Status.MarkMigrating(migration.Phase, "deprovisioning stream from provider %q", migration.FromProvider)
+
+	if err := r.StreamProvisionerClient.DeprovisionStream(stream, migration.FromProvider); err != nil {
+		stream.Status.MarkMigrationFailed("DeprovisionFailed", "unable to deprovision stream from provider %q: %s", migration.FromProvider, err)
+		return ctrl.Result{}, err
+	}
+	if _, err := r.reconcileStreamBridge(ctx, log, stream, nil); err != nil {
+		return ctrl.Result{}, err
+	}
+	stream.Status.Migration = nil
+	stream.Status.MarkMigrated()
+
 	return ctrl.Result{}, nil
 }
 
-func (r *StreamReconciler) reconcileChildBindingMetadata(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream) (*corev1.ConfigMap, error) {
+func (r *StreamReconciler) reconcileStreamBridge(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream, migration *streamingv1alpha1.StreamMigrationStatus) (*batchv1.Job, error) {
+	var actualBridge batchv1.Job
+	var childBridges batchv1.JobList
+	if err := r.List(ctx, &childBridges, client.InNamespace(stream.Namespace), client.MatchingField(streamBridgeIndexField, stream.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childBridges.Items) == 1 {
+		actualBridge = childBridges.Items[0]
+	} else if len(childBridges.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraBridge := range childBridges.Items {
+			log.Info("deleting extra bridge", "bridge", extraBridge)
+			if err := r.Delete(ctx, &extraBridge, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredBridge, err := r.constructStreamBridge(ctx, stream, migration)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete bridge if no longer needed
+	if desiredBridge == nil {
+		if actualBridge.Name == "" {
+			return nil, nil
+		}
+		log.Info("deleting bridge", "bridge", actualBridge)
+		if err := r.Delete(ctx, &actualBridge, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+			log.Error(err, "unable to delete bridge Job for Stream", "bridge", actualBridge)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create bridge if it doesn't exist
+	if actualBridge.Name == "" {
+		log.Info("creating bridge", "spec", desiredBridge.Spec)
+		if err := r.Create(ctx, desiredBridge); err != nil {
+			log.Error(err, "unable to create bridge Job for Stream", "bridge", desiredBridge)
+			return nil, err
+		}
+		return desiredBridge, nil
+	}
+
+	// a failed bridge is retried once the stream changes
+	retry := jobConditionIsTrue(actualBridge.Status.Conditions, batchv1.JobFailed) &&
+		actualBridge.Annotations[streamGenerationAnnotationKey] != desiredBridge.Annotations[streamGenerationAnnotationKey]
+
+	if !retry && r.streamBridgeSemanticEquals(desiredBridge, &actualBridge) {
+		// bridge is unchanged
+		return &actualBridge, nil
+	}
+
+	// jobs are immutable, replace the bridge
+	log.Info("replacing bridge", "retry", retry, "diff", cmp.Diff(actualBridge.Spec.Template.Spec.Containers, desiredBridge.Spec.Template.Spec.Containers))
+	if err := r.Delete(ctx, &actualBridge, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		log.Error(err, "unable to delete bridge Job for Stream", "bridge", actualBridge)
+		return nil, err
+	}
+	if err := r.Create(ctx, desiredBridge); err != nil {
+		log.Error(err, "unable to create bridge Job for Stream", "bridge", desiredBridge)
+		return nil, err
+	}
+
+	return desiredBridge, nil
+}
+
+func (r *StreamReconciler) streamBridgeSemanticEquals(desiredBridge, bridge *batchv1.Job) bool {
+	return equality.Semantic.DeepEqual(desiredBridge.Spec.Template.Spec.Containers[0].Image, bridge.Spec.Template.Spec.Containers[0].Image) &&
+		equality.Semantic.DeepEqual(desiredBridge.Spec.Template.Spec.Containers[0].Env, bridge.Spec.Template.Spec.Containers[0].Env) &&
+		equality.Semantic.DeepEqual(desiredBridge.ObjectMeta.Labels, bridge.ObjectMeta.Labels)
+}
+
+func (r *StreamReconciler) constructStreamBridge(ctx context.Context, stream *streamingv1alpha1.Stream, migration *streamingv1alpha1.StreamMigrationStatus) (*batchv1.Job, error) {
+	if migration == nil {
+		return nil, nil
+	}
+
+	// Lookup and track configMap to know which image to use
+	cm := corev1.ConfigMap{}
+	cmKey := types.NamespacedName{Namespace: r.Namespace, Name: streamBridgeImages}
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, cmKey),
+		types.NamespacedName{Namespace: stream.Namespace, Name: stream.Name},
+	)
+	if err := r.Get(ctx, cmKey, &cm); err != nil {
+		return nil, err
+	}
+	bridgeImg := cm.Data[bridgeImageKey]
+	if bridgeImg == "" {
+		return nil, fmt.Errorf("missing bridge image configuration")
+	}
+
+	labels := map[string]string{
+		streamingv1alpha1.StreamLabelKey: stream.Name,
+	}
+	bridge := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: labels,
+			Annotations: map[string]string{
+				streamGenerationAnnotationKey: strconv.FormatInt(stream.Generation, 10),
+			},
+			GenerateName: fmt.Sprintf("%s-stream-bridge-", stream.Name),
+			Namespace:    stream.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:            "bridge",
+						Image:           bridgeImg,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Env: []corev1.EnvVar{
+							{Name: "INPUTS", Value: migration.FromAddress.String()},
+							{Name: "INPUT_NAMES", Value: stream.Name},
+							{Name: "OUTPUTS", Value: migration.ToAddress.String()},
+							{Name: "OUTPUT_NAMES", Value: stream.Name},
+							{Name: "GROUP", Value: fmt.Sprintf("%s-bridge-%s", stream.Name, stream.UID)},
+							{Name: "OFFSET", Value: "earliest"},
+							{
+								// the bridge exits once it copied all data from the input
+								Name:  "EXIT_WHEN_DRAINED",
+								Value: "true",
+							},
+						},
+					}},
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(stream, bridge, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return bridge, nil
+}
+
+func (r *StreamReconciler) reconcileChildBindingMetadata(ctx context.Context, log logr.Logger, stream *streamingv1alpha1.Stream, schemaDefinition string) (*corev1.ConfigMap, error) {
 	var actualBindingMetadata corev1.ConfigMap
 	var childBindingMetadatas corev1.ConfigMapList
 	if err := r.List(ctx, &childBindingMetadatas, client.InNamespace(stream.Namespace), client.MatchingField(bindingMetadataIndexField, stream.Name)); err != nil {
@@ -158,7 +547,31 @@ func (r *StreamReconciler) reconcileChildBindingMetadata(ctx context.Context, lo
 		}
 	}
 
-	desiredBindingMetadata, err := r.constructBindingMetadata(stream)
+	var schemaType streamingv1alpha1.SchemaType
+	var schemaCompatibility streamingv1alpha1.SchemaCompatibility
+	if stream.Spec.Schema != nil {
+		schemaType, schemaCompatibility = stream.Spec.Schema.Type, stream.Spec.Schema.Compatibility
+		// the previously published schema remains until a valid and compatible
+		// schema replaces it
+		previousType := streamingv1alpha1.SchemaType(actualBindingMetadata.Data[schemaTypeMetadataKey])
+		previousDefinition := actualBindingMetadata.Data[schemaMetadataKey]
+		// the compatibility promised to existing consumers applies to the
+		// change, not the one requested alongside it
+		previousCompatibility := streamingv1alpha1.SchemaCompatibility(actualBindingMetadata.Data[schemaCompatibilityMetadataKey])
+		if previousCompatibility == "" {
+			previousCompatibility = schemaCompatibility
+		}
+		if schemaDefinition == "" {
+			schemaType, schemaCompatibility, schemaDefinition = previousType, previousCompatibility, previousDefinition
+		} else if previousDefinition != "" {
+			if err := streamingv1alpha1.CheckSchemaCompatibility(previousCompatibility, previousType, previousDefinition, schemaType, schemaDefinition); err != nil {
+				stream.Status.MarkSchemaNotReady("IncompatibleSchema", "%s", err)
+				schemaType, schemaCompatibility, schemaDefinition = previousType, previousCompatibility, previousDefinition
+			}
+		}
+	}
+
+	desiredBindingMetadata, err := r.constructBindingMetadata(stream, schemaType, schemaCompatibility, schemaDefinition)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +619,7 @@ func (r *StreamReconciler) bindingMetadataSemanticEquals(desiredBindingMetadata,
 		equality.Semantic.DeepEqual(desiredBindingMetadata.ObjectMeta.Labels, bindingMetadata.ObjectMeta.Labels)
 }
 
-func (r *StreamReconciler) constructBindingMetadata(stream *streamingv1alpha1.Stream) (*corev1.ConfigMap, error) {
+func (r *StreamReconciler) constructBindingMetadata(stream *streamingv1alpha1.Stream, schemaType streamingv1alpha1.SchemaType, schemaCompatibility streamingv1alpha1.SchemaCompatibility, schemaDefinition string) (*corev1.ConfigMap, error) {
 	metadata := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels: map[string]string{
@@ -226,6 +639,13 @@ func (r *StreamReconciler) constructBindingMetadata(stream *streamingv1alpha1.St
 			"contentType": stream.Spec.ContentType,
 		},
 	}
+	if stream.Spec.Schema != nil && schemaDefinition != "" {
+		metadata.Data[schemaTypeMetadataKey] = string(schemaType)
+		metadata.Data[schemaMetadataKey] = schemaDefinition
+		if schemaCompatibility != "" {
+			metadata.Data[schemaCompatibilityMetadataKey] = string(schemaCompatibility)
+		}
+	}
 	if err := ctrl.SetControllerReference(stream, metadata, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -327,16 +747,57 @@ func (r *StreamReconciler) constructBindingSecret(stream *streamingv1alpha1.Stre
 }
 
 func (r *StreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			key := tracker.NewKey(
+				schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+			)
+			for _, item := range r.Tracker.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+
 	if err := controllers.IndexControllersOfType(mgr, bindingMetadataIndexField, &streamingv1alpha1.Stream{}, &corev1.ConfigMap{}); err != nil {
 		return err
 	}
 	if err := controllers.IndexControllersOfType(mgr, bindingSecretIndexField, &streamingv1alpha1.Stream{}, &corev1.Secret{}); err != nil {
 		return err
 	}
+	if err := controllers.IndexControllersOfType(mgr, streamBridgeIndexField, &streamingv1alpha1.Stream{}, &batchv1.Job{}); err != nil {
+		return err
+	}
+
+	enqueueStreamsForKafkaProvider := &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			requests := []reconcile.Request{}
+			kafkaProvider, ok := a.Object.(*streamingv1alpha1.KafkaProvider)
+			if !ok || kafkaProvider.Status.ProvisionerServiceRef == nil {
+				return requests
+			}
+			var streams streamingv1alpha1.StreamList
+			if err := r.List(context.Background(), &streams, client.InNamespace(kafkaProvider.Namespace)); err != nil {
+				r.Log.Error(err, "unable to list streams for kafka provider", "kafkaprovider", kafkaProvider.Name)
+				return requests
+			}
+			for _, stream := range streams.Items {
+				if stream.Spec.Provider == kafkaProvider.Status.ProvisionerServiceRef.Name || stream.Status.Provider == kafkaProvider.Status.ProvisionerServiceRef.Name {
+					requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: stream.Namespace, Name: stream.Name}})
+				}
+			}
+			return requests
+		}),
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&streamingv1alpha1.Stream{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Secret{}).
+		Owns(&batchv1.Job{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources).
+		Watches(&source.Kind{Type: &streamingv1alpha1.KafkaProvider{}}, enqueueStreamsForKafkaProvider).
 		Complete(r)
 }
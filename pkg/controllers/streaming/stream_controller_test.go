@@ -0,0 +1,355 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+type fakeStreamProvisionerClient struct {
+	deprovisioned []string
+	err           error
+}
+
+func (c *fakeStreamProvisionerClient) ProvisionStream(stream *streamingv1alpha1.Stream) (*streamingv1alpha1.StreamAddress, error) {
+	return &stream.Status.Address, c.err
+}
+
+func (c *fakeStreamProvisionerClient) DeprovisionStream(stream *streamingv1alpha1.Stream, provider string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.deprovisioned = append(c.deprovisioned, provider)
+	return nil
+}
+
+func TestStreamReconcilerReconcileMigration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = streamingv1alpha1.AddToScheme(scheme)
+
+	fromAddress := streamingv1alpha1.StreamAddress{Gateway: "old-gateway:6565", Topic: "default_my-stream"}
+	toAddress := streamingv1alpha1.StreamAddress{Gateway: "new-gateway:6565", Topic: "default_my-stream"}
+	elapsed := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	recent := metav1.NewTime(time.Now().Add(-1 * time.Minute))
+	bridgeImages := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "riff-system", Name: streamBridgeImages},
+		Data:       map[string]string{bridgeImageKey: "projectriff/streaming-processor"},
+	}
+
+	newStream := func(bridge bool, phase streamingv1alpha1.StreamMigrationPhase, drainingSince *metav1.Time) *streamingv1alpha1.Stream {
+		stream := &streamingv1alpha1.Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-stream", Generation: 2},
+			Spec: streamingv1alpha1.StreamSpec{
+				Provider: "new-provider",
+				Migration: &streamingv1alpha1.StreamMigration{
+					Bridge:       bridge,
+					DrainTimeout: "5m",
+				},
+			},
+			Status: streamingv1alpha1.StreamStatus{
+				Provider: "old-provider",
+				Address:  fromAddress,
+				Migration: &streamingv1alpha1.StreamMigrationStatus{
+					Phase:         phase,
+					FromProvider:  "old-provider",
+					FromAddress:   fromAddress,
+					ToProvider:    "new-provider",
+					ToAddress:     toAddress,
+					DrainingSince: drainingSince,
+				},
+			},
+		}
+		stream.Status.InitializeConditions()
+		return stream
+	}
+
+	for _, c := range []struct {
+		name                  string
+		stream                *streamingv1alpha1.Stream
+		existingBridge        bool
+		bridgeCondition       batchv1.JobConditionType
+		provisionerErr        error
+		expectedErr           bool
+		expectedPhase         streamingv1alpha1.StreamMigrationPhase
+		expectedRequeue       bool
+		expectedDeprovisioned []string
+		expectedBridges       int
+		expectedMigrated      corev1.ConditionStatus
+	}{{
+		name:             "provisioned stream switches the binding before draining",
+		stream:           newStream(true, streamingv1alpha1.StreamMigrationPhaseProvisioning, nil),
+		expectedPhase:    streamingv1alpha1.StreamMigrationPhaseDraining,
+		expectedRequeue:  true,
+		expectedMigrated: corev1.ConditionUnknown,
+	}, {
+		name:             "draining waits for the drain timeout",
+		stream:           newStream(false, streamingv1alpha1.StreamMigrationPhaseDraining, &recent),
+		expectedPhase:    streamingv1alpha1.StreamMigrationPhaseDraining,
+		expectedRequeue:  true,
+		expectedMigrated: corev1.ConditionUnknown,
+	}, {
+		name:                  "drained stream without bridge is deprovisioned",
+		stream:                newStream(false, streamingv1alpha1.StreamMigrationPhaseDraining, &elapsed),
+		expectedDeprovisioned: []string{"old-provider"},
+		expectedMigrated:      corev1.ConditionTrue,
+	}, {
+		name:             "drained stream with bridge starts the bridge",
+		stream:           newStream(true, streamingv1alpha1.StreamMigrationPhaseDraining, &elapsed),
+		expectedPhase:    streamingv1alpha1.StreamMigrationPhaseBridging,
+		expectedBridges:  1,
+		expectedMigrated: corev1.ConditionUnknown,
+	}, {
+		name:             "bridging waits for the bridge to complete",
+		stream:           newStream(true, streamingv1alpha1.StreamMigrationPhaseBridging, &elapsed),
+		existingBridge:   true,
+		expectedPhase:    streamingv1alpha1.StreamMigrationPhaseBridging,
+		expectedBridges:  1,
+		expectedMigrated: corev1.ConditionUnknown,
+	}, {
+		name:             "failed bridge fails the migration",
+		stream:           newStream(true, streamingv1alpha1.StreamMigrationPhaseBridging, &elapsed),
+		existingBridge:   true,
+		bridgeCondition:  batchv1.JobFailed,
+		expectedPhase:    streamingv1alpha1.StreamMigrationPhaseBridging,
+		expectedBridges:  1,
+		expectedMigrated: corev1.ConditionFalse,
+	}, {
+		name:                  "completed bridge deprovisions the previous provider",
+		stream:                newStream(true, streamingv1alpha1.StreamMigrationPhaseBridging, &elapsed),
+		existingBridge:        true,
+		bridgeCondition:       batchv1.JobComplete,
+		expectedDeprovisioned: []string{"old-provider"},
+		expectedMigrated:      corev1.ConditionTrue,
+	}, {
+		name:             "deprovision failure",
+		stream:           newStream(false, streamingv1alpha1.StreamMigrationPhaseDeprovisioning, &elapsed),
+		provisionerErr:   errors.New("provisioner unavailable"),
+		expectedErr:      true,
+		expectedPhase:    streamingv1alpha1.StreamMigrationPhaseDeprovisioning,
+		expectedMigrated: corev1.ConditionFalse,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			provisioner := &fakeStreamProvisionerClient{err: c.provisionerErr}
+			r := &StreamReconciler{
+				Client:                  fake.NewFakeClientWithScheme(scheme, bridgeImages.DeepCopy()),
+				Log:                     logf.NullLogger{},
+				Scheme:                  scheme,
+				Tracker:                 tracker.New(time.Hour, logf.NullLogger{}),
+				StreamProvisionerClient: provisioner,
+				Namespace:               "riff-system",
+			}
+			stream := c.stream
+			if c.existingBridge {
+				bridge, err := r.constructStreamBridge(ctx, stream, stream.Status.Migration)
+				if err != nil {
+					t.Fatalf("constructStreamBridge() unexpected error: %v", err)
+				}
+				bridge.Name = "my-stream-stream-bridge-abcde"
+				if c.bridgeCondition != "" {
+					bridge.Status.Conditions = []batchv1.JobCondition{{Type: c.bridgeCondition, Status: corev1.ConditionTrue}}
+				}
+				if err := r.Create(ctx, bridge); err != nil {
+					t.Fatalf("Create() unexpected error: %v", err)
+				}
+			}
+
+			result, err := r.reconcileMigration(ctx, logf.NullLogger{}, stream)
+			if (err != nil) != c.expectedErr {
+				t.Fatalf("reconcileMigration() expected error %v, got %v", c.expectedErr, err)
+			}
+
+			// the binding always references the new provider once provisioned
+			if stream.Status.Provider != "new-provider" {
+				t.Errorf("expected binding provider %q, got %q", "new-provider", stream.Status.Provider)
+			}
+			if diff := cmp.Diff(toAddress, stream.Status.Address); diff != "" {
+				t.Errorf("binding address (-expected, +actual): %s", diff)
+			}
+
+			phase := streamingv1alpha1.StreamMigrationPhase("")
+			if stream.Status.Migration != nil {
+				phase = stream.Status.Migration.Phase
+				if stream.Status.Migration.DrainingSince == nil {
+					t.Errorf("expected draining to have started")
+				}
+			}
+			if phase != c.expectedPhase {
+				t.Errorf("expected migration phase %q, got %q", c.expectedPhase, phase)
+			}
+			if (result.RequeueAfter > 0) != c.expectedRequeue {
+				t.Errorf("expected requeue %v, got %v", c.expectedRequeue, result.RequeueAfter)
+			}
+			if diff := cmp.Diff(c.expectedDeprovisioned, provisioner.deprovisioned); diff != "" {
+				t.Errorf("deprovisioned providers (-expected, +actual): %s", diff)
+			}
+			if actual := stream.Status.GetCondition(streamingv1alpha1.StreamConditionMigrated).Status; actual != c.expectedMigrated {
+				t.Errorf("expected %s condition %s, got %s", streamingv1alpha1.StreamConditionMigrated, c.expectedMigrated, actual)
+			}
+
+			var bridges batchv1.JobList
+			if err := r.List(ctx, &bridges, client.InNamespace(stream.Namespace)); err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+			if len(bridges.Items) != c.expectedBridges {
+				t.Errorf("expected %d bridge jobs, got %d", c.expectedBridges, len(bridges.Items))
+			}
+		})
+	}
+}
+
+func TestStreamReconcilerReconcileChildBindingMetadata(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = streamingv1alpha1.AddToScheme(scheme)
+
+	userJSON := `{"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}`
+	userAvro := `{"type": "record", "name": "user", "fields": [{"name": "name", "type": "string"}]}`
+
+	newStream := func(compatibility streamingv1alpha1.SchemaCompatibility) *streamingv1alpha1.Stream {
+		stream := &streamingv1alpha1.Stream{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-stream"},
+			Spec: streamingv1alpha1.StreamSpec{
+				Provider: "my-provider",
+				Schema: &streamingv1alpha1.StreamSchema{
+					Type:          streamingv1alpha1.SchemaTypeAvro,
+					Compatibility: compatibility,
+					ConfigMapRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "my-schema"},
+						Key:                  "schema.avsc",
+					},
+				},
+			},
+		}
+		stream.Status.InitializeConditions()
+		return stream
+	}
+	newBindingMetadata := func(schemaType streamingv1alpha1.SchemaType, compatibility streamingv1alpha1.SchemaCompatibility, definition string) *corev1.ConfigMap {
+		metadata := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              "my-stream-stream-binding-metadata",
+				CreationTimestamp: metav1.Now(),
+			},
+			Data: map[string]string{
+				schemaTypeMetadataKey: string(schemaType),
+				schemaMetadataKey:     definition,
+			},
+		}
+		if compatibility != "" {
+			metadata.Data[schemaCompatibilityMetadataKey] = string(compatibility)
+		}
+		return metadata
+	}
+
+	tests := []struct {
+		name                  string
+		stream                *streamingv1alpha1.Stream
+		bindingMetadata       *corev1.ConfigMap
+		expectedType          string
+		expectedCompatibility string
+		expectedSchema        string
+		expectedRejected      bool
+	}{{
+		name:                  "first schema",
+		stream:                newStream(streamingv1alpha1.SchemaCompatibilityBackward),
+		expectedType:          string(streamingv1alpha1.SchemaTypeAvro),
+		expectedCompatibility: string(streamingv1alpha1.SchemaCompatibilityBackward),
+		expectedSchema:        userAvro,
+	}, {
+		name:                  "type changed",
+		stream:                newStream(streamingv1alpha1.SchemaCompatibilityBackward),
+		bindingMetadata:       newBindingMetadata(streamingv1alpha1.SchemaTypeJSONSchema, streamingv1alpha1.SchemaCompatibilityBackward, userJSON),
+		expectedType:          string(streamingv1alpha1.SchemaTypeJSONSchema),
+		expectedCompatibility: string(streamingv1alpha1.SchemaCompatibilityBackward),
+		expectedSchema:        userJSON,
+		expectedRejected:      true,
+	}, {
+		name:                  "type changed without checks",
+		stream:                newStream(streamingv1alpha1.SchemaCompatibilityBackward),
+		bindingMetadata:       newBindingMetadata(streamingv1alpha1.SchemaTypeJSONSchema, streamingv1alpha1.SchemaCompatibilityNone, userJSON),
+		expectedType:          string(streamingv1alpha1.SchemaTypeAvro),
+		expectedCompatibility: string(streamingv1alpha1.SchemaCompatibilityBackward),
+		expectedSchema:        userAvro,
+	}, {
+		name:                  "type changed while dropping compatibility",
+		stream:                newStream(streamingv1alpha1.SchemaCompatibilityNone),
+		bindingMetadata:       newBindingMetadata(streamingv1alpha1.SchemaTypeJSONSchema, streamingv1alpha1.SchemaCompatibilityBackward, userJSON),
+		expectedType:          string(streamingv1alpha1.SchemaTypeJSONSchema),
+		expectedCompatibility: string(streamingv1alpha1.SchemaCompatibilityBackward),
+		expectedSchema:        userJSON,
+		expectedRejected:      true,
+	}, {
+		name:                  "type changed without a recorded compatibility",
+		stream:                newStream(streamingv1alpha1.SchemaCompatibilityNone),
+		bindingMetadata:       newBindingMetadata(streamingv1alpha1.SchemaTypeJSONSchema, "", userJSON),
+		expectedType:          string(streamingv1alpha1.SchemaTypeAvro),
+		expectedCompatibility: string(streamingv1alpha1.SchemaCompatibilityNone),
+		expectedSchema:        userAvro,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			objs := []runtime.Object{}
+			if test.bindingMetadata != nil {
+				objs = append(objs, test.bindingMetadata.DeepCopy())
+			}
+			r := &StreamReconciler{
+				Client:  fake.NewFakeClientWithScheme(scheme, objs...),
+				Log:     logf.NullLogger{},
+				Scheme:  scheme,
+				Tracker: tracker.New(time.Hour, logf.NullLogger{}),
+			}
+			stream := test.stream.DeepCopy()
+
+			metadata, err := r.reconcileChildBindingMetadata(context.TODO(), r.Log, stream, userAvro)
+			if err != nil {
+				t.Fatalf("reconcileChildBindingMetadata() unexpected error %v", err)
+			}
+			if expected, actual := test.expectedType, metadata.Data[schemaTypeMetadataKey]; expected != actual {
+				t.Errorf("expected schema type %q, got %q", expected, actual)
+			}
+			if expected, actual := test.expectedCompatibility, metadata.Data[schemaCompatibilityMetadataKey]; expected != actual {
+				t.Errorf("expected schema compatibility %q, got %q", expected, actual)
+			}
+			if expected, actual := test.expectedSchema, metadata.Data[schemaMetadataKey]; expected != actual {
+				t.Errorf("expected schema %q, got %q", expected, actual)
+			}
+			cond := stream.Status.GetCondition(streamingv1alpha1.StreamConditionSchemaReady)
+			if rejected := cond.IsFalse() && cond.Reason == "IncompatibleSchema"; rejected != test.expectedRejected {
+				t.Errorf("expected schema rejected %v, got condition %v", test.expectedRejected, cond)
+			}
+		})
+	}
+}
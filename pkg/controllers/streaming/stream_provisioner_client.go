@@ -30,6 +30,7 @@ import (
 
 type StreamProvisionerClient interface {
 	ProvisionStream(stream *streamingv1alpha1.Stream) (*streamingv1alpha1.StreamAddress, error)
+	DeprovisionStream(stream *streamingv1alpha1.Stream, provider string) error
 }
 
 type streamProvisionerRestClient struct {
@@ -70,3 +71,26 @@ func (s *streamProvisionerRestClient) ProvisionStream(stream *streamingv1alpha1.
 	}
 	return address, nil
 }
+
+func (s *streamProvisionerRestClient) DeprovisionStream(stream *streamingv1alpha1.Stream, provider string) error {
+	url := fmt.Sprintf("http://%s.%s.svc.cluster.local/%s/%s", provider, stream.Namespace, stream.Namespace, stream.Name)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Error(err, "Error closing stream deletion response body")
+		}
+	}()
+	// the stream may already be gone
+	if res.StatusCode >= 400 && res.StatusCode != http.StatusNotFound {
+		msg, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("status: %d, body: %q", res.StatusCode, string(msg))
+	}
+	return nil
+}
@@ -0,0 +1,643 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	streamIngressDeploymentIndexField = ".metadata.streamIngressDeploymentController"
+	streamIngressServiceIndexField    = ".metadata.streamIngressServiceController"
+	streamIngressIngressIndexField    = ".metadata.streamIngressIngressController"
+)
+
+const (
+	streamIngressPort = 8080
+)
+
+// StreamIngressReconciler reconciles a StreamIngress object
+type StreamIngressReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+}
+
+// For
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamingresses/status,verbs=get;update;patch
+// Owns
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// Watches
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+func (r *StreamIngressReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("streamingress", req.NamespacedName)
+
+	var original streamingv1alpha1.StreamIngress
+	if err := r.Client.Get(ctx, req.NamespacedName, &original); err != nil {
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	// Don't modify the informers copy
+	streamIngress := original.DeepCopy()
+
+	// Reconcile this copy of the stream ingress and then write back any status
+	// updates regardless of whether the reconciliation errored out.
+	result, err := r.reconcile(ctx, log, streamIngress)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(original.Status, streamIngress.Status) {
+		log.Info("updating stream ingress status", "diff", cmp.Diff(original.Status, streamIngress.Status))
+		if updateErr := r.Status().Update(ctx, streamIngress); updateErr != nil {
+			log.Error(updateErr, "unable to update StreamIngress status")
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *StreamIngressReconciler) reconcile(ctx context.Context, log logr.Logger, streamIngress *streamingv1alpha1.StreamIngress) (ctrl.Result, error) {
+	if streamIngress.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// We may be reading a version of the object that was stored at an older version
+	// and may not have had all of the assumed defaults specified.  This won't result
+	// in this getting written back to the API Server, but lets downstream logic make
+	// assumptions about defaulting.
+	streamIngress.Default()
+
+	streamIngress.Status.InitializeConditions()
+
+	streamIngressNSName := namespacedNamedFor(streamIngress)
+
+	// Lookup and track configMap to know which image and domain to use
+	cm := corev1.ConfigMap{}
+	cmKey := types.NamespacedName{Namespace: r.Namespace, Name: streamIngressSettings}
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, cmKey),
+		streamIngressNSName,
+	)
+	if err := r.Get(ctx, cmKey, &cm); err != nil {
+		log.Error(err, "unable to lookup stream ingress configMap")
+		return ctrl.Result{}, err
+	}
+
+	// resolve stream
+	var stream streamingv1alpha1.Stream
+	streamNSName := types.NamespacedName{Namespace: streamIngress.Namespace, Name: streamIngress.Spec.Stream}
+	r.Tracker.Track(
+		tracker.NewKey(stream.GetGroupVersionKind(), streamNSName),
+		streamIngressNSName,
+	)
+	if err := r.Get(ctx, streamNSName, &stream); err != nil {
+		if apierrs.IsNotFound(err) {
+			// we'll ignore not-found errors, since the referenced stream may not exist yet.
+			streamIngress.Status.MarkStreamNotReady(fmt.Sprintf("stream %q not found", streamIngress.Spec.Stream))
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{Requeue: true}, err
+	}
+	ready := stream.Status.GetCondition(stream.Status.GetReadyConditionType())
+	if ready == nil {
+		ready = &apis.Condition{Message: "stream has no ready condition"}
+	}
+	if ready.IsTrue() {
+		streamIngress.Status.MarkStreamReady()
+	} else {
+		streamIngress.Status.MarkStreamNotReady(fmt.Sprintf("stream %s is not ready: %s", stream.Name, ready.Message))
+	}
+
+	// reconcile deployment
+	childDeployment, err := r.reconcileChildDeployment(ctx, log, streamIngress, &stream, &cm)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Deployment")
+		return ctrl.Result{}, err
+	}
+	streamIngress.Status.DeploymentRef = refs.NewTypedLocalObjectReferenceForObject(childDeployment, r.Scheme)
+	streamIngress.Status.PropagateDeploymentStatus(&childDeployment.Status)
+
+	// reconcile service
+	childService, err := r.reconcileChildService(ctx, log, streamIngress)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Service")
+		return ctrl.Result{}, err
+	}
+	streamIngress.Status.ServiceRef = refs.NewTypedLocalObjectReferenceForObject(childService, r.Scheme)
+	streamIngress.Status.Address = &apis.Addressable{URL: fmt.Sprintf("http://%s.%s.%s", childService.Name, childService.Namespace, "svc.cluster.local")}
+	streamIngress.Status.PropagateServiceStatus(&childService.Status)
+
+	// reconcile ingress
+	childIngress, err := r.reconcileChildIngress(ctx, log, streamIngress, &cm)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Ingress")
+		return ctrl.Result{}, err
+	}
+	if childIngress == nil {
+		streamIngress.Status.IngressRef = nil
+		streamIngress.Status.URL = ""
+		streamIngress.Status.MarkIngressNotRequired()
+	} else {
+		streamIngress.Status.IngressRef = refs.NewTypedLocalObjectReferenceForObject(childIngress, r.Scheme)
+		streamIngress.Status.URL = fmt.Sprintf("http://%s", childIngress.Spec.Rules[0].Host)
+		streamIngress.Status.PropagateIngressStatus(&childIngress.Status)
+	}
+
+	streamIngress.Status.ObservedGeneration = streamIngress.Generation
+
+	return ctrl.Result{}, nil
+}
+
+func (r *StreamIngressReconciler) reconcileChildDeployment(ctx context.Context, log logr.Logger, streamIngress *streamingv1alpha1.StreamIngress, stream *streamingv1alpha1.Stream, cm *corev1.ConfigMap) (*appsv1.Deployment, error) {
+	var actualDeployment appsv1.Deployment
+	var childDeployments appsv1.DeploymentList
+	if err := r.List(ctx, &childDeployments, client.InNamespace(streamIngress.Namespace), client.MatchingField(streamIngressDeploymentIndexField, streamIngress.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childDeployments.Items) == 1 {
+		actualDeployment = childDeployments.Items[0]
+	} else if len(childDeployments.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraDeployment := range childDeployments.Items {
+			log.Info("deleting extra deployment", "deployment", extraDeployment)
+			if err := r.Delete(ctx, &extraDeployment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ingressImg := cm.Data[streamIngressImageKey]
+	if ingressImg == "" {
+		return nil, fmt.Errorf("missing stream ingress image configuration")
+	}
+
+	desiredDeployment, err := r.constructDeploymentForStreamIngress(streamIngress, stream, ingressImg)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete deployment if no longer needed
+	if desiredDeployment == nil {
+		log.Info("deleting deployment", "deployment", actualDeployment)
+		if err := r.Delete(ctx, &actualDeployment); err != nil {
+			log.Error(err, "unable to delete Deployment for StreamIngress", "deployment", actualDeployment)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create deployment if it doesn't exist
+	if actualDeployment.Name == "" {
+		log.Info("creating deployment", "spec", desiredDeployment.Spec)
+		if err := r.Create(ctx, desiredDeployment); err != nil {
+			log.Error(err, "unable to create Deployment for StreamIngress", "deployment", desiredDeployment)
+			return nil, err
+		}
+		return desiredDeployment, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredDeployment.Spec.Replicas = actualDeployment.Spec.Replicas
+
+	if r.deploymentSemanticEquals(desiredDeployment, &actualDeployment) {
+		// deployment is unchanged
+		return &actualDeployment, nil
+	}
+
+	// update deployment with desired changes
+	deployment := actualDeployment.DeepCopy()
+	deployment.ObjectMeta.Labels = desiredDeployment.ObjectMeta.Labels
+	deployment.Spec = desiredDeployment.Spec
+	log.Info("reconciling deployment", "diff", cmp.Diff(actualDeployment.Spec, deployment.Spec))
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "unable to update Deployment for StreamIngress", "deployment", deployment)
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *StreamIngressReconciler) deploymentSemanticEquals(desiredDeployment, deployment *appsv1.Deployment) bool {
+	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
+		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
+}
+
+func (r *StreamIngressReconciler) constructDeploymentForStreamIngress(streamIngress *streamingv1alpha1.StreamIngress, stream *streamingv1alpha1.Stream, ingressImg string) (*appsv1.Deployment, error) {
+	labels := r.constructLabelsForStreamIngress(streamIngress)
+
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	if stream.Status.Binding.MetadataRef.Name != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: "stream-metadata",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: stream.Status.Binding.MetadataRef,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "stream-metadata",
+			MountPath: fmt.Sprintf("%s/output_%03d/metadata", bindingsRootPath, 0),
+			ReadOnly:  true,
+		})
+	}
+	if stream.Status.Binding.SecretRef.Name != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: "stream-secret",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: stream.Status.Binding.SecretRef.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "stream-secret",
+			MountPath: fmt.Sprintf("%s/output_%03d/secret", bindingsRootPath, 0),
+			ReadOnly:  true,
+		})
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-stream-ingress-", streamIngress.Name),
+			Namespace:    streamIngress.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.StreamIngressLabelKey: streamIngress.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:            "ingress",
+						Image:           ingressImg,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Ports: []corev1.ContainerPort{{
+							Name:          "http",
+							ContainerPort: streamIngressPort,
+						}},
+						Env: []corev1.EnvVar{
+							{Name: "PORT", Value: fmt.Sprintf("%d", streamIngressPort)},
+							{Name: "CNB_BINDINGS", Value: bindingsRootPath},
+							{
+								// TODO remove once the ingress image consumes bindings
+								Name:  "OUTPUTS",
+								Value: stream.Status.Address.String(),
+							},
+							{Name: "OUTPUT_NAMES", Value: stream.Name},
+							{
+								// requests with an incompatible content type are rejected
+								Name:  "CONTENT_TYPE",
+								Value: stream.Spec.ContentType,
+							},
+						},
+						VolumeMounts: volumeMounts,
+						ReadinessProbe: &corev1.Probe{
+							Handler: corev1.Handler{
+								TCPSocket: &corev1.TCPSocketAction{
+									Port: intstr.FromInt(streamIngressPort),
+								},
+							},
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	if stream.Spec.Schema != nil {
+		// requests not matching the schema published with the stream binding
+		// metadata are rejected
+		container := &deployment.Spec.Template.Spec.Containers[0]
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "SCHEMA_TYPE",
+			Value: string(stream.Spec.Schema.Type),
+		})
+	}
+	if err := ctrl.SetControllerReference(streamIngress, deployment, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *StreamIngressReconciler) reconcileChildService(ctx context.Context, log logr.Logger, streamIngress *streamingv1alpha1.StreamIngress) (*corev1.Service, error) {
+	var actualService corev1.Service
+	var childServices corev1.ServiceList
+	if err := r.List(ctx, &childServices, client.InNamespace(streamIngress.Namespace), client.MatchingField(streamIngressServiceIndexField, streamIngress.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childServices.Items) == 1 {
+		actualService = childServices.Items[0]
+	} else if len(childServices.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraService := range childServices.Items {
+			log.Info("deleting extra service", "service", extraService)
+			if err := r.Delete(ctx, &extraService); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredService, err := r.constructServiceForStreamIngress(streamIngress)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete service if no longer needed
+	if desiredService == nil {
+		log.Info("deleting service", "service", actualService)
+		if err := r.Delete(ctx, &actualService); err != nil {
+			log.Error(err, "unable to delete Service for StreamIngress", "service", actualService)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create service if it doesn't exist
+	if actualService.Name == "" {
+		log.Info("creating service", "spec", desiredService.Spec)
+		if err := r.Create(ctx, desiredService); err != nil {
+			log.Error(err, "unable to create Service for StreamIngress", "service", desiredService)
+			return nil, err
+		}
+		return desiredService, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredService.Spec.ClusterIP = actualService.Spec.ClusterIP
+
+	if r.serviceSemanticEquals(desiredService, &actualService) {
+		// service is unchanged
+		return &actualService, nil
+	}
+
+	// update service with desired changes
+	service := actualService.DeepCopy()
+	service.ObjectMeta.Labels = desiredService.ObjectMeta.Labels
+	service.Spec = desiredService.Spec
+	log.Info("reconciling service", "diff", cmp.Diff(actualService.Spec, service.Spec))
+	if err := r.Update(ctx, service); err != nil {
+		log.Error(err, "unable to update Service for StreamIngress", "service", service)
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *StreamIngressReconciler) serviceSemanticEquals(desiredService, service *corev1.Service) bool {
+	return equality.Semantic.DeepEqual(desiredService.Spec, service.Spec) &&
+		equality.Semantic.DeepEqual(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels)
+}
+
+func (r *StreamIngressReconciler) constructServiceForStreamIngress(streamIngress *streamingv1alpha1.StreamIngress) (*corev1.Service, error) {
+	labels := r.constructLabelsForStreamIngress(streamIngress)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-stream-ingress-", streamIngress.Name),
+			Namespace:    streamIngress.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(streamIngressPort)},
+			},
+			Selector: map[string]string{
+				streamingv1alpha1.StreamIngressLabelKey: streamIngress.Name,
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(streamIngress, service, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *StreamIngressReconciler) reconcileChildIngress(ctx context.Context, log logr.Logger, streamIngress *streamingv1alpha1.StreamIngress, cm *corev1.ConfigMap) (*networkingv1beta1.Ingress, error) {
+	var actualIngress networkingv1beta1.Ingress
+	var childIngresses networkingv1beta1.IngressList
+	if err := r.List(ctx, &childIngresses, client.InNamespace(streamIngress.Namespace), client.MatchingField(streamIngressIngressIndexField, streamIngress.Name)); err != nil {
+		return nil, err
+	}
+	if len(childIngresses.Items) == 1 {
+		actualIngress = childIngresses.Items[0]
+	} else if len(childIngresses.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraIngress := range childIngresses.Items {
+			log.Info("deleting extra ingress", "ingress", extraIngress)
+			if err := r.Delete(ctx, &extraIngress); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredIngress, err := r.constructIngressForStreamIngress(streamIngress, cm)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete ingress if no longer needed
+	if desiredIngress == nil {
+		if actualIngress.Name != "" {
+			log.Info("deleting ingress", "ingress", actualIngress)
+			if err := r.Delete(ctx, &actualIngress); err != nil {
+				log.Error(err, "unable to delete Ingress for StreamIngress", "ingress", actualIngress)
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	// create ingress if it doesn't exist
+	if actualIngress.Name == "" {
+		log.Info("creating ingress", "spec", desiredIngress.Spec)
+		if err := r.Create(ctx, desiredIngress); err != nil {
+			log.Error(err, "unable to create Ingress for StreamIngress", "ingress", desiredIngress)
+			return nil, err
+		}
+		return desiredIngress, nil
+	}
+
+	if r.ingressSemanticEquals(desiredIngress, &actualIngress) {
+		// ingress is unchanged
+		return &actualIngress, nil
+	}
+
+	// update ingress with desired changes
+	ingress := actualIngress.DeepCopy()
+	ingress.ObjectMeta.Labels = desiredIngress.ObjectMeta.Labels
+	ingress.Spec = desiredIngress.Spec
+	log.Info("reconciling ingress", "diff", cmp.Diff(actualIngress.Spec, ingress.Spec))
+	if err := r.Update(ctx, ingress); err != nil {
+		log.Error(err, "unable to update Ingress for StreamIngress", "ingress", ingress)
+		return nil, err
+	}
+
+	return ingress, nil
+}
+
+func (r *StreamIngressReconciler) ingressSemanticEquals(desiredIngress, ingress *networkingv1beta1.Ingress) bool {
+	return equality.Semantic.DeepEqual(desiredIngress.Spec, ingress.Spec) &&
+		equality.Semantic.DeepEqual(desiredIngress.ObjectMeta.Labels, ingress.ObjectMeta.Labels)
+}
+
+func (r *StreamIngressReconciler) constructIngressForStreamIngress(streamIngress *streamingv1alpha1.StreamIngress, cm *corev1.ConfigMap) (*networkingv1beta1.Ingress, error) {
+	if streamIngress.Status.ServiceRef == nil || streamIngress.Spec.IngressPolicy == streamingv1alpha1.IngressPolicyClusterLocal {
+		// skip ingress
+		return nil, nil
+	}
+	labels := r.constructLabelsForStreamIngress(streamIngress)
+	host := r.constructHostForStreamIngress(streamIngress, cm)
+
+	ingress := &networkingv1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-stream-ingress-", streamIngress.Name),
+			Namespace:    streamIngress.Namespace,
+		},
+		Spec: networkingv1beta1.IngressSpec{
+			Rules: []networkingv1beta1.IngressRule{{
+				Host: host,
+				IngressRuleValue: networkingv1beta1.IngressRuleValue{
+					HTTP: &networkingv1beta1.HTTPIngressRuleValue{
+						Paths: []networkingv1beta1.HTTPIngressPath{{
+							Path: "/",
+							Backend: networkingv1beta1.IngressBackend{
+								ServiceName: streamIngress.Status.ServiceRef.Name,
+								ServicePort: intstr.FromInt(80),
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(streamIngress, ingress, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return ingress, nil
+}
+
+func (r *StreamIngressReconciler) constructLabelsForStreamIngress(streamIngress *streamingv1alpha1.StreamIngress) map[string]string {
+	labels := make(map[string]string, len(streamIngress.ObjectMeta.Labels)+2)
+	// pass through existing labels
+	for k, v := range streamIngress.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[streamingv1alpha1.StreamIngressLabelKey] = streamIngress.Name
+	labels[streamingv1alpha1.StreamLabelKey] = streamIngress.Spec.Stream
+	return labels
+}
+
+func (r *StreamIngressReconciler) constructHostForStreamIngress(streamIngress *streamingv1alpha1.StreamIngress, cm *corev1.ConfigMap) string {
+	domain := defaultDomain
+	if d := cm.Data[streamIngressDomainKey]; d != "" {
+		domain = d
+	}
+
+	return fmt.Sprintf("%s.%s.%s", streamIngress.Name, streamIngress.Namespace, domain)
+}
+
+func (r *StreamIngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := func(t runtime.Object) handler.EventHandler {
+		versionKinds, _, err := r.Scheme.ObjectKinds(t)
+		if err != nil {
+			panic(err)
+		}
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				var requests []reconcile.Request
+				key := tracker.NewKey(
+					versionKinds[0],
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+				return requests
+			}),
+		}
+	}
+
+	if err := controllers.IndexControllersOfType(mgr, streamIngressDeploymentIndexField, &streamingv1alpha1.StreamIngress{}, &appsv1.Deployment{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, streamIngressServiceIndexField, &streamingv1alpha1.StreamIngress{}, &corev1.Service{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, streamIngressIngressIndexField, &streamingv1alpha1.StreamIngress{}, &networkingv1beta1.Ingress{}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&streamingv1alpha1.StreamIngress{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&networkingv1beta1.Ingress{}).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources(&corev1.ConfigMap{})).
+		Complete(r)
+}
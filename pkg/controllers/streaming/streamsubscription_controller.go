@@ -0,0 +1,602 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	streamSubscriptionDeploymentIndexField = ".metadata.streamSubscriptionDeploymentController"
+
+	// dispatcherStatsPort the dispatcher reports delivery stats on
+	dispatcherStatsPort = 8081
+	// deliveryFailingWindow is how long a delivery failure is reported on the
+	// subscription
+	deliveryFailingWindow = 5 * time.Minute
+)
+
+// StreamSubscriptionReconciler reconciles a StreamSubscription object
+type StreamSubscriptionReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+	// DeliveryStatsClient reads the messages handled by dispatchers
+	DeliveryStatsClient DeliveryStatsClient
+	// DeliveryCheckInterval between delivery stats checks
+	DeliveryCheckInterval time.Duration
+
+	// deliveryStats caches the last delivery stats check for each subscription
+	deliveryStats     map[types.NamespacedName]deliveryStatsCheck
+	deliveryStatsLock sync.Mutex
+
+	controller controller.Controller
+	// subscriber kinds are only known at runtime, watches are added as they are discovered
+	watchedSubscribers     map[schema.GroupVersionKind]bool
+	watchedSubscribersLock sync.Mutex
+}
+
+// For
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamsubscriptions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamsubscriptions/status,verbs=get;update;patch
+// Owns
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// Watches
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// Dispatchers, for delivery stats
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// Subscribers, other addressable kinds are granted by labeling a ClusterRole
+// with streaming.projectriff.io/aggregate-to-subscriber: "true"
+// +kubebuilder:rbac:groups=core.projectriff.io,resources=deployers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=knative.projectriff.io,resources=deployers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamingresses,verbs=get;list;watch
+
+func (r *StreamSubscriptionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("streamsubscription", req.NamespacedName)
+
+	var original streamingv1alpha1.StreamSubscription
+	if err := r.Client.Get(ctx, req.NamespacedName, &original); err != nil {
+		r.forgetDeliveryStats(req.NamespacedName)
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	// Don't modify the informers copy
+	subscription := original.DeepCopy()
+
+	// Reconcile this copy of the subscription and then write back any status
+	// updates regardless of whether the reconciliation errored out.
+	result, err := r.reconcile(ctx, log, subscription)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(original.Status, subscription.Status) {
+		log.Info("updating stream subscription status", "diff", cmp.Diff(original.Status, subscription.Status))
+		if updateErr := r.Status().Update(ctx, subscription); updateErr != nil {
+			log.Error(updateErr, "unable to update StreamSubscription status")
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *StreamSubscriptionReconciler) reconcile(ctx context.Context, log logr.Logger, subscription *streamingv1alpha1.StreamSubscription) (ctrl.Result, error) {
+	if subscription.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// We may be reading a version of the object that was stored at an older version
+	// and may not have had all of the assumed defaults specified.  This won't result
+	// in this getting written back to the API Server, but lets downstream logic make
+	// assumptions about defaulting.
+	subscription.Default()
+
+	subscription.Status.InitializeConditions()
+
+	subscriptionNSName := namespacedNamedFor(subscription)
+
+	// Lookup and track configMap to know which images to use
+	cm := corev1.ConfigMap{}
+	cmKey := types.NamespacedName{Namespace: r.Namespace, Name: streamSubscriptionImages}
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, cmKey),
+		subscriptionNSName,
+	)
+	if err := r.Get(ctx, cmKey, &cm); err != nil {
+		log.Error(err, "unable to lookup images configMap")
+		return ctrl.Result{}, err
+	}
+
+	// resolve subscriber address
+	subscriberURL, err := r.resolveSubscriberURL(ctx, subscription)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			// we'll ignore not-found errors, since the subscriber may not exist yet.
+			subscription.Status.MarkSubscriberNotFound("subscriber %s %q not found", subscription.Spec.Subscriber.Kind, subscription.Spec.Subscriber.Name)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to resolve subscriber")
+		return ctrl.Result{Requeue: true}, err
+	}
+	subscription.Status.SubscriberURL = subscriberURL
+	if subscriberURL == "" {
+		subscription.Status.MarkSubscriberNotAddressable("subscriber %s %q does not expose an address", subscription.Spec.Subscriber.Kind, subscription.Spec.Subscriber.Name)
+	} else {
+		subscription.Status.MarkSubscriberReady()
+	}
+
+	// resolve streams
+	stream, err := r.resolveStream(ctx, subscriptionNSName, subscription.Spec.Stream)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			subscription.Status.MarkStreamsNotReady("stream %q not found", subscription.Spec.Stream)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{Requeue: true}, err
+	}
+	streams := []streamingv1alpha1.Stream{*stream}
+	var deadLetterStream *streamingv1alpha1.Stream
+	if name := subscription.Spec.Delivery.DeadLetterStream; name != "" {
+		deadLetterStream, err = r.resolveStream(ctx, subscriptionNSName, name)
+		if err != nil {
+			if apierrs.IsNotFound(err) {
+				subscription.Status.MarkStreamsNotReady("dead letter stream %q not found", name)
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{Requeue: true}, err
+		}
+		streams = append(streams, *deadLetterStream)
+	}
+	subscription.Status.MarkStreamsReady()
+	for _, s := range streams {
+		ready := s.Status.GetCondition(s.Status.GetReadyConditionType())
+		if ready == nil {
+			ready = &apis.Condition{Message: "stream has no ready condition"}
+		}
+		if !ready.IsTrue() {
+			subscription.Status.MarkStreamsNotReady("stream %s is not ready: %s", s.Name, ready.Message)
+			break
+		}
+	}
+
+	// reconcile delivery deployment
+	deployment, err := r.reconcileChildDeployment(ctx, log, subscription, stream, deadLetterStream, &cm)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Deployment")
+		return ctrl.Result{}, err
+	}
+	subscription.Status.DeploymentRef = refs.NewTypedLocalObjectReferenceForObject(deployment, r.Scheme)
+	subscription.Status.PropagateDeploymentStatus(&deployment.Status)
+
+	// reflect the messages handled by the dispatcher
+	if err := r.reconcileDeliveryStatus(ctx, log, subscription); err != nil {
+		log.Error(err, "unable to reconcile delivery status")
+		return ctrl.Result{}, err
+	}
+
+	subscription.Status.ObservedGeneration = subscription.Generation
+
+	// check deliveries again on a schedule, the dispatcher does not notify
+	return ctrl.Result{RequeueAfter: r.DeliveryCheckInterval}, nil
+}
+
+type deliveryStatsCheck struct {
+	checked time.Time
+	status  *streamingv1alpha1.DeliveryStatus
+	err     error
+}
+
+func (r *StreamSubscriptionReconciler) reconcileDeliveryStatus(ctx context.Context, log logr.Logger, subscription *streamingv1alpha1.StreamSubscription) error {
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(subscription.Namespace), client.MatchingLabels(map[string]string{streamingv1alpha1.StreamSubscriptionLabelKey: subscription.Name})); err != nil {
+		return err
+	}
+	dispatchers := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			dispatchers = append(dispatchers, pod)
+		}
+	}
+	if len(dispatchers) == 0 {
+		subscription.Status.Delivery = nil
+		subscription.Status.MarkDeliveryUnknown("DispatcherNotRunning", "no dispatcher is running")
+		return nil
+	}
+
+	status, err := r.checkDelivery(namespacedNamedFor(subscription), dispatchers)
+	switch {
+	case err == ErrDeliveryStatsNotSupported:
+		subscription.Status.Delivery = nil
+		subscription.Status.MarkDeliveryUnknown("DeliveryStatsNotSupported", "%s", err)
+	case err != nil:
+		log.Info("unable to read delivery stats", "error", err.Error())
+		subscription.Status.Delivery = nil
+		subscription.Status.MarkDeliveryUnknown("DeliveryStatsUnavailable", "%s", err)
+	default:
+		subscription.Status.PropagateDeliveryStatus(status, time.Now(), deliveryFailingWindow)
+	}
+	return nil
+}
+
+// checkDelivery sums the delivery stats of the dispatchers at most once per
+// check interval, reconciles triggered by other resources reuse the last
+// result rather than blocking on the dispatchers.
+func (r *StreamSubscriptionReconciler) checkDelivery(key types.NamespacedName, dispatchers []corev1.Pod) (*streamingv1alpha1.DeliveryStatus, error) {
+	r.deliveryStatsLock.Lock()
+	last, ok := r.deliveryStats[key]
+	r.deliveryStatsLock.Unlock()
+	if ok && time.Since(last.checked) < r.DeliveryCheckInterval {
+		return last.status, last.err
+	}
+
+	status, err := r.sumDeliveryStats(dispatchers)
+
+	r.deliveryStatsLock.Lock()
+	defer r.deliveryStatsLock.Unlock()
+	if r.deliveryStats == nil {
+		r.deliveryStats = map[types.NamespacedName]deliveryStatsCheck{}
+	}
+	r.deliveryStats[key] = deliveryStatsCheck{checked: time.Now(), status: status, err: err}
+	return status, err
+}
+
+func (r *StreamSubscriptionReconciler) sumDeliveryStats(dispatchers []corev1.Pod) (*streamingv1alpha1.DeliveryStatus, error) {
+	status := &streamingv1alpha1.DeliveryStatus{}
+	for i := range dispatchers {
+		stats, err := r.DeliveryStatsClient.DeliveryStats(&dispatchers[i])
+		if err != nil {
+			return nil, err
+		}
+		status.Delivered += stats.Delivered
+		status.Failed += stats.Failed
+		status.DeadLettered += stats.DeadLettered
+		if stats.LastFailure != nil && (status.LastFailureTime == nil || stats.LastFailure.Time.After(status.LastFailureTime.Time)) {
+			lastFailureTime := metav1.NewTime(stats.LastFailure.Time)
+			status.LastFailureTime = &lastFailureTime
+			status.LastFailureMessage = stats.LastFailure.Message
+		}
+	}
+	return status, nil
+}
+
+func (r *StreamSubscriptionReconciler) forgetDeliveryStats(key types.NamespacedName) {
+	r.deliveryStatsLock.Lock()
+	defer r.deliveryStatsLock.Unlock()
+	delete(r.deliveryStats, key)
+}
+
+func (r *StreamSubscriptionReconciler) resolveSubscriberURL(ctx context.Context, subscription *streamingv1alpha1.StreamSubscription) (string, error) {
+	ref := subscription.Spec.Subscriber
+	gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+	if err := r.watchSubscriberKind(gvk); err != nil {
+		return "", err
+	}
+
+	subscriberNSName := types.NamespacedName{Namespace: subscription.Namespace, Name: ref.Name}
+	// track subscriber for address changes
+	r.Tracker.Track(
+		tracker.NewKey(gvk, subscriberNSName),
+		namespacedNamedFor(subscription),
+	)
+	subscriber := &unstructured.Unstructured{}
+	subscriber.SetGroupVersionKind(gvk)
+	if err := r.Get(ctx, subscriberNSName, subscriber); err != nil {
+		return "", err
+	}
+	url, _, err := unstructured.NestedString(subscriber.Object, "status", "address", "url")
+	if err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// watchSubscriberKind adds a watch for the subscriber kind, unless it is
+// already watched, so subscriptions are updated as the subscriber address
+// changes.
+func (r *StreamSubscriptionReconciler) watchSubscriberKind(gvk schema.GroupVersionKind) error {
+	r.watchedSubscribersLock.Lock()
+	defer r.watchedSubscribersLock.Unlock()
+
+	if r.watchedSubscribers[gvk] {
+		return nil
+	}
+	subscriber := &unstructured.Unstructured{}
+	subscriber.SetGroupVersionKind(gvk)
+	if err := r.controller.Watch(&source.Kind{Type: subscriber}, r.enqueueTrackedResources(gvk)); err != nil {
+		return err
+	}
+	r.watchedSubscribers[gvk] = true
+	return nil
+}
+
+func (r *StreamSubscriptionReconciler) resolveStream(ctx context.Context, subscriptionNSName types.NamespacedName, name string) (*streamingv1alpha1.Stream, error) {
+	var stream streamingv1alpha1.Stream
+	streamNSName := types.NamespacedName{Namespace: subscriptionNSName.Namespace, Name: name}
+	// track stream for new coordinates
+	r.Tracker.Track(
+		tracker.NewKey(stream.GetGroupVersionKind(), streamNSName),
+		subscriptionNSName,
+	)
+	if err := r.Get(ctx, streamNSName, &stream); err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+func (r *StreamSubscriptionReconciler) reconcileChildDeployment(ctx context.Context, log logr.Logger, subscription *streamingv1alpha1.StreamSubscription, stream, deadLetterStream *streamingv1alpha1.Stream, cm *corev1.ConfigMap) (*appsv1.Deployment, error) {
+	var actualDeployment appsv1.Deployment
+	var childDeployments appsv1.DeploymentList
+	if err := r.List(ctx, &childDeployments, client.InNamespace(subscription.Namespace), client.MatchingField(streamSubscriptionDeploymentIndexField, subscription.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childDeployments.Items) == 1 {
+		actualDeployment = childDeployments.Items[0]
+	} else if len(childDeployments.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraDeployment := range childDeployments.Items {
+			log.Info("deleting extra deployment", "deployment", extraDeployment)
+			if err := r.Delete(ctx, &extraDeployment); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	dispatcherImg := cm.Data[dispatcherImageKey]
+	if dispatcherImg == "" {
+		return nil, fmt.Errorf("missing dispatcher image configuration")
+	}
+
+	desiredDeployment, err := r.constructDeploymentForStreamSubscription(subscription, stream, deadLetterStream, dispatcherImg)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete deployment if no longer needed
+	if desiredDeployment == nil {
+		log.Info("deleting deployment", "deployment", actualDeployment)
+		if err := r.Delete(ctx, &actualDeployment); err != nil {
+			log.Error(err, "unable to delete Deployment for StreamSubscription", "deployment", actualDeployment)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// create deployment if it doesn't exist
+	if actualDeployment.Name == "" {
+		log.Info("creating deployment", "spec", desiredDeployment.Spec)
+		if err := r.Create(ctx, desiredDeployment); err != nil {
+			log.Error(err, "unable to create Deployment for StreamSubscription", "deployment", desiredDeployment)
+			return nil, err
+		}
+		return desiredDeployment, nil
+	}
+
+	if r.deploymentSemanticEquals(desiredDeployment, &actualDeployment) {
+		// deployment is unchanged
+		return &actualDeployment, nil
+	}
+
+	// update deployment with desired changes
+	deployment := actualDeployment.DeepCopy()
+	deployment.ObjectMeta.Labels = desiredDeployment.ObjectMeta.Labels
+	deployment.Spec = desiredDeployment.Spec
+	log.Info("reconciling deployment", "diff", cmp.Diff(actualDeployment.Spec, deployment.Spec))
+	if err := r.Update(ctx, deployment); err != nil {
+		log.Error(err, "unable to update Deployment for StreamSubscription", "deployment", deployment)
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *StreamSubscriptionReconciler) deploymentSemanticEquals(desiredDeployment, deployment *appsv1.Deployment) bool {
+	return equality.Semantic.DeepEqual(desiredDeployment.Spec, deployment.Spec) &&
+		equality.Semantic.DeepEqual(desiredDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels)
+}
+
+func (r *StreamSubscriptionReconciler) constructDeploymentForStreamSubscription(subscription *streamingv1alpha1.StreamSubscription, stream, deadLetterStream *streamingv1alpha1.Stream, dispatcherImg string) (*appsv1.Deployment, error) {
+	labels := r.constructLabelsForStreamSubscription(subscription)
+
+	replicas := int32(1)
+	if subscription.Status.SubscriberURL == "" || subscription.Status.GetCondition(streamingv1alpha1.StreamSubscriptionConditionStreamsReady).IsFalse() {
+		// nothing to deliver to or from until dependencies are ready
+		replicas = int32(0)
+	}
+
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	volumes, volumeMounts = r.appendStreamBinding(volumes, volumeMounts, stream, "input_000")
+	outputs := ""
+	outputNames := ""
+	if deadLetterStream != nil {
+		volumes, volumeMounts = r.appendStreamBinding(volumes, volumeMounts, deadLetterStream, "output_000")
+		outputs = deadLetterStream.Status.Address.String()
+		outputNames = deadLetterStream.Name
+	}
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-stream-subscription-", subscription.Name),
+			Namespace:    subscription.Namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					streamingv1alpha1.StreamSubscriptionLabelKey: subscription.Name,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:            "dispatcher",
+						Image:           dispatcherImg,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Env: []corev1.EnvVar{
+							{Name: "CNB_BINDINGS", Value: bindingsRootPath},
+							{
+								// TODO remove once the dispatcher image consumes bindings
+								Name:  "INPUTS",
+								Value: stream.Status.Address.String(),
+							},
+							{Name: "INPUT_NAMES", Value: stream.Name},
+							{
+								// TODO remove once the dispatcher image consumes bindings
+								Name:  "OUTPUTS",
+								Value: outputs,
+							},
+							{Name: "OUTPUT_NAMES", Value: outputNames},
+							{Name: "GROUP", Value: subscription.Name},
+							{Name: "SUBSCRIBER", Value: subscription.Status.SubscriberURL},
+							{Name: "RETRY", Value: fmt.Sprintf("%d", *subscription.Spec.Delivery.Retry)},
+							{Name: "TIMEOUT", Value: subscription.Spec.Delivery.Timeout},
+							{Name: "STATS_PORT", Value: fmt.Sprintf("%d", dispatcherStatsPort)},
+						},
+						Ports: []corev1.ContainerPort{{
+							Name:          "stats",
+							ContainerPort: dispatcherStatsPort,
+							Protocol:      corev1.ProtocolTCP,
+						}},
+						VolumeMounts: volumeMounts,
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(subscription, deployment, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+func (r *StreamSubscriptionReconciler) appendStreamBinding(volumes []corev1.Volume, volumeMounts []corev1.VolumeMount, stream *streamingv1alpha1.Stream, binding string) ([]corev1.Volume, []corev1.VolumeMount) {
+	if stream.Status.Binding.MetadataRef.Name != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: fmt.Sprintf("stream-%s-metadata", stream.UID),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: stream.Status.Binding.MetadataRef,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      fmt.Sprintf("stream-%s-metadata", stream.UID),
+			MountPath: fmt.Sprintf("%s/%s/metadata", bindingsRootPath, binding),
+			ReadOnly:  true,
+		})
+	}
+	if stream.Status.Binding.SecretRef.Name != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: fmt.Sprintf("stream-%s-secret", stream.UID),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: stream.Status.Binding.SecretRef.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      fmt.Sprintf("stream-%s-secret", stream.UID),
+			MountPath: fmt.Sprintf("%s/%s/secret", bindingsRootPath, binding),
+			ReadOnly:  true,
+		})
+	}
+	return volumes, volumeMounts
+}
+
+func (r *StreamSubscriptionReconciler) constructLabelsForStreamSubscription(subscription *streamingv1alpha1.StreamSubscription) map[string]string {
+	labels := make(map[string]string, len(subscription.ObjectMeta.Labels)+1)
+	// pass through existing labels
+	for k, v := range subscription.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[streamingv1alpha1.StreamSubscriptionLabelKey] = subscription.Name
+	return labels
+}
+
+func (r *StreamSubscriptionReconciler) enqueueTrackedResources(gvk schema.GroupVersionKind) handler.EventHandler {
+	return &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+			var requests []reconcile.Request
+			key := tracker.NewKey(
+				gvk,
+				types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+			)
+			for _, item := range r.Tracker.Lookup(key) {
+				requests = append(requests, reconcile.Request{NamespacedName: item})
+			}
+			return requests
+		}),
+	}
+}
+
+func (r *StreamSubscriptionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := controllers.IndexControllersOfType(mgr, streamSubscriptionDeploymentIndexField, &streamingv1alpha1.StreamSubscription{}, &appsv1.Deployment{}); err != nil {
+		return err
+	}
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&streamingv1alpha1.StreamSubscription{}).
+		Owns(&appsv1.Deployment{}).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, r.enqueueTrackedResources((&streamingv1alpha1.Stream{}).GetGroupVersionKind())).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, r.enqueueTrackedResources(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.watchedSubscribers = map[schema.GroupVersionKind]bool{}
+
+	return nil
+}
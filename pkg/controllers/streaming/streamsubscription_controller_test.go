@@ -0,0 +1,176 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+)
+
+type fakeDeliveryStatsClient struct {
+	calls int
+	stats map[string]*DeliveryStats
+	err   error
+}
+
+func (c *fakeDeliveryStatsClient) DeliveryStats(pod *corev1.Pod) (*DeliveryStats, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.stats[pod.Name], nil
+}
+
+func TestStreamSubscriptionReconcilerReconcileDeliveryStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = streamingv1alpha1.AddToScheme(scheme)
+
+	recentFailure := time.Now().Add(-1 * time.Minute).Truncate(time.Second)
+	olderFailure := recentFailure.Add(-1 * time.Minute)
+	dispatcher := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				Labels:    map[string]string{streamingv1alpha1.StreamSubscriptionLabelKey: "my-subscription"},
+			},
+			Status: corev1.PodStatus{Phase: phase, PodIP: "10.0.0.7"},
+		}
+	}
+	for _, c := range []struct {
+		name             string
+		pods             []runtime.Object
+		statsClient      *fakeDeliveryStatsClient
+		expectedDelivery *streamingv1alpha1.DeliveryStatus
+		expectedStatus   corev1.ConditionStatus
+		expectedReason   string
+	}{{
+		name:           "no dispatcher",
+		pods:           []runtime.Object{dispatcher("pending", corev1.PodPending)},
+		statsClient:    &fakeDeliveryStatsClient{},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: "DispatcherNotRunning",
+	}, {
+		name: "stats summed across dispatchers",
+		pods: []runtime.Object{dispatcher("a", corev1.PodRunning), dispatcher("b", corev1.PodRunning)},
+		statsClient: &fakeDeliveryStatsClient{
+			stats: map[string]*DeliveryStats{
+				"a": {Delivered: 5, Failed: 1, LastFailure: &DeliveryFailure{Time: olderFailure, Message: "older"}},
+				"b": {Delivered: 3, Failed: 1, DeadLettered: 1, LastFailure: &DeliveryFailure{Time: recentFailure, Message: "recent"}},
+			},
+		},
+		expectedDelivery: &streamingv1alpha1.DeliveryStatus{
+			Delivered:          8,
+			Failed:             2,
+			DeadLettered:       1,
+			LastFailureTime:    &metav1.Time{Time: recentFailure},
+			LastFailureMessage: "recent",
+		},
+		expectedStatus: corev1.ConditionTrue,
+		expectedReason: "DeliveryFailed",
+	}, {
+		name: "no failures",
+		pods: []runtime.Object{dispatcher("a", corev1.PodRunning)},
+		statsClient: &fakeDeliveryStatsClient{
+			stats: map[string]*DeliveryStats{"a": {Delivered: 5}},
+		},
+		expectedDelivery: &streamingv1alpha1.DeliveryStatus{Delivered: 5},
+		expectedStatus:   corev1.ConditionFalse,
+		expectedReason:   "DeliverySucceeding",
+	}, {
+		name:           "stats not supported",
+		pods:           []runtime.Object{dispatcher("a", corev1.PodRunning)},
+		statsClient:    &fakeDeliveryStatsClient{err: ErrDeliveryStatsNotSupported},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: "DeliveryStatsNotSupported",
+	}, {
+		name:           "stats unavailable",
+		pods:           []runtime.Object{dispatcher("a", corev1.PodRunning)},
+		statsClient:    &fakeDeliveryStatsClient{err: errors.New("connection refused")},
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: "DeliveryStatsUnavailable",
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			r := &StreamSubscriptionReconciler{
+				Client:                fake.NewFakeClientWithScheme(scheme, c.pods...),
+				Scheme:                scheme,
+				DeliveryStatsClient:   c.statsClient,
+				DeliveryCheckInterval: time.Hour,
+			}
+			subscription := &streamingv1alpha1.StreamSubscription{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-subscription"},
+			}
+			subscription.Status.InitializeConditions()
+
+			if err := r.reconcileDeliveryStatus(context.Background(), logf.NullLogger{}, subscription); err != nil {
+				t.Fatalf("reconcileDeliveryStatus() unexpected error: %v", err)
+			}
+
+			if c.expectedDelivery == nil && subscription.Status.Delivery != nil {
+				t.Errorf("expected no delivery status, got %v", subscription.Status.Delivery)
+			}
+			if c.expectedDelivery != nil && !equality.Semantic.DeepEqual(c.expectedDelivery, subscription.Status.Delivery) {
+				t.Errorf("expected delivery status %v, got %v", c.expectedDelivery, subscription.Status.Delivery)
+			}
+			cond := subscription.Status.GetCondition(streamingv1alpha1.StreamSubscriptionConditionDeliveryFailing)
+			if cond.Status != c.expectedStatus || cond.Reason != c.expectedReason {
+				t.Errorf("%s expected status %s with reason %q, got %s with reason %q", streamingv1alpha1.StreamSubscriptionConditionDeliveryFailing, c.expectedStatus, c.expectedReason, cond.Status, cond.Reason)
+			}
+		})
+	}
+}
+
+func TestStreamSubscriptionReconcilerCheckDelivery(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "my-subscription"}
+	dispatchers := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}}
+	statsClient := &fakeDeliveryStatsClient{stats: map[string]*DeliveryStats{"a": {Delivered: 5}}}
+	r := &StreamSubscriptionReconciler{
+		DeliveryStatsClient:   statsClient,
+		DeliveryCheckInterval: time.Hour,
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.checkDelivery(key, dispatchers); err != nil {
+			t.Errorf("checkDelivery() unexpected error: %v", err)
+		}
+	}
+	if statsClient.calls != 1 {
+		t.Errorf("checkDelivery() expected the dispatchers to be asked once per interval, got %d requests", statsClient.calls)
+	}
+
+	r.forgetDeliveryStats(key)
+	if _, err := r.checkDelivery(key, dispatchers); err != nil {
+		t.Errorf("checkDelivery() unexpected error: %v", err)
+	}
+	if statsClient.calls != 2 {
+		t.Errorf("checkDelivery() expected the dispatchers to be asked again once forgotten, got %d requests", statsClient.calls)
+	}
+}
@@ -0,0 +1,483 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/projectriff/system/pkg/apis"
+	streamingv1alpha1 "github.com/projectriff/system/pkg/apis/streaming/v1alpha1"
+	"github.com/projectriff/system/pkg/controllers"
+	"github.com/projectriff/system/pkg/refs"
+	"github.com/projectriff/system/pkg/tracker"
+)
+
+const (
+	streamTapPodIndexField     = ".metadata.streamTapPodController"
+	streamTapServiceIndexField = ".metadata.streamTapServiceController"
+)
+
+const (
+	streamTapPort = 8080
+)
+
+// StreamTapReconciler reconciles a StreamTap object
+type StreamTapReconciler struct {
+	client.Client
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
+	Tracker   tracker.Tracker
+	Namespace string
+}
+
+// For
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamtaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streamtaps/status,verbs=get;update;patch
+// Owns
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// Watches
+// +kubebuilder:rbac:groups=streaming.projectriff.io,resources=streams,verbs=get;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+func (r *StreamTapReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("streamtap", req.NamespacedName)
+
+	var original streamingv1alpha1.StreamTap
+	if err := r.Client.Get(ctx, req.NamespacedName, &original); err != nil {
+		return ctrl.Result{}, ignoreNotFound(err)
+	}
+
+	// Don't modify the informers copy
+	streamTap := original.DeepCopy()
+
+	// Reconcile this copy of the stream tap and then write back any status
+	// updates regardless of whether the reconciliation errored out.
+	result, err := r.reconcile(ctx, log, streamTap)
+
+	// check if status has changed before updating, unless requeued
+	if !result.Requeue && !equality.Semantic.DeepEqual(original.Status, streamTap.Status) {
+		log.Info("updating stream tap status", "diff", cmp.Diff(original.Status, streamTap.Status))
+		if updateErr := r.Status().Update(ctx, streamTap); updateErr != nil {
+			log.Error(updateErr, "unable to update StreamTap status")
+			return ctrl.Result{Requeue: true}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *StreamTapReconciler) reconcile(ctx context.Context, log logr.Logger, streamTap *streamingv1alpha1.StreamTap) (ctrl.Result, error) {
+	if streamTap.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// We may be reading a version of the object that was stored at an older version
+	// and may not have had all of the assumed defaults specified.  This won't result
+	// in this getting written back to the API Server, but lets downstream logic make
+	// assumptions about defaulting.
+	streamTap.Default()
+
+	// taps clean themselves up once expired, children are garbage collected
+	ttl, err := time.ParseDuration(streamTap.Spec.TTL)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	expirationTime := streamTap.CreationTimestamp.Add(ttl)
+	if !time.Now().Before(expirationTime) {
+		log.Info("deleting expired stream tap")
+		if err := r.Delete(ctx, streamTap); err != nil {
+			return ctrl.Result{}, ignoreNotFound(err)
+		}
+		return ctrl.Result{}, nil
+	}
+	streamTap.Status.ExpirationTime = &metav1.Time{Time: expirationTime}
+	// revisit the tap once expired
+	result := ctrl.Result{RequeueAfter: time.Until(expirationTime)}
+
+	streamTap.Status.InitializeConditions()
+
+	streamTapNSName := namespacedNamedFor(streamTap)
+
+	// Lookup and track configMap to know which image to use
+	cm := corev1.ConfigMap{}
+	cmKey := types.NamespacedName{Namespace: r.Namespace, Name: streamTapImages}
+	r.Tracker.Track(
+		tracker.NewKey(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, cmKey),
+		streamTapNSName,
+	)
+	if err := r.Get(ctx, cmKey, &cm); err != nil {
+		log.Error(err, "unable to lookup stream tap configMap")
+		return ctrl.Result{}, err
+	}
+
+	// resolve stream
+	var stream streamingv1alpha1.Stream
+	streamNSName := types.NamespacedName{Namespace: streamTap.Namespace, Name: streamTap.Spec.Stream}
+	r.Tracker.Track(
+		tracker.NewKey(stream.GetGroupVersionKind(), streamNSName),
+		streamTapNSName,
+	)
+	if err := r.Get(ctx, streamNSName, &stream); err != nil {
+		if apierrs.IsNotFound(err) {
+			// we'll ignore not-found errors, since the referenced stream may not exist yet.
+			streamTap.Status.MarkStreamNotReady(fmt.Sprintf("stream %q not found", streamTap.Spec.Stream))
+			return result, nil
+		}
+		return ctrl.Result{Requeue: true}, err
+	}
+	ready := stream.Status.GetCondition(stream.Status.GetReadyConditionType())
+	if ready == nil {
+		ready = &apis.Condition{Message: "stream has no ready condition"}
+	}
+	if !ready.IsTrue() {
+		// the pod can only bind to a ready stream
+		streamTap.Status.MarkStreamNotReady(fmt.Sprintf("stream %s is not ready: %s", stream.Name, ready.Message))
+		return result, nil
+	}
+	streamTap.Status.MarkStreamReady()
+
+	// reconcile pod
+	childPod, err := r.reconcileChildPod(ctx, log, streamTap, &stream, &cm)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Pod")
+		return ctrl.Result{}, err
+	}
+	streamTap.Status.PodRef = refs.NewTypedLocalObjectReferenceForObject(childPod, r.Scheme)
+	streamTap.Status.PropagatePodStatus(&childPod.Status)
+
+	// reconcile service
+	childService, err := r.reconcileChildService(ctx, log, streamTap)
+	if err != nil {
+		log.Error(err, "unable to reconcile child Service")
+		return ctrl.Result{}, err
+	}
+	streamTap.Status.ServiceRef = refs.NewTypedLocalObjectReferenceForObject(childService, r.Scheme)
+	streamTap.Status.Address = &apis.Addressable{URL: fmt.Sprintf("http://%s.%s.%s", childService.Name, childService.Namespace, "svc.cluster.local")}
+	streamTap.Status.PropagateServiceStatus(&childService.Status)
+
+	streamTap.Status.ObservedGeneration = streamTap.Generation
+
+	return result, nil
+}
+
+func (r *StreamTapReconciler) reconcileChildPod(ctx context.Context, log logr.Logger, streamTap *streamingv1alpha1.StreamTap, stream *streamingv1alpha1.Stream, cm *corev1.ConfigMap) (*corev1.Pod, error) {
+	var actualPod corev1.Pod
+	var childPods corev1.PodList
+	if err := r.List(ctx, &childPods, client.InNamespace(streamTap.Namespace), client.MatchingField(streamTapPodIndexField, streamTap.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childPods.Items) == 1 {
+		actualPod = childPods.Items[0]
+	} else if len(childPods.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraPod := range childPods.Items {
+			log.Info("deleting extra pod", "pod", extraPod)
+			if err := r.Delete(ctx, &extraPod); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	tapImg := cm.Data[tapImageKey]
+	if tapImg == "" {
+		return nil, fmt.Errorf("missing stream tap image configuration")
+	}
+
+	desiredPod, err := r.constructPodForStreamTap(streamTap, stream, tapImg)
+	if err != nil {
+		return nil, err
+	}
+
+	// create pod if it doesn't exist
+	if actualPod.Name == "" {
+		log.Info("creating pod", "spec", desiredPod.Spec)
+		if err := r.Create(ctx, desiredPod); err != nil {
+			log.Error(err, "unable to create Pod for StreamTap", "pod", desiredPod)
+			return nil, err
+		}
+		return desiredPod, nil
+	}
+
+	if r.podSemanticEquals(desiredPod, &actualPod) {
+		// pod is unchanged
+		return &actualPod, nil
+	}
+
+	// pods are immutable, replace the pod with desired changes. The buffered
+	// messages are lost.
+	log.Info("replacing pod", "diff", cmp.Diff(actualPod.Spec.Containers[0], desiredPod.Spec.Containers[0]))
+	if err := r.Delete(ctx, &actualPod); err != nil {
+		log.Error(err, "unable to delete Pod for StreamTap", "pod", actualPod)
+		return nil, err
+	}
+	if err := r.Create(ctx, desiredPod); err != nil {
+		log.Error(err, "unable to create Pod for StreamTap", "pod", desiredPod)
+		return nil, err
+	}
+
+	return desiredPod, nil
+}
+
+func (r *StreamTapReconciler) podSemanticEquals(desiredPod, pod *corev1.Pod) bool {
+	// the api server defaults many pod fields, only compare fields we control
+	return equality.Semantic.DeepEqual(desiredPod.Spec.Containers[0].Image, pod.Spec.Containers[0].Image) &&
+		equality.Semantic.DeepEqual(desiredPod.Spec.Containers[0].Env, pod.Spec.Containers[0].Env) &&
+		equality.Semantic.DeepEqual(desiredPod.ObjectMeta.Labels, pod.ObjectMeta.Labels)
+}
+
+func (r *StreamTapReconciler) constructPodForStreamTap(streamTap *streamingv1alpha1.StreamTap, stream *streamingv1alpha1.Stream, tapImg string) (*corev1.Pod, error) {
+	labels := r.constructLabelsForStreamTap(streamTap)
+
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	if stream.Status.Binding.MetadataRef.Name != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: "stream-metadata",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: stream.Status.Binding.MetadataRef,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "stream-metadata",
+			MountPath: fmt.Sprintf("%s/input_%03d/metadata", bindingsRootPath, 0),
+			ReadOnly:  true,
+		})
+	}
+	if stream.Status.Binding.SecretRef.Name != "" {
+		volumes = append(volumes, corev1.Volume{
+			Name: "stream-secret",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: stream.Status.Binding.SecretRef.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "stream-secret",
+			MountPath: fmt.Sprintf("%s/input_%03d/secret", bindingsRootPath, 0),
+			ReadOnly:  true,
+		})
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-stream-tap-", streamTap.Name),
+			Namespace:    streamTap.Namespace,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:            "tap",
+				Image:           tapImg,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Ports: []corev1.ContainerPort{{
+					Name:          "http",
+					ContainerPort: streamTapPort,
+				}},
+				Env: []corev1.EnvVar{
+					{Name: "PORT", Value: fmt.Sprintf("%d", streamTapPort)},
+					{Name: "CNB_BINDINGS", Value: bindingsRootPath},
+					{
+						// TODO remove once the tap image consumes bindings
+						Name:  "INPUTS",
+						Value: stream.Status.Address.String(),
+					},
+					{Name: "INPUT_NAMES", Value: stream.Name},
+					{
+						// a dedicated consumer group leaves other consumers undisturbed
+						Name:  "GROUP",
+						Value: fmt.Sprintf("%s-tap-%s", streamTap.Name, streamTap.UID),
+					},
+					{Name: "OFFSET", Value: "latest"},
+					{Name: "BUFFER", Value: fmt.Sprintf("%d", *streamTap.Spec.Buffer)},
+				},
+				VolumeMounts: volumeMounts,
+				ReadinessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						TCPSocket: &corev1.TCPSocketAction{
+							Port: intstr.FromInt(streamTapPort),
+						},
+					},
+				},
+			}},
+			Volumes: volumes,
+		},
+	}
+	if err := ctrl.SetControllerReference(streamTap, pod, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return pod, nil
+}
+
+func (r *StreamTapReconciler) reconcileChildService(ctx context.Context, log logr.Logger, streamTap *streamingv1alpha1.StreamTap) (*corev1.Service, error) {
+	var actualService corev1.Service
+	var childServices corev1.ServiceList
+	if err := r.List(ctx, &childServices, client.InNamespace(streamTap.Namespace), client.MatchingField(streamTapServiceIndexField, streamTap.Name)); err != nil {
+		return nil, err
+	}
+	// TODO do we need to remove resources pending deletion?
+	if len(childServices.Items) == 1 {
+		actualService = childServices.Items[0]
+	} else if len(childServices.Items) > 1 {
+		// this shouldn't happen, delete everything to a clean slate
+		for _, extraService := range childServices.Items {
+			log.Info("deleting extra service", "service", extraService)
+			if err := r.Delete(ctx, &extraService); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	desiredService, err := r.constructServiceForStreamTap(streamTap)
+	if err != nil {
+		return nil, err
+	}
+
+	// create service if it doesn't exist
+	if actualService.Name == "" {
+		log.Info("creating service", "spec", desiredService.Spec)
+		if err := r.Create(ctx, desiredService); err != nil {
+			log.Error(err, "unable to create Service for StreamTap", "service", desiredService)
+			return nil, err
+		}
+		return desiredService, nil
+	}
+
+	// overwrite fields that should not be mutated
+	desiredService.Spec.ClusterIP = actualService.Spec.ClusterIP
+
+	if r.serviceSemanticEquals(desiredService, &actualService) {
+		// service is unchanged
+		return &actualService, nil
+	}
+
+	// update service with desired changes
+	service := actualService.DeepCopy()
+	service.ObjectMeta.Labels = desiredService.ObjectMeta.Labels
+	service.Spec = desiredService.Spec
+	log.Info("reconciling service", "diff", cmp.Diff(actualService.Spec, service.Spec))
+	if err := r.Update(ctx, service); err != nil {
+		log.Error(err, "unable to update Service for StreamTap", "service", service)
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *StreamTapReconciler) serviceSemanticEquals(desiredService, service *corev1.Service) bool {
+	return equality.Semantic.DeepEqual(desiredService.Spec, service.Spec) &&
+		equality.Semantic.DeepEqual(desiredService.ObjectMeta.Labels, service.ObjectMeta.Labels)
+}
+
+func (r *StreamTapReconciler) constructServiceForStreamTap(streamTap *streamingv1alpha1.StreamTap) (*corev1.Service, error) {
+	labels := r.constructLabelsForStreamTap(streamTap)
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:       labels,
+			Annotations:  make(map[string]string),
+			GenerateName: fmt.Sprintf("%s-stream-tap-", streamTap.Name),
+			Namespace:    streamTap.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt(streamTapPort)},
+			},
+			Selector: map[string]string{
+				streamingv1alpha1.StreamTapLabelKey: streamTap.Name,
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(streamTap, service, r.Scheme); err != nil {
+		return nil, err
+	}
+
+	return service, nil
+}
+
+func (r *StreamTapReconciler) constructLabelsForStreamTap(streamTap *streamingv1alpha1.StreamTap) map[string]string {
+	labels := make(map[string]string, len(streamTap.ObjectMeta.Labels)+2)
+	// pass through existing labels
+	for k, v := range streamTap.ObjectMeta.Labels {
+		labels[k] = v
+	}
+
+	labels[streamingv1alpha1.StreamTapLabelKey] = streamTap.Name
+	labels[streamingv1alpha1.StreamLabelKey] = streamTap.Spec.Stream
+	return labels
+}
+
+func (r *StreamTapReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueTrackedResources := func(t runtime.Object) handler.EventHandler {
+		versionKinds, _, err := r.Scheme.ObjectKinds(t)
+		if err != nil {
+			panic(err)
+		}
+		return &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(func(a handler.MapObject) []reconcile.Request {
+				var requests []reconcile.Request
+				key := tracker.NewKey(
+					versionKinds[0],
+					types.NamespacedName{Namespace: a.Meta.GetNamespace(), Name: a.Meta.GetName()},
+				)
+				for _, item := range r.Tracker.Lookup(key) {
+					requests = append(requests, reconcile.Request{NamespacedName: item})
+				}
+				return requests
+			}),
+		}
+	}
+
+	if err := controllers.IndexControllersOfType(mgr, streamTapPodIndexField, &streamingv1alpha1.StreamTap{}, &corev1.Pod{}); err != nil {
+		return err
+	}
+	if err := controllers.IndexControllersOfType(mgr, streamTapServiceIndexField, &streamingv1alpha1.StreamTap{}, &corev1.Service{}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&streamingv1alpha1.StreamTap{}).
+		Owns(&corev1.Pod{}).
+		Owns(&corev1.Service{}).
+		Watches(&source.Kind{Type: &streamingv1alpha1.Stream{}}, enqueueTrackedResources(&streamingv1alpha1.Stream{})).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, enqueueTrackedResources(&corev1.ConfigMap{})).
+		Complete(r)
+}
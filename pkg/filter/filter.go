@@ -0,0 +1,307 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filter parses the expressions selecting which messages a processor
+// consumes from an input stream.
+//
+// An expression compares the message content type, or a message header, to a
+// quoted string. Comparisons are combined with &&, || and !, and grouped with
+// parentheses:
+//
+//	contentType == 'application/json' && headers['priority'] != "low"
+package filter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed filter expression.
+type Expression interface {
+	// Matches returns true when a message with the content type and headers
+	// satisfies the expression.
+	Matches(contentType string, headers map[string]string) bool
+}
+
+// Parse returns the expression for the text, or an error when the text is not
+// a valid expression.
+func Parse(text string) (Expression, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", p.peek(), p.peek().pos)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenEqual
+	tokenNotEqual
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLeftParen
+	tokenRightParen
+	tokenLeftBracket
+	tokenRightBracket
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+func tokenize(text string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: string(runes[i+1 : end]), pos: i})
+			i = end + 1
+		case unicode.IsLetter(r):
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: string(runes[i:end]), pos: i})
+			i = end
+		default:
+			operators := []struct {
+				value string
+				kind  tokenKind
+			}{
+				{"==", tokenEqual},
+				{"!=", tokenNotEqual},
+				{"&&", tokenAnd},
+				{"||", tokenOr},
+				{"!", tokenNot},
+				{"(", tokenLeftParen},
+				{")", tokenRightParen},
+				{"[", tokenLeftBracket},
+				{"]", tokenRightBracket},
+			}
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op.value) {
+					tokens = append(tokens, token{kind: op.kind, value: op.value, pos: i})
+					i += len(op.value)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) consume(kind tokenKind, expected string) (token, error) {
+	t := p.peek()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s at position %d, found %s", expected, t.pos, t)
+	}
+	p.next++
+	return t, nil
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expression, error) {
+	switch p.peek().kind {
+	case tokenNot:
+		p.next++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{expr}, nil
+	case tokenLeftParen:
+		p.next++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.consume(tokenRightParen, "')'"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	identifier, err := p.consume(tokenIdentifier, "contentType or headers")
+	if err != nil {
+		return nil, err
+	}
+	c := comparison{}
+	switch identifier.value {
+	case "contentType":
+		c.contentType = true
+	case "headers":
+		if _, err := p.consume(tokenLeftBracket, "'['"); err != nil {
+			return nil, err
+		}
+		name, err := p.consume(tokenString, "a header name")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.consume(tokenRightBracket, "']'"); err != nil {
+			return nil, err
+		}
+		c.header = http.CanonicalHeaderKey(name.value)
+	default:
+		return nil, fmt.Errorf("unknown identifier %q at position %d, expected contentType or headers", identifier.value, identifier.pos)
+	}
+	switch p.peek().kind {
+	case tokenEqual:
+	case tokenNotEqual:
+		c.negate = true
+	default:
+		return nil, fmt.Errorf("expected '==' or '!=' at position %d, found %s", p.peek().pos, p.peek())
+	}
+	p.next++
+	value, err := p.consume(tokenString, "a quoted string")
+	if err != nil {
+		return nil, err
+	}
+	c.value = value.value
+	return c, nil
+}
+
+type comparison struct {
+	contentType bool
+	header      string
+	negate      bool
+	value       string
+}
+
+func (c comparison) Matches(contentType string, headers map[string]string) bool {
+	actual := contentType
+	if !c.contentType {
+		actual = ""
+		for name, value := range headers {
+			if http.CanonicalHeaderKey(name) == c.header {
+				actual = value
+				break
+			}
+		}
+	}
+	return (actual == c.value) != c.negate
+}
+
+type and struct {
+	left, right Expression
+}
+
+func (a and) Matches(contentType string, headers map[string]string) bool {
+	return a.left.Matches(contentType, headers) && a.right.Matches(contentType, headers)
+}
+
+type or struct {
+	left, right Expression
+}
+
+func (o or) Matches(contentType string, headers map[string]string) bool {
+	return o.left.Matches(contentType, headers) || o.right.Matches(contentType, headers)
+}
+
+type not struct {
+	expr Expression
+}
+
+func (n not) Matches(contentType string, headers map[string]string) bool {
+	return !n.expr.Matches(contentType, headers)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/system/pkg/filter"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		text    string
+		invalid bool
+	}{{
+		name: "content type",
+		text: `contentType == 'application/json'`,
+	}, {
+		name: "header",
+		text: `headers["priority"] != "low"`,
+	}, {
+		name: "boolean operators",
+		text: `!(contentType == 'text/plain' || headers['x-source'] == 'legacy') && headers['priority'] == 'high'`,
+	}, {
+		name:    "empty",
+		text:    ``,
+		invalid: true,
+	}, {
+		name:    "unknown identifier",
+		text:    `payload == 'x'`,
+		invalid: true,
+	}, {
+		name:    "unquoted value",
+		text:    `contentType == json`,
+		invalid: true,
+	}, {
+		name:    "unterminated string",
+		text:    `contentType == 'application/json`,
+		invalid: true,
+	}, {
+		name:    "missing operator",
+		text:    `contentType 'application/json'`,
+		invalid: true,
+	}, {
+		name:    "unbalanced parentheses",
+		text:    `(contentType == 'application/json'`,
+		invalid: true,
+	}, {
+		name:    "trailing tokens",
+		text:    `contentType == 'application/json' headers['a'] == 'b'`,
+		invalid: true,
+	}, {
+		name:    "unexpected character",
+		text:    `contentType = 'application/json'`,
+		invalid: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := filter.Parse(c.text)
+			if invalid := err != nil; invalid != c.invalid {
+				t.Errorf("Parse(%s) expected invalid %v, got error %v", c.name, c.invalid, err)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	contentType := "application/json"
+	headers := map[string]string{
+		"Priority": "high",
+		"x-source": "billing",
+	}
+	for _, c := range []struct {
+		name     string
+		text     string
+		expected bool
+	}{{
+		name:     "content type equal",
+		text:     `contentType == 'application/json'`,
+		expected: true,
+	}, {
+		name:     "content type not equal",
+		text:     `contentType != 'application/json'`,
+		expected: false,
+	}, {
+		name:     "header names are case insensitive",
+		text:     `headers['priority'] == 'high' && headers['X-Source'] == 'billing'`,
+		expected: true,
+	}, {
+		name:     "missing header is empty",
+		text:     `headers['tenant'] == ''`,
+		expected: true,
+	}, {
+		name:     "or",
+		text:     `headers['priority'] == 'low' || contentType == 'application/json'`,
+		expected: true,
+	}, {
+		name:     "and binds tighter than or",
+		text:     `contentType == 'text/plain' && headers['priority'] == 'low' || headers['x-source'] == 'billing'`,
+		expected: true,
+	}, {
+		name:     "not",
+		text:     `!(headers['x-source'] == 'billing')`,
+		expected: false,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := filter.Parse(c.text)
+			if err != nil {
+				t.Fatalf("Parse(%s) unexpected error %v", c.name, err)
+			}
+			if actual := expr.Matches(contentType, headers); actual != c.expected {
+				t.Errorf("Matches(%s) expected %v, got %v", c.name, c.expected, actual)
+			}
+		})
+	}
+}
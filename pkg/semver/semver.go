@@ -0,0 +1,288 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package semver matches semantic versions against version ranges.
+//
+// A range is one or more sets of comparators joined by ||. A version is in the
+// range when it satisfies every comparator of any set. A comparator is a
+// version prefixed with =, <, <=, > or >=, a ~ (patch updates) or ^
+// (compatible updates) range, or a partial version with x wildcards:
+//
+//	>=1.2.0 <2.0.0 || ~2.1 || 3.x
+//
+// Pre-release versions are only in a range when a comparator of the matching
+// set has a pre-release of the same major, minor and patch version.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Version is a parsed semantic version.
+type Version = version.Version
+
+// ParseVersion parses a semantic version, optionally prefixed with v.
+func ParseVersion(text string) (*Version, error) {
+	return version.ParseSemantic(text)
+}
+
+// Range is a parsed version range.
+type Range struct {
+	sets [][]comparator
+}
+
+// ParseRange returns the range for the text, or an error when the text is not
+// a valid range.
+func ParseRange(text string) (*Range, error) {
+	r := &Range{}
+	for _, set := range strings.Split(text, "||") {
+		fields := strings.Fields(set)
+		if len(fields) == 0 {
+			if strings.TrimSpace(text) == "" {
+				return nil, fmt.Errorf("empty range")
+			}
+			return nil, fmt.Errorf("empty comparator set in range %q", text)
+		}
+		comparators := []comparator{}
+		for _, field := range fields {
+			c, err := parseComparator(field)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, c...)
+		}
+		r.sets = append(r.sets, comparators)
+	}
+	return r, nil
+}
+
+// Matches returns true when the version is in the range.
+func (r *Range) Matches(v *Version) bool {
+	for _, set := range r.sets {
+		if matchesSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesSet(set []comparator, v *Version) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	if v.PreRelease() == "" {
+		return true
+	}
+	for _, c := range set {
+		if c.version.PreRelease() != "" &&
+			c.version.Major() == v.Major() && c.version.Minor() == v.Minor() && c.version.Patch() == v.Patch() {
+			return true
+		}
+	}
+	return false
+}
+
+type operator string
+
+const (
+	opEqual              operator = "="
+	opLessThan           operator = "<"
+	opLessThanOrEqual    operator = "<="
+	opGreaterThan        operator = ">"
+	opGreaterThanOrEqual operator = ">="
+)
+
+type comparator struct {
+	op      operator
+	version *Version
+}
+
+func (c comparator) matches(v *Version) bool {
+	cmp := compare(v, c.version)
+	switch c.op {
+	case opEqual:
+		return cmp == 0
+	case opLessThan:
+		return cmp < 0
+	case opLessThanOrEqual:
+		return cmp <= 0
+	case opGreaterThan:
+		return cmp > 0
+	case opGreaterThanOrEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+func compare(a, b *Version) int {
+	if a.LessThan(b) {
+		return -1
+	}
+	if b.LessThan(a) {
+		return 1
+	}
+	return 0
+}
+
+// partial is a version where trailing components may be wildcards.
+type partial struct {
+	components []uint
+	preRelease string
+}
+
+func (p partial) version(components ...uint) *Version {
+	v := version.MustParseSemantic(fmt.Sprintf("%d.%d.%d", components[0], components[1], components[2]))
+	if p.preRelease != "" && len(p.components) == 3 {
+		v = v.WithPreRelease(p.preRelease)
+	}
+	return v
+}
+
+// lower is the lowest version the partial matches.
+func (p partial) lower() *Version {
+	c := append(append([]uint{}, p.components...), 0, 0, 0)
+	return p.version(c[0], c[1], c[2])
+}
+
+// upper is the lowest version above every version the partial matches, the
+// partial must not be complete or empty.
+func (p partial) upper() *Version {
+	c := append(append([]uint{}, p.components...), 0, 0, 0)
+	c[len(p.components)-1]++
+	for i := len(p.components); i < 3; i++ {
+		c[i] = 0
+	}
+	return version.MustParseSemantic(fmt.Sprintf("%d.%d.%d", c[0], c[1], c[2]))
+}
+
+func parseComparator(text string) ([]comparator, error) {
+	rest := strings.TrimLeft(text, "<>=~^")
+	op := text[:len(text)-len(rest)]
+	p, err := parsePartial(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid comparator %q: %v", text, err)
+	}
+	complete := len(p.components) == 3
+	any := len(p.components) == 0
+
+	switch op {
+	case "", "=":
+		if complete {
+			return []comparator{{opEqual, p.lower()}}, nil
+		}
+		if any {
+			return []comparator{{opGreaterThanOrEqual, p.lower()}}, nil
+		}
+		return []comparator{{opGreaterThanOrEqual, p.lower()}, {opLessThan, p.upper()}}, nil
+	case ">=":
+		return []comparator{{opGreaterThanOrEqual, p.lower()}}, nil
+	case ">":
+		if any {
+			// nothing is greater than any version
+			return []comparator{{opLessThan, p.lower()}}, nil
+		}
+		if complete {
+			return []comparator{{opGreaterThan, p.lower()}}, nil
+		}
+		return []comparator{{opGreaterThanOrEqual, p.upper()}}, nil
+	case "<":
+		return []comparator{{opLessThan, p.lower()}}, nil
+	case "<=":
+		if any {
+			return []comparator{{opGreaterThanOrEqual, p.lower()}}, nil
+		}
+		if complete {
+			return []comparator{{opLessThanOrEqual, p.lower()}}, nil
+		}
+		return []comparator{{opLessThan, p.upper()}}, nil
+	case "~":
+		if any {
+			return []comparator{{opGreaterThanOrEqual, p.lower()}}, nil
+		}
+		minor := partial{components: p.components[:1]}
+		if len(p.components) > 1 {
+			minor.components = p.components[:2]
+		}
+		return []comparator{{opGreaterThanOrEqual, p.lower()}, {opLessThan, minor.upper()}}, nil
+	case "^":
+		if any {
+			return []comparator{{opGreaterThanOrEqual, p.lower()}}, nil
+		}
+		// the left-most non-zero component may not change
+		compatible := partial{components: p.components[:1]}
+		for i, c := range p.components {
+			compatible.components = p.components[:i+1]
+			if c != 0 {
+				break
+			}
+		}
+		return []comparator{{opGreaterThanOrEqual, p.lower()}, {opLessThan, compatible.upper()}}, nil
+	}
+	return nil, fmt.Errorf("invalid operator %q in comparator %q", op, text)
+}
+
+func parsePartial(text string) (partial, error) {
+	p := partial{}
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "v"), "V")
+	if i := strings.Index(text, "+"); i != -1 {
+		// build metadata is ignored
+		text = text[:i]
+	}
+	if i := strings.Index(text, "-"); i != -1 {
+		p.preRelease = text[i+1:]
+		text = text[:i]
+		if p.preRelease == "" {
+			return p, fmt.Errorf("empty pre-release")
+		}
+	}
+	if text == "" {
+		return p, fmt.Errorf("missing version")
+	}
+	parts := strings.Split(text, ".")
+	if len(parts) > 3 {
+		return p, fmt.Errorf("too many version components")
+	}
+	wildcard := false
+	for _, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return p, fmt.Errorf("version component %q follows a wildcard", part)
+		}
+		n, err := strconv.ParseUint(part, 10, 0)
+		if err != nil {
+			return p, fmt.Errorf("invalid version component %q", part)
+		}
+		p.components = append(p.components, uint(n))
+	}
+	if p.preRelease != "" {
+		if len(p.components) != 3 {
+			return p, fmt.Errorf("pre-release requires a complete version")
+		}
+		if _, err := version.ParseSemantic(fmt.Sprintf("%s-%s", text, p.preRelease)); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
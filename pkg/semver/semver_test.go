@@ -0,0 +1,166 @@
+/*
+Copyright 2019 the original author or authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/projectriff/system/pkg/semver"
+)
+
+func TestParseRange(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		text    string
+		invalid bool
+	}{{
+		name: "comparators",
+		text: `>=1.2.0 <2.0.0`,
+	}, {
+		name: "alternatives",
+		text: `~1.2 || ^2.0.1 || 3.x`,
+	}, {
+		name: "any",
+		text: `*`,
+	}, {
+		name: "pre-release",
+		text: `>=1.0.0-rc.1`,
+	}, {
+		name:    "empty",
+		text:    ``,
+		invalid: true,
+	}, {
+		name:    "empty alternative",
+		text:    `1.x ||`,
+		invalid: true,
+	}, {
+		name:    "unknown operator",
+		text:    `=>1.0.0`,
+		invalid: true,
+	}, {
+		name:    "non-numeric component",
+		text:    `1.a`,
+		invalid: true,
+	}, {
+		name:    "too many components",
+		text:    `1.2.3.4`,
+		invalid: true,
+	}, {
+		name:    "component after wildcard",
+		text:    `1.x.3`,
+		invalid: true,
+	}, {
+		name:    "partial pre-release",
+		text:    `1.2-rc.1`,
+		invalid: true,
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := semver.ParseRange(c.text)
+			if c.invalid && err == nil {
+				t.Errorf("ParseRange(%q) expected error", c.text)
+			}
+			if !c.invalid && err != nil {
+				t.Errorf("ParseRange(%q) unexpected error: %v", c.text, err)
+			}
+		})
+	}
+}
+
+func TestRangeMatches(t *testing.T) {
+	for _, c := range []struct {
+		text     string
+		matching []string
+		excluded []string
+	}{{
+		text:     `>=1.2.0 <2.0.0`,
+		matching: []string{"1.2.0", "1.9.9", "v1.4.2"},
+		excluded: []string{"1.1.9", "2.0.0", "1.5.0-rc.1"},
+	}, {
+		text:     `1.2.3`,
+		matching: []string{"1.2.3", "1.2.3+build.1"},
+		excluded: []string{"1.2.4"},
+	}, {
+		text:     `1.x`,
+		matching: []string{"1.0.0", "1.9.0"},
+		excluded: []string{"0.9.0", "2.0.0"},
+	}, {
+		text:     `*`,
+		matching: []string{"0.0.1", "10.0.0"},
+		excluded: []string{"1.0.0-alpha"},
+	}, {
+		text:     `~1.2.3`,
+		matching: []string{"1.2.3", "1.2.9"},
+		excluded: []string{"1.2.2", "1.3.0"},
+	}, {
+		text:     `~1`,
+		matching: []string{"1.0.0", "1.9.0"},
+		excluded: []string{"2.0.0"},
+	}, {
+		text:     `^1.2.3`,
+		matching: []string{"1.2.3", "1.9.0"},
+		excluded: []string{"1.2.2", "2.0.0"},
+	}, {
+		text:     `^0.2.3`,
+		matching: []string{"0.2.3", "0.2.9"},
+		excluded: []string{"0.3.0"},
+	}, {
+		text:     `^0.0.3`,
+		matching: []string{"0.0.3"},
+		excluded: []string{"0.0.4"},
+	}, {
+		text:     `>1.2`,
+		matching: []string{"1.3.0"},
+		excluded: []string{"1.2.9"},
+	}, {
+		text:     `<=1.2`,
+		matching: []string{"1.2.9"},
+		excluded: []string{"1.3.0"},
+	}, {
+		text:     `>=1.0.0-rc.1 <1.1.0`,
+		matching: []string{"1.0.0-rc.1", "1.0.0-rc.2", "1.0.0"},
+		excluded: []string{"1.0.0-beta.1", "1.0.1-rc.1"},
+	}, {
+		text:     `1.x || >=3.0.0`,
+		matching: []string{"1.5.0", "3.1.0"},
+		excluded: []string{"2.0.0"},
+	}} {
+		t.Run(c.text, func(t *testing.T) {
+			r, err := semver.ParseRange(c.text)
+			if err != nil {
+				t.Fatalf("ParseRange(%q) unexpected error: %v", c.text, err)
+			}
+			for _, text := range c.matching {
+				if v := mustParseVersion(t, text); !r.Matches(v) {
+					t.Errorf("%q expected to match %q", c.text, text)
+				}
+			}
+			for _, text := range c.excluded {
+				if v := mustParseVersion(t, text); r.Matches(v) {
+					t.Errorf("%q expected not to match %q", c.text, text)
+				}
+			}
+		})
+	}
+}
+
+func mustParseVersion(t *testing.T, text string) *semver.Version {
+	v, err := semver.ParseVersion(text)
+	if err != nil {
+		t.Fatalf("ParseVersion(%q) unexpected error: %v", text, err)
+	}
+	return v
+}
@@ -118,6 +118,12 @@ func ErrInvalidValue(value interface{}, name string) FieldErrors {
 	}
 }
 
+func ErrInvalidValueWithDetail(value interface{}, name string, detail string) FieldErrors {
+	return FieldErrors{
+		field.Invalid(field.NewPath(name), value, detail),
+	}
+}
+
 func ErrDuplicateValue(value interface{}, names ...string) FieldErrors {
 	errs := FieldErrors{}
 
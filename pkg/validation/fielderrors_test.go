@@ -171,6 +171,22 @@ func TestErrInvalidValue(t *testing.T) {
 	}
 }
 
+func TestErrInvalidValueWithDetail(t *testing.T) {
+	expected := validation.FieldErrors{
+		&field.Error{
+			Type:     field.ErrorTypeInvalid,
+			Field:    "my-field",
+			BadValue: "value",
+			Detail:   "my-detail",
+		},
+	}
+	actual := validation.ErrInvalidValueWithDetail("value", "my-field", "my-detail")
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("(-expected, +actual): %s", diff)
+	}
+}
+
 func TestErrDuplicateValue(t *testing.T) {
 	expected := validation.FieldErrors{
 		&field.Error{